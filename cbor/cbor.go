@@ -8,6 +8,7 @@ package cbor
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -23,9 +24,15 @@ type (
 	RawCBOR []byte
 
 	TaggedCBOR = RawCBOR
+
+	Decoder = cbor.Decoder
 )
 
 var (
+	// ErrNonCanonical is returned when input is decodable but it is not
+	// the deterministic encoding of the decoded value.
+	ErrNonCanonical = errors.New("non-canonical CBOR encoding")
+
 	encMode cbor.EncMode
 
 	cborNil = []byte{0xf6}
@@ -78,6 +85,71 @@ func Unmarshal(data []byte, v any) error {
 	return cbor.Unmarshal(data, v)
 }
 
+/*
+UnmarshalCanonical is the strict mode of Unmarshal: it decodes data into v and
+verifies that data is the deterministic encoding, see VerifyCanonical. Signatures
+and hashes are calculated over the re-encoded value so non-canonical input would
+allow to create different byte representations of the same (signed) object.
+
+The check covers the whole input, including values nested in v which are kept
+in encoded form (RawCBOR) or decoded by custom UnmarshalCBOR methods, so it is
+enough to use it on the outermost value only.
+*/
+func UnmarshalCanonical(data []byte, v any) error {
+	if err := Unmarshal(data, v); err != nil {
+		return err
+	}
+	return VerifyCanonical(data, v)
+}
+
+/*
+VerifyCanonical checks that data is the deterministic encoding of v (v is
+expected to be the value decoded from data). Returns error wrapping ErrNonCanonical
+when the encodings differ or when any data item in "data" is not encoded
+deterministically.
+
+Re-encoding v alone is not sufficient as RawCBOR fields are re-encoded verbatim,
+so data is also decoded without schema and the result re-encoded.
+*/
+func VerifyCanonical(data []byte, v any) error {
+	buf, err := Marshal(v)
+	if err != nil {
+		return fmt.Errorf("re-encoding %T: %w", v, err)
+	}
+	if !bytes.Equal(data, buf) {
+		return fmt.Errorf("%w of %T", ErrNonCanonical, v)
+	}
+	if err := verifyCanonicalItems(data); err != nil {
+		return fmt.Errorf("%w of %T: %w", ErrNonCanonical, v, err)
+	}
+	return nil
+}
+
+/*
+verifyCanonicalItems decodes data into generic Go values and checks that
+re-encoding them results in the same bytes, ie every (nested) data item
+is in deterministic encoding.
+*/
+func verifyCanonicalItems(data []byte) error {
+	var item any
+	if err := Unmarshal(data, &item); err != nil {
+		return fmt.Errorf("decoding data items: %w", err)
+	}
+	buf, err := Marshal(item)
+	if err != nil {
+		return fmt.Errorf("re-encoding data items: %w", err)
+	}
+	if !bytes.Equal(data, buf) {
+		// report the offset of the first difference to help debugging
+		idx := 0
+		for idx < len(data) && idx < len(buf) && data[idx] == buf[idx] {
+			idx++
+		}
+		return fmt.Errorf("nested data item differs at offset %d", idx)
+	}
+	return nil
+}
+
 func UnmarshalTagged(data []byte) (ABTag, []any, error) {
 	var raw cbor.RawTag
 	if err := Unmarshal(data, &raw); err != nil {
@@ -121,7 +193,7 @@ func Encode(w io.Writer, v any) error {
 	return enc.Encode(v)
 }
 
-func GetDecoder(r io.Reader) *cbor.Decoder {
+func GetDecoder(r io.Reader) *Decoder {
 	return cbor.NewDecoder(r)
 }
 
@@ -129,6 +201,47 @@ func Decode(r io.Reader, v any) error {
 	return GetDecoder(r).Decode(v)
 }
 
+/*
+ReadArrayHead reads the head of a definite length CBOR array from r and returns
+the number of items in the array. CBOR nil is accepted as an empty array.
+Only the head is consumed, the array items are left in the reader, so this
+function can be used to decode large arrays item by item.
+*/
+func ReadArrayHead(r io.Reader) (uint64, error) {
+	var head [1]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, err
+	}
+	if head[0] == cborNil[0] {
+		return 0, nil
+	}
+	if major := head[0] >> 5; major != 4 {
+		return 0, fmt.Errorf("expected array (major type 4), got major type %d", major)
+	}
+	var size int
+	switch info := head[0] & 0x1f; {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == 31:
+		return 0, errors.New("indefinite length arrays are not supported")
+	default:
+		return 0, fmt.Errorf("invalid additional information %d in array head", info)
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[8-size:]); err != nil {
+		return 0, fmt.Errorf("reading array length: %w", err)
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
 // MarshalCBOR returns r or CBOR nil if r is empty.
 func (r RawCBOR) MarshalCBOR() ([]byte, error) {
 	if len(r) == 0 {
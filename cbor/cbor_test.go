@@ -3,6 +3,7 @@ package cbor
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"testing"
 
 	"github.com/alphabill-org/alphabill-go-base/types/hex"
@@ -234,6 +235,86 @@ func TestCborHandler_Decoding(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalCanonical(t *testing.T) {
+	// CustomData{Name: "foo", Value: 10}
+	validCbor := []byte{0xa2, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x63, 0x66, 0x6f, 0x6f, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0xa}
+
+	t.Run("canonical input", func(t *testing.T) {
+		var got CustomData
+		require.NoError(t, UnmarshalCanonical(validCbor, &got))
+		require.Equal(t, CustomData{Name: "foo", Value: 10}, got)
+	})
+
+	t.Run("non-minimal integer encoding", func(t *testing.T) {
+		data := append(append([]byte{}, validCbor[:len(validCbor)-1]...), 0x18, 0x0a)
+		var got CustomData
+		require.NoError(t, Unmarshal(data, &got))
+		require.ErrorIs(t, UnmarshalCanonical(data, &got), ErrNonCanonical)
+		require.EqualError(t, UnmarshalCanonical(data, &got), `non-canonical CBOR encoding of *cbor.CustomData`)
+	})
+
+	t.Run("map keys not sorted", func(t *testing.T) {
+		// {"Value": 10, "Name": "foo"}
+		data := []byte{0xa2, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0xa, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x63, 0x66, 0x6f, 0x6f}
+		var got CustomData
+		require.ErrorIs(t, UnmarshalCanonical(data, &got), ErrNonCanonical)
+		require.Equal(t, CustomData{Name: "foo", Value: 10}, got)
+	})
+
+	t.Run("indefinite length string", func(t *testing.T) {
+		// {"Name": "foo" (as indefinite length string), "Value": 10}
+		data := []byte{0xa2, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x7f, 0x63, 0x66, 0x6f, 0x6f, 0xff, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0xa}
+		var got CustomData
+		require.ErrorIs(t, UnmarshalCanonical(data, &got), ErrNonCanonical)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		var got CustomData
+		require.EqualError(t, UnmarshalCanonical([]byte{0xa2}, &got), `unexpected EOF`)
+	})
+}
+
+func Test_ReadArrayHead(t *testing.T) {
+	for _, n := range []uint64{0, 1, 23, 24, 255, 256, 65535, 65536, 1 << 32} {
+		data, err := Marshal(make([]struct{}, 0, n)[:0])
+		require.NoError(t, err)
+		if n > 0 {
+			// build the head only, encoding huge arrays is not necessary
+			data, err = Marshal(n)
+			require.NoError(t, err)
+			data[0] |= 0x80 // major type 0 (uint) -> 4 (array)
+		}
+		r := bytes.NewReader(append(data, 0xf6))
+		cnt, err := ReadArrayHead(r)
+		require.NoError(t, err)
+		require.Equal(t, n, cnt)
+		require.Equal(t, 1, r.Len(), "only the head must be consumed")
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		cnt, err := ReadArrayHead(bytes.NewReader(cborNil))
+		require.NoError(t, err)
+		require.Zero(t, cnt)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := ReadArrayHead(bytes.NewReader(nil))
+		require.ErrorIs(t, err, io.EOF)
+
+		_, err = ReadArrayHead(bytes.NewReader([]byte{0x01}))
+		require.EqualError(t, err, `expected array (major type 4), got major type 0`)
+
+		_, err = ReadArrayHead(bytes.NewReader([]byte{0x9f, 0xff}))
+		require.EqualError(t, err, `indefinite length arrays are not supported`)
+
+		_, err = ReadArrayHead(bytes.NewReader([]byte{0x9c}))
+		require.EqualError(t, err, `invalid additional information 28 in array head`)
+
+		_, err = ReadArrayHead(bytes.NewReader([]byte{0x99, 0x01}))
+		require.EqualError(t, err, `reading array length: unexpected EOF`)
+	})
+}
+
 func Test_RawCBOR(t *testing.T) {
 	t.Run("MarshalCBOR empty input returns CBOR nil marker", func(t *testing.T) {
 		// input is nil slice
@@ -302,3 +383,40 @@ func Test_RawCBOR(t *testing.T) {
 		require.Equal(t, `{"rawCborField":"0x4201ff"}`, string(jsonBytes))
 	})
 }
+
+func Test_VerifyCanonical_RawCBOR(t *testing.T) {
+	type container struct {
+		_     struct{} `cbor:",toarray"`
+		Value uint64
+		Raw   RawCBOR
+	}
+	// Raw contains [1, "foo"]
+	data, err := Marshal(container{Value: 1, Raw: RawCBOR{0x82, 0x01, 0x63, 0x66, 0x6f, 0x6f}})
+	require.NoError(t, err)
+
+	var got container
+	require.NoError(t, UnmarshalCanonical(data, &got))
+
+	// encode the integer in Raw using non-minimal length (0x18 0x01 instead of 0x01),
+	// re-encoding the container copies the raw bytes verbatim
+	nonCanonical := container{Value: 1, Raw: RawCBOR{0x82, 0x18, 0x01, 0x63, 0x66, 0x6f, 0x6f}}
+	data, err = Marshal(nonCanonical)
+	require.NoError(t, err)
+	require.NoError(t, Unmarshal(data, &got))
+	require.Equal(t, nonCanonical.Raw, got.Raw)
+	err = UnmarshalCanonical(data, &got)
+	require.ErrorIs(t, err, ErrNonCanonical)
+	require.EqualError(t, err, `non-canonical CBOR encoding of *cbor.container: nested data item differs at offset 3`)
+
+	// map keys of the raw item not sorted: {"b": 1, "a": 2}
+	data, err = Marshal(container{Value: 1, Raw: RawCBOR{0xa2, 0x61, 0x62, 0x01, 0x61, 0x61, 0x02}})
+	require.NoError(t, err)
+	require.ErrorIs(t, UnmarshalCanonical(data, &got), ErrNonCanonical)
+
+	// tagged raw item in canonical encoding
+	raw, err := MarshalTaggedValue(1001, []any{uint64(1), []byte{2, 3}})
+	require.NoError(t, err)
+	data, err = Marshal(container{Value: 1, Raw: raw})
+	require.NoError(t, err)
+	require.NoError(t, UnmarshalCanonical(data, &got))
+}
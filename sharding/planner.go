@@ -0,0 +1,286 @@
+/*
+Package sharding implements tools for planning the changes of the sharding scheme
+of a partition.
+*/
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+// DefaultMaxShards is the max number of shards in the planned scheme when
+// Policy.MaxShards is not set.
+const DefaultMaxShards = 1024
+
+type (
+	// ShardStats is the load statistics of a shard.
+	ShardStats struct {
+		Shard      types.ShardID
+		Units      uint64   // number of units in the shard
+		TxRate     float64  // average number of transactions per round
+		BlockSizes []uint64 // Block.Size of the recent blocks of the shard
+		// optional sample of the IDs of the units in the shard, used to estimate how
+		// the units are divided between the new shards when the shard is split. When
+		// there is no sample even distribution is assumed.
+		SampleIDs []types.UnitID
+	}
+
+	// Policy controls when the shards are split or merged. Zero value of a limit
+	// means that the limit is not used.
+	Policy struct {
+		MaxUnits     uint64  // shard with more units is split
+		MaxTxRate    float64 // shard with higher transaction rate is split
+		MaxBlockSize uint64  // shard with bigger average block size is split
+		// sibling shards are merged when both have less units than MinUnits and the
+		// merged shard would not exceed any of the limits above
+		MinUnits uint64
+		// max length of the shard ID in bits, shards are not split beyond it. Must be
+		// shorter than the unit ID length of the partition, when zero the unit ID
+		// length minus one is used.
+		MaxShardIDLength uint
+		// max number of shards in the planned scheme, shards are not split beyond
+		// it. When zero DefaultMaxShards is used.
+		MaxShards uint
+	}
+
+	// ShardForecast is the expected load of a shard in the planned scheme.
+	ShardForecast struct {
+		Shard     types.ShardID
+		Units     uint64
+		TxRate    float64
+		BlockSize uint64 // average block size
+	}
+
+	// Plan is the proposed change of the sharding scheme.
+	Plan struct {
+		Change *types.ShardingSchemeChange
+		Scheme types.ShardingScheme // the scheme after the change
+		Shards []ShardForecast      // sorted by types.CompareShardIDs
+		// shards which exceed the limits of the policy but can't be split as their
+		// ID is already of max length or the scheme already has max number of shards
+		Overloaded []types.ShardID
+	}
+
+	shardLoad struct {
+		ShardForecast
+		samples []types.UnitID
+		split   bool // the shard is created by splitting in this plan
+	}
+)
+
+/*
+IsValid checks the policy against the partition description "pdr".
+*/
+func (p *Policy) IsValid(pdr *types.PartitionDescriptionRecord) error {
+	if p == nil {
+		return errors.New("policy is nil")
+	}
+	if pdr == nil {
+		return types.ErrSystemDescriptionIsNil
+	}
+	if p.MaxShardIDLength >= uint(pdr.UnitIDLen) {
+		return fmt.Errorf("max shard ID length %d must be shorter than unit ID length %d", p.MaxShardIDLength, pdr.UnitIDLen)
+	}
+	if p.MaxTxRate < 0 {
+		return fmt.Errorf("max transaction rate must not be negative, got %f", p.MaxTxRate)
+	}
+	if p.MinUnits > 0 && p.MaxUnits > 0 && p.MinUnits > p.MaxUnits/2 {
+		// otherwise merged shard could be immediately split again
+		return fmt.Errorf("min units %d must not be greater than half of the max units %d", p.MinUnits, p.MaxUnits)
+	}
+	return nil
+}
+
+/*
+NewPlan proposes changes to the sharding scheme "scheme" of the partition "pdr"
+according to the "policy" based on the current load "stats" of the shards (there
+must be statistics for every shard in the scheme).
+
+Overloaded shards are split (repeatedly, if the estimated load of the new shard is
+still over the limits) and underloaded sibling shards which are not split are merged.
+When the shard is split the units are divided between the new shards according to
+the unit ID sample of the shard (using the new scheme's Shard method) and the
+transaction rate and block size are divided in the same proportion.
+*/
+func NewPlan(scheme types.ShardingScheme, stats []ShardStats, policy Policy, pdr *types.PartitionDescriptionRecord) (*Plan, error) {
+	if err := policy.IsValid(pdr); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := scheme.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid sharding scheme: %w", err)
+	}
+	loads, err := initLoads(scheme, stats, pdr)
+	if err != nil {
+		return nil, err
+	}
+
+	work, err := types.NewShardingScheme(slices.Collect(scheme.All()))
+	if err != nil {
+		return nil, fmt.Errorf("copying sharding scheme: %w", err)
+	}
+	maxIDLen := policy.MaxShardIDLength
+	if maxIDLen == 0 {
+		maxIDLen = uint(pdr.UnitIDLen) - 1
+	}
+	maxShards := policy.MaxShards
+	if maxShards == 0 {
+		maxShards = DefaultMaxShards
+	}
+	plan := &Plan{}
+
+	// split phase, shards are processed level by level so when the max number
+	// of shards is reached the load is spread as evenly as possible
+	queue := slices.SortedFunc(work.All(), types.CompareShardIDs)
+	shardCnt := uint(len(queue))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		load := loads[id.Key()]
+		if !policy.exceeded(&load.ShardForecast) {
+			continue
+		}
+		if id.Length() >= maxIDLen || shardCnt >= maxShards {
+			plan.Overloaded = append(plan.Overloaded, id)
+			continue
+		}
+		id0, id1, err := work.Split(id)
+		if err != nil {
+			return nil, fmt.Errorf("splitting shard %q: %w", id, err)
+		}
+		l0, l1 := splitLoad(work, load, id0, id1)
+		delete(loads, id.Key())
+		loads[id0.Key()] = l0
+		loads[id1.Key()] = l1
+		queue = append(queue, id0, id1)
+		shardCnt++
+	}
+
+	// merge phase, repeat as merged shards might be merged again
+	for merged := policy.MinUnits > 0; merged; {
+		merged = false
+		for id := range work.All() {
+			parent, ok := id.Parent()
+			if !ok {
+				break // single shard
+			}
+			id0, id1 := parent.Split()
+			l0, ok0 := loads[id0.Key()]
+			l1, ok1 := loads[id1.Key()]
+			if !ok0 || !ok1 || l0.split || l1.split || l0.Units >= policy.MinUnits || l1.Units >= policy.MinUnits {
+				continue
+			}
+			load := mergeLoad(parent, l0, l1)
+			if policy.exceeded(&load.ShardForecast) {
+				continue
+			}
+			if err := work.Merge(parent); err != nil {
+				return nil, fmt.Errorf("merging shard %q: %w", parent, err)
+			}
+			delete(loads, id0.Key())
+			delete(loads, id1.Key())
+			loads[parent.Key()] = load
+			merged = true
+			break // the scheme changed, restart the iteration
+		}
+	}
+
+	if plan.Change, err = scheme.Diff(work); err != nil {
+		return nil, fmt.Errorf("creating sharding scheme change: %w", err)
+	}
+	if plan.Scheme, err = plan.Change.Apply(scheme); err != nil {
+		return nil, fmt.Errorf("verifying sharding scheme change: %w", err)
+	}
+	for id := range plan.Scheme.All() {
+		plan.Shards = append(plan.Shards, loads[id.Key()].ShardForecast)
+	}
+	slices.SortFunc(plan.Shards, func(a, b ShardForecast) int { return types.CompareShardIDs(a.Shard, b.Shard) })
+	return plan, nil
+}
+
+func (p *Policy) exceeded(load *ShardForecast) bool {
+	return (p.MaxUnits > 0 && load.Units > p.MaxUnits) ||
+		(p.MaxTxRate > 0 && load.TxRate > p.MaxTxRate) ||
+		(p.MaxBlockSize > 0 && load.BlockSize > p.MaxBlockSize)
+}
+
+func initLoads(scheme types.ShardingScheme, stats []ShardStats, pdr *types.PartitionDescriptionRecord) (map[string]*shardLoad, error) {
+	loads := make(map[string]*shardLoad, len(stats))
+	for _, s := range stats {
+		if _, ok := loads[s.Shard.Key()]; ok {
+			return nil, fmt.Errorf("duplicate statistics for shard %q", s.Shard)
+		}
+		validate := pdr.UnitIDValidator(s.Shard)
+		for _, id := range s.SampleIDs {
+			if err := validate(id); err != nil {
+				return nil, fmt.Errorf("invalid sample unit ID %s of shard %q: %w", id, s.Shard, err)
+			}
+		}
+		var blockSize uint64
+		if len(s.BlockSizes) > 0 {
+			var total float64
+			for _, v := range s.BlockSizes {
+				total += float64(v)
+			}
+			blockSize = uint64(total / float64(len(s.BlockSizes)))
+		}
+		loads[s.Shard.Key()] = &shardLoad{
+			ShardForecast: ShardForecast{Shard: s.Shard, Units: s.Units, TxRate: s.TxRate, BlockSize: blockSize},
+			samples:       s.SampleIDs,
+		}
+	}
+
+	var cnt int
+	for id := range scheme.All() {
+		if _, ok := loads[id.Key()]; !ok {
+			return nil, fmt.Errorf("no statistics for shard %q", id)
+		}
+		cnt++
+	}
+	if cnt != len(loads) {
+		return nil, errors.New("statistics contain shards which are not in the sharding scheme")
+	}
+	return loads, nil
+}
+
+/*
+splitLoad divides the load of the shard between it's child shards "id0" and "id1"
+which must be leafs of the "scheme".
+*/
+func splitLoad(scheme types.ShardingScheme, load *shardLoad, id0, id1 types.ShardID) (*shardLoad, *shardLoad) {
+	l0 := &shardLoad{ShardForecast: ShardForecast{Shard: id0}, split: true}
+	l1 := &shardLoad{ShardForecast: ShardForecast{Shard: id1}, split: true}
+	for _, id := range load.samples {
+		if scheme.Shard(id).Equal(id0) {
+			l0.samples = append(l0.samples, id)
+		} else {
+			l1.samples = append(l1.samples, id)
+		}
+	}
+	ratio := 0.5
+	if len(load.samples) > 0 {
+		ratio = float64(len(l0.samples)) / float64(len(load.samples))
+	}
+	l0.Units = uint64(float64(load.Units) * ratio)
+	l1.Units = load.Units - l0.Units
+	l0.TxRate = load.TxRate * ratio
+	l1.TxRate = load.TxRate - l0.TxRate
+	l0.BlockSize = uint64(float64(load.BlockSize) * ratio)
+	l1.BlockSize = load.BlockSize - l0.BlockSize
+	return l0, l1
+}
+
+func mergeLoad(id types.ShardID, l0, l1 *shardLoad) *shardLoad {
+	return &shardLoad{
+		ShardForecast: ShardForecast{
+			Shard:     id,
+			Units:     l0.Units + l1.Units,
+			TxRate:    l0.TxRate + l1.TxRate,
+			BlockSize: l0.BlockSize + l1.BlockSize,
+		},
+		samples: slices.Concat(l0.samples, l1.samples),
+	}
+}
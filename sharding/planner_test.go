@@ -0,0 +1,162 @@
+package sharding
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_NewPlan(t *testing.T) {
+	pdr := &types.PartitionDescriptionRecord{
+		Version:     1,
+		NetworkID:   5,
+		PartitionID: 1,
+		TypeIDLen:   8,
+		UnitIDLen:   64,
+		T2Timeout:   2500 * time.Millisecond,
+	}
+	emptyID := types.ShardID{}
+	id0, id1 := emptyID.Split()
+	id00, id01 := id0.Split()
+	id10, id11 := id1.Split()
+
+	newScheme := func(t *testing.T, ids ...types.ShardID) types.ShardingScheme {
+		t.Helper()
+		ss, err := types.NewShardingScheme(ids)
+		require.NoError(t, err)
+		return ss
+	}
+	// unit ID with the first byte "b"
+	unitID := func(b byte) types.UnitID { return types.UnitID{b, 0, 0, 0, 0, 0, 0, 0, 1} }
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := NewPlan(types.ShardingScheme{}, nil, Policy{MaxShardIDLength: 64}, pdr)
+		require.EqualError(t, err, "invalid policy: max shard ID length 64 must be shorter than unit ID length 64")
+
+		_, err = NewPlan(types.ShardingScheme{}, nil, Policy{MinUnits: 6, MaxUnits: 10}, pdr)
+		require.EqualError(t, err, "invalid policy: min units 6 must not be greater than half of the max units 10")
+
+		_, err = NewPlan(types.ShardingScheme{}, nil, Policy{MaxTxRate: -1}, pdr)
+		require.EqualError(t, err, "invalid policy: max transaction rate must not be negative, got -1.000000")
+
+		_, err = NewPlan(types.ShardingScheme{}, nil, Policy{}, nil)
+		require.ErrorIs(t, err, types.ErrSystemDescriptionIsNil)
+
+		_, err = NewPlan(newScheme(t, id0, id1), []ShardStats{{Shard: id0}}, Policy{}, pdr)
+		require.EqualError(t, err, `no statistics for shard "1"`)
+
+		_, err = NewPlan(types.ShardingScheme{}, []ShardStats{{Shard: emptyID}, {Shard: id0}}, Policy{}, pdr)
+		require.EqualError(t, err, "statistics contain shards which are not in the sharding scheme")
+
+		_, err = NewPlan(newScheme(t, id0, id1), []ShardStats{{Shard: id0}, {Shard: id0}}, Policy{}, pdr)
+		require.EqualError(t, err, `duplicate statistics for shard "0"`)
+
+		_, err = NewPlan(newScheme(t, id0, id1), []ShardStats{{Shard: id0, SampleIDs: []types.UnitID{unitID(0x80)}}, {Shard: id1}}, Policy{}, pdr)
+		require.EqualError(t, err, `invalid sample unit ID 800000000000000001 of shard "0": unit doesn't belong into the shard`)
+	})
+
+	t.Run("no change", func(t *testing.T) {
+		plan, err := NewPlan(types.ShardingScheme{}, []ShardStats{{Shard: emptyID, Units: 100, TxRate: 5, BlockSizes: []uint64{10, 20}}}, Policy{MaxUnits: 1000, MinUnits: 200}, pdr)
+		require.NoError(t, err)
+		require.True(t, plan.Change.IsEmpty())
+		require.Equal(t, []ShardForecast{{Shard: emptyID, Units: 100, TxRate: 5, BlockSize: 15}}, plan.Shards)
+	})
+
+	t.Run("split evenly", func(t *testing.T) {
+		// 1000 units, max 300 per shard - must be split into 4 shards
+		plan, err := NewPlan(types.ShardingScheme{}, []ShardStats{{Shard: emptyID, Units: 1000, TxRate: 8, BlockSizes: []uint64{400}}}, Policy{MaxUnits: 300}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{emptyID, id0, id1}, plan.Change.Splits)
+		require.Empty(t, plan.Change.Merges)
+		require.Equal(t, []types.ShardID{id00, id01, id10, id11}, slices.SortedFunc(plan.Scheme.All(), types.CompareShardIDs))
+		require.Equal(t, []ShardForecast{
+			{Shard: id00, Units: 250, TxRate: 2, BlockSize: 100},
+			{Shard: id01, Units: 250, TxRate: 2, BlockSize: 100},
+			{Shard: id10, Units: 250, TxRate: 2, BlockSize: 100},
+			{Shard: id11, Units: 250, TxRate: 2, BlockSize: 100},
+		}, plan.Shards)
+	})
+
+	t.Run("split according to sample", func(t *testing.T) {
+		// 3/4 of the units are in the "0" half
+		samples := []types.UnitID{unitID(0x00), unitID(0x10), unitID(0x50), unitID(0x90)}
+		stats := []ShardStats{{Shard: emptyID, Units: 400, TxRate: 4, SampleIDs: samples}}
+		plan, err := NewPlan(types.ShardingScheme{}, stats, Policy{MaxTxRate: 2.5}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{emptyID, id0}, plan.Change.Splits)
+		require.Equal(t, []ShardForecast{
+			{Shard: id00, Units: 200, TxRate: 2},
+			{Shard: id01, Units: 100, TxRate: 1},
+			{Shard: id1, Units: 100, TxRate: 1},
+		}, plan.Shards)
+	})
+
+	t.Run("max shard ID length", func(t *testing.T) {
+		plan, err := NewPlan(types.ShardingScheme{}, []ShardStats{{Shard: emptyID, Units: 1000}}, Policy{MaxUnits: 100, MaxShardIDLength: 1}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{emptyID}, plan.Change.Splits)
+		require.Equal(t, []types.ShardID{id0, id1}, plan.Overloaded)
+	})
+
+	t.Run("max number of shards", func(t *testing.T) {
+		plan, err := NewPlan(types.ShardingScheme{}, []ShardStats{{Shard: emptyID, Units: 1000}}, Policy{MaxUnits: 100, MaxShards: 3}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{emptyID, id0}, plan.Change.Splits)
+		require.Equal(t, []types.ShardID{id1, id00, id01}, plan.Overloaded)
+
+		// by default number of shards is limited even when the forecast would require
+		// splitting the shards to max shard ID length
+		plan, err = NewPlan(types.ShardingScheme{}, []ShardStats{{Shard: emptyID, Units: 1 << 62}}, Policy{MaxUnits: 1}, pdr)
+		require.NoError(t, err)
+		require.Len(t, plan.Shards, DefaultMaxShards)
+		require.Len(t, plan.Overloaded, DefaultMaxShards)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		stats := []ShardStats{
+			{Shard: id00, Units: 10, TxRate: 1},
+			{Shard: id01, Units: 20, TxRate: 1},
+			{Shard: id10, Units: 30, TxRate: 1},
+			{Shard: id11, Units: 40, TxRate: 1},
+		}
+		scheme := newScheme(t, id00, id01, id10, id11)
+
+		// merges all the way into single shard
+		plan, err := NewPlan(scheme, stats, Policy{MinUnits: 80, MaxUnits: 200}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{id0, id1, emptyID}, plan.Change.Merges)
+		require.Equal(t, []ShardForecast{{Shard: emptyID, Units: 100, TxRate: 4}}, plan.Shards)
+
+		// "1" has too many units to be merged
+		plan, err = NewPlan(scheme, stats, Policy{MinUnits: 50, MaxUnits: 200}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{id0, id1}, plan.Change.Merges)
+		require.Equal(t, []ShardForecast{{Shard: id0, Units: 30, TxRate: 2}, {Shard: id1, Units: 70, TxRate: 2}}, plan.Shards)
+
+		// merged shard would exceed the max tx rate
+		plan, err = NewPlan(scheme, stats, Policy{MinUnits: 80, MaxUnits: 200, MaxTxRate: 3}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{id0, id1}, plan.Change.Merges)
+
+		// only "0" children are small enough
+		plan, err = NewPlan(scheme, stats, Policy{MinUnits: 25}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, []types.ShardID{id0}, plan.Change.Merges)
+	})
+
+	t.Run("split and merge", func(t *testing.T) {
+		stats := []ShardStats{
+			{Shard: id0, Units: 500},
+			{Shard: id10, Units: 10},
+			{Shard: id11, Units: 10},
+		}
+		plan, err := NewPlan(newScheme(t, id0, id10, id11), stats, Policy{MinUnits: 100, MaxUnits: 300}, pdr)
+		require.NoError(t, err)
+		require.Equal(t, &types.ShardingSchemeChange{Merges: []types.ShardID{id1}, Splits: []types.ShardID{id0}}, plan.Change)
+		require.Equal(t, []ShardForecast{{Shard: id00, Units: 250}, {Shard: id01, Units: 250}, {Shard: id1, Units: 20}}, plan.Shards)
+	})
+}
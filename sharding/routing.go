@@ -0,0 +1,272 @@
+package sharding
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+var (
+	ErrUnknownPartition = errors.New("unknown partition")
+	ErrCrossShard       = errors.New("units are in different shards")
+)
+
+type (
+	// ShardRoute is the routing information of a shard.
+	ShardRoute struct {
+		Config    *types.PartitionDescriptionRecord // shard configuration, includes the validators of the shard
+		Endpoints []string                          // addresses of the nodes of the shard
+	}
+
+	// RoutingTable maps units to the shards of the partitions. It is safe for concurrent
+	// use, the routes of a partition are replaced atomically by SetPartition, ie readers
+	// see either the old or the new configuration of the partition, never a mix of them.
+	// Zero value is an empty routing table.
+	RoutingTable struct {
+		mu    sync.Mutex // serializes updates
+		state atomic.Pointer[map[types.PartitionID]*partitionRoutes]
+	}
+
+	partitionRoutes struct {
+		scheme types.ShardingScheme
+		shards map[string]*ShardRoute // key is ShardID.Key()
+		// validates the unit ID length, all the shards of the partition use the same length
+		validateID func(types.UnitID) error
+	}
+)
+
+// ShardID returns the ID of the shard described by the route.
+func (r *ShardRoute) ShardID() types.ShardID {
+	return r.Config.ShardID
+}
+
+// Validators returns the validators of the shard.
+func (r *ShardRoute) Validators() []*types.NodeInfo {
+	return r.Config.Validators
+}
+
+// clone returns a deep copy of the route, ie the copy doesn't share any data
+// (endpoints, configuration, validators) with the original.
+func (r *ShardRoute) clone() *ShardRoute {
+	return &ShardRoute{Config: r.Config.Clone(), Endpoints: slices.Clone(r.Endpoints)}
+}
+
+/*
+SetPartition replaces the sharding scheme and shard routes of the partition, there
+must be exactly one route for every shard in the "scheme". All the shard configurations
+must be of the same network and partition and the epochs must not be older than the
+epochs of the current routes of the same shards.
+The table stores copies of the "scheme" and "routes", ie the caller may modify them
+after the call without affecting the table.
+*/
+func (rt *RoutingTable) SetPartition(scheme types.ShardingScheme, routes []*ShardRoute) error {
+	if err := scheme.IsValid(); err != nil {
+		return fmt.Errorf("invalid sharding scheme: %w", err)
+	}
+	if len(routes) == 0 {
+		return errors.New("no shard routes")
+	}
+
+	pr := &partitionRoutes{scheme: scheme.Clone(), shards: make(map[string]*ShardRoute, len(routes))}
+	if routes[0] == nil || routes[0].Config == nil {
+		return errors.New("shard route 0 has no configuration")
+	}
+	first := routes[0].Config
+	for i, r := range routes {
+		if r == nil || r.Config == nil {
+			return fmt.Errorf("shard route %d has no configuration", i)
+		}
+		if err := r.Config.IsValid(); err != nil {
+			return fmt.Errorf("invalid configuration of shard route %d: %w", i, err)
+		}
+		if r.Config.NetworkID != first.NetworkID || r.Config.PartitionID != first.PartitionID {
+			return fmt.Errorf("shard route %d is for network %d partition %s, expected network %d partition %s",
+				i, r.Config.NetworkID, r.Config.PartitionID, first.NetworkID, first.PartitionID)
+		}
+		if r.Config.UnitIDLen != first.UnitIDLen || r.Config.TypeIDLen != first.TypeIDLen {
+			return fmt.Errorf("shard %q uses different unit ID length than shard %q", r.ShardID(), first.ShardID)
+		}
+		key := r.ShardID().Key()
+		if _, ok := pr.shards[key]; ok {
+			return fmt.Errorf("duplicate route for shard %q", r.ShardID())
+		}
+		pr.shards[key] = r.clone()
+	}
+	for id := range scheme.All() {
+		if _, ok := pr.shards[id.Key()]; !ok {
+			return fmt.Errorf("no route for shard %q", id)
+		}
+	}
+	if len(pr.shards) != len(slices.Collect(scheme.All())) {
+		return errors.New("routes contain shards which are not in the sharding scheme")
+	}
+	pr.validateID = first.UnitIDValidator(types.ShardID{})
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	current := rt.partitions()
+	if old, ok := current[first.PartitionID]; ok {
+		for key, r := range pr.shards {
+			if o, ok := old.shards[key]; ok && r.Config.Epoch < o.Config.Epoch {
+				return fmt.Errorf("shard %q epoch %d is older than the current epoch %d", r.ShardID(), r.Config.Epoch, o.Config.Epoch)
+			}
+		}
+	}
+	next := make(map[types.PartitionID]*partitionRoutes, len(current)+1)
+	for k, v := range current {
+		next[k] = v
+	}
+	next[first.PartitionID] = pr
+	rt.state.Store(&next)
+	return nil
+}
+
+// RemovePartition removes all the routes of the partition.
+func (rt *RoutingTable) RemovePartition(partitionID types.PartitionID) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	current := rt.partitions()
+	next := make(map[types.PartitionID]*partitionRoutes, len(current))
+	for k, v := range current {
+		if k != partitionID {
+			next[k] = v
+		}
+	}
+	rt.state.Store(&next)
+}
+
+// Scheme returns a copy of the sharding scheme of the partition.
+func (rt *RoutingTable) Scheme(partitionID types.PartitionID) (types.ShardingScheme, error) {
+	pr, err := rt.partition(partitionID)
+	if err != nil {
+		return types.ShardingScheme{}, err
+	}
+	return pr.scheme.Clone(), nil
+}
+
+/*
+Shard returns the route of the shard "shardID" of the partition.
+The route is a copy, ie modifying it doesn't affect the routing table.
+*/
+func (rt *RoutingTable) Shard(partitionID types.PartitionID, shardID types.ShardID) (*ShardRoute, error) {
+	pr, err := rt.partition(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := pr.shards[shardID.Key()]
+	if !ok {
+		return nil, fmt.Errorf("shard %q is not in the partition %s", shardID, partitionID)
+	}
+	return r.clone(), nil
+}
+
+/*
+Resolve returns (a copy of) the route of the shard the unit "unitID" of the
+partition belongs to.
+*/
+func (rt *RoutingTable) Resolve(partitionID types.PartitionID, unitID types.UnitID) (*ShardRoute, error) {
+	pr, err := rt.partition(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := pr.resolve(unitID)
+	if err != nil {
+		return nil, err
+	}
+	return r.clone(), nil
+}
+
+/*
+ResolveUnits returns (a copy of) the route of the shard the units "unitIDs" of the
+partition belong to. Returns ErrCrossShard when the units are in different shards.
+*/
+func (rt *RoutingTable) ResolveUnits(partitionID types.PartitionID, unitIDs ...types.UnitID) (*ShardRoute, error) {
+	if len(unitIDs) == 0 {
+		return nil, errors.New("no unit IDs to resolve")
+	}
+	pr, err := rt.partition(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	var route *ShardRoute
+	for _, id := range unitIDs {
+		r, err := pr.resolve(id)
+		if err != nil {
+			return nil, err
+		}
+		if route != nil && !r.ShardID().Equal(route.ShardID()) {
+			return nil, fmt.Errorf("%w: unit %s is in shard %q, unit %s in shard %q", ErrCrossShard, unitIDs[0], route.ShardID(), id, r.ShardID())
+		}
+		route = r
+	}
+	return route.clone(), nil
+}
+
+/*
+ResolveTxOrder returns the route of the shard the transaction order must be sent to,
+ie the shard of the target unit. The fee credit record of the transaction (when
+assigned) must be in the same shard, otherwise ErrCrossShard is returned.
+*/
+func (rt *RoutingTable) ResolveTxOrder(txo *types.TransactionOrder) (*ShardRoute, error) {
+	if txo == nil {
+		return nil, types.ErrTransactionOrderIsNil
+	}
+	ids := []types.UnitID{txo.UnitID}
+	if fcrID := txo.FeeCreditRecordID(); len(fcrID) != 0 {
+		ids = append(ids, fcrID)
+	}
+	return rt.ResolveUnits(txo.PartitionID, ids...)
+}
+
+/*
+ResolveTxRecord returns (copies of) the routes of the shards of all the units the
+executed transaction modified (see TransactionRecord.TargetUnits). When the result contains
+more than one route the transaction was a cross-shard transaction.
+*/
+func (rt *RoutingTable) ResolveTxRecord(txr *types.TransactionRecord) ([]*ShardRoute, error) {
+	txo, err := txr.GetTransactionOrderV1()
+	if err != nil {
+		return nil, fmt.Errorf("decoding transaction order: %w", err)
+	}
+	pr, err := rt.partition(txo.PartitionID)
+	if err != nil {
+		return nil, err
+	}
+	var routes []*ShardRoute
+	for _, id := range txr.TargetUnits() {
+		r, err := pr.resolve(id)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(routes, func(sr *ShardRoute) bool { return sr.ShardID().Equal(r.ShardID()) }) {
+			routes = append(routes, r.clone())
+		}
+	}
+	return routes, nil
+}
+
+func (rt *RoutingTable) partition(partitionID types.PartitionID) (*partitionRoutes, error) {
+	pr, ok := rt.partitions()[partitionID]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownPartition, partitionID)
+	}
+	return pr, nil
+}
+
+func (rt *RoutingTable) partitions() map[types.PartitionID]*partitionRoutes {
+	if p := rt.state.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (pr *partitionRoutes) resolve(unitID types.UnitID) (*ShardRoute, error) {
+	if err := pr.validateID(unitID); err != nil {
+		return nil, fmt.Errorf("invalid unit ID %s: %w", unitID, err)
+	}
+	return pr.shards[pr.scheme.Shard(unitID).Key()], nil
+}
@@ -0,0 +1,263 @@
+package sharding
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	testsig "github.com/alphabill-org/alphabill-go-base/testutils/sig"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_RoutingTable(t *testing.T) {
+	emptyID := types.ShardID{}
+	id0, id1 := emptyID.Split()
+	scheme, err := types.NewShardingScheme([]types.ShardID{id0, id1})
+	require.NoError(t, err)
+
+	newRoute := func(shard types.ShardID, epoch uint64) *ShardRoute {
+		return &ShardRoute{
+			Config: &types.PartitionDescriptionRecord{
+				Version:         1,
+				NetworkID:       5,
+				PartitionID:     1,
+				PartitionTypeID: 1,
+				ShardID:         shard,
+				TypeIDLen:       8,
+				UnitIDLen:       64,
+				T2Timeout:       2500 * time.Millisecond,
+				Epoch:           epoch,
+			},
+			Endpoints: []string{"/ip4/127.0.0.1/tcp/" + shard.String()},
+		}
+	}
+	unitID := func(b byte) types.UnitID { return types.UnitID{b, 0, 0, 0, 0, 0, 0, 0, 1} }
+
+	t.Run("empty table", func(t *testing.T) {
+		var rt RoutingTable
+		_, err := rt.Resolve(1, unitID(0))
+		require.ErrorIs(t, err, ErrUnknownPartition)
+		_, err = rt.Scheme(1)
+		require.EqualError(t, err, "unknown partition 00000001")
+	})
+
+	t.Run("invalid routes", func(t *testing.T) {
+		var rt RoutingTable
+		require.EqualError(t, rt.SetPartition(scheme, nil), "no shard routes")
+		require.EqualError(t, rt.SetPartition(scheme, []*ShardRoute{nil}), "shard route 0 has no configuration")
+		require.EqualError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 0)}), `no route for shard "1"`)
+		require.EqualError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 0), newRoute(id0, 0)}), `duplicate route for shard "0"`)
+		require.EqualError(t, rt.SetPartition(types.ShardingScheme{}, []*ShardRoute{newRoute(emptyID, 0), newRoute(id0, 0)}),
+			"routes contain shards which are not in the sharding scheme")
+
+		r := newRoute(id1, 0)
+		r.Config.PartitionID = 2
+		require.EqualError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 0), r}),
+			"shard route 1 is for network 5 partition 00000002, expected network 5 partition 00000001")
+
+		r = newRoute(id1, 0)
+		r.Config.UnitIDLen = 128
+		require.EqualError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 0), r}),
+			`shard "1" uses different unit ID length than shard "0"`)
+
+		r = newRoute(id1, 0)
+		r.Config.T2Timeout = 0
+		require.EqualError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 0), r}),
+			"invalid configuration of shard route 1: t2 timeout value out of allowed range: 0s")
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		var rt RoutingTable
+		r0, r1 := newRoute(id0, 1), newRoute(id1, 1)
+		require.NoError(t, rt.SetPartition(scheme, []*ShardRoute{r0, r1}))
+
+		ss, err := rt.Scheme(1)
+		require.NoError(t, err)
+		require.Equal(t, scheme, ss)
+
+		r, err := rt.Shard(1, id1)
+		require.NoError(t, err)
+		require.Equal(t, r1, r)
+		_, err = rt.Shard(1, emptyID)
+		require.EqualError(t, err, `shard "" is not in the partition 00000001`)
+
+		r, err = rt.Resolve(1, unitID(0x7F))
+		require.NoError(t, err)
+		require.Equal(t, r0, r)
+		require.Equal(t, id0, r.ShardID())
+		r, err = rt.Resolve(1, unitID(0x80))
+		require.NoError(t, err)
+		require.Equal(t, r1, r)
+
+		_, err = rt.Resolve(1, types.UnitID{1})
+		require.EqualError(t, err, "invalid unit ID 01: expected 9 byte unit ID, got 1 bytes")
+		_, err = rt.Resolve(2, unitID(0))
+		require.ErrorIs(t, err, ErrUnknownPartition)
+
+		r, err = rt.ResolveUnits(1, unitID(0x01), unitID(0x02))
+		require.NoError(t, err)
+		require.Equal(t, r0, r)
+		_, err = rt.ResolveUnits(1, unitID(0x01), unitID(0x81))
+		require.ErrorIs(t, err, ErrCrossShard)
+		require.EqualError(t, err, `units are in different shards: unit 010000000000000001 is in shard "0", unit 810000000000000001 in shard "1"`)
+		_, err = rt.ResolveUnits(1)
+		require.EqualError(t, err, "no unit IDs to resolve")
+	})
+
+	t.Run("resolve transactions", func(t *testing.T) {
+		var rt RoutingTable
+		r0, r1 := newRoute(id0, 1), newRoute(id1, 1)
+		require.NoError(t, rt.SetPartition(scheme, []*ShardRoute{r0, r1}))
+
+		txo := &types.TransactionOrder{Version: 1, Payload: types.Payload{PartitionID: 1, UnitID: unitID(0x90), ClientMetadata: &types.ClientMetadata{FeeCreditRecordID: unitID(0xA0)}}}
+		r, err := rt.ResolveTxOrder(txo)
+		require.NoError(t, err)
+		require.Equal(t, r1, r)
+
+		txo.ClientMetadata.FeeCreditRecordID = unitID(0x10)
+		_, err = rt.ResolveTxOrder(txo)
+		require.ErrorIs(t, err, ErrCrossShard)
+		_, err = rt.ResolveTxOrder(nil)
+		require.ErrorIs(t, err, types.ErrTransactionOrderIsNil)
+
+		txoBytes, err := txo.MarshalCBOR()
+		require.NoError(t, err)
+		txr := &types.TransactionRecord{
+			Version:          1,
+			TransactionOrder: txoBytes,
+			ServerMetadata:   &types.ServerMetadata{TargetUnits: []types.UnitID{unitID(0x90), unitID(0x91)}},
+		}
+		routes, err := rt.ResolveTxRecord(txr)
+		require.NoError(t, err)
+		require.Equal(t, []*ShardRoute{r1}, routes)
+
+		txr.ServerMetadata.TargetUnits = append(txr.ServerMetadata.TargetUnits, unitID(0x10))
+		routes, err = rt.ResolveTxRecord(txr)
+		require.NoError(t, err)
+		require.Equal(t, []*ShardRoute{r1, r0}, routes)
+	})
+
+	t.Run("input is copied", func(t *testing.T) {
+		var rt RoutingTable
+		ss, err := types.NewShardingScheme([]types.ShardID{id0, id1})
+		require.NoError(t, err)
+		r0, r1 := newRoute(id0, 1), newRoute(id1, 1)
+		require.NoError(t, rt.SetPartition(ss, []*ShardRoute{r0, r1}))
+
+		// modifying the scheme and routes of the caller doesn't affect the table
+		_, _, err = ss.Split(id1)
+		require.NoError(t, err)
+		r1.Config.Epoch = 5
+		r1.Endpoints[0] = "/ip4/10.0.0.1/tcp/1"
+
+		r, err := rt.Resolve(1, unitID(0xFF))
+		require.NoError(t, err)
+		require.Equal(t, id1, r.ShardID())
+		require.EqualValues(t, 1, r.Config.Epoch)
+		require.Equal(t, []string{"/ip4/127.0.0.1/tcp/1"}, r.Endpoints)
+
+		// nor does modifying the scheme returned by the table
+		ts, err := rt.Scheme(1)
+		require.NoError(t, err)
+		_, _, err = ts.Split(id0)
+		require.NoError(t, err)
+		ts, err = rt.Scheme(1)
+		require.NoError(t, err)
+		require.Equal(t, scheme, ts)
+	})
+
+	t.Run("returned routes are copies", func(t *testing.T) {
+		var rt RoutingTable
+		r0, r1 := newRoute(id0, 1), newRoute(id1, 1)
+		r1.Config.PartitionParams = map[string]string{"key": "value"}
+		_, verifier := testsig.CreateSignerAndVerifier(t)
+		sigKey, err := verifier.MarshalPublicKey()
+		require.NoError(t, err)
+		r1.Config.Validators = []*types.NodeInfo{{NodeID: "node1", SigKey: sigKey, Stake: 1}}
+		require.NoError(t, rt.SetPartition(scheme, []*ShardRoute{r0, r1}))
+		requireUnmodified := func(t *testing.T, r *ShardRoute) {
+			t.Helper()
+			require.Equal(t, id1, r.ShardID())
+			require.EqualValues(t, 1, r.Config.Epoch)
+			require.Equal(t, map[string]string{"key": "value"}, r.Config.PartitionParams)
+			require.Len(t, r.Validators(), 1)
+			require.Equal(t, "node1", r.Validators()[0].NodeID)
+			require.EqualValues(t, sigKey, r.Validators()[0].SigKey)
+			require.Equal(t, []string{"/ip4/127.0.0.1/tcp/1"}, r.Endpoints)
+		}
+
+		modify := func(r *ShardRoute) {
+			r.Config.Epoch = 5
+			r.Config.PartitionParams["key"] = "modified"
+			r.Config.Validators[0].NodeID = "node2"
+			r.Config.Validators[0].SigKey[0] = 9
+			r.Endpoints[0] = "/ip4/10.0.0.1/tcp/1"
+		}
+		r, err := rt.Shard(1, id1)
+		require.NoError(t, err)
+		requireUnmodified(t, r)
+		modify(r)
+		r, err = rt.Resolve(1, unitID(0xFF))
+		require.NoError(t, err)
+		requireUnmodified(t, r)
+		modify(r)
+		r, err = rt.ResolveUnits(1, unitID(0xFF), unitID(0xFE))
+		require.NoError(t, err)
+		requireUnmodified(t, r)
+		modify(r)
+
+		txoBytes, err := (&types.TransactionOrder{Version: 1, Payload: types.Payload{PartitionID: 1, UnitID: unitID(0xFF)}}).MarshalCBOR()
+		require.NoError(t, err)
+		routes, err := rt.ResolveTxRecord(&types.TransactionRecord{
+			Version:          1,
+			TransactionOrder: txoBytes,
+			ServerMetadata:   &types.ServerMetadata{TargetUnits: []types.UnitID{unitID(0xFF)}},
+		})
+		require.NoError(t, err)
+		require.Len(t, routes, 1)
+		requireUnmodified(t, routes[0])
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var rt RoutingTable
+		require.NoError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 2), newRoute(id1, 2)}))
+
+		// stale epoch is rejected and the current routes are kept
+		require.EqualError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 3), newRoute(id1, 1)}), `shard "1" epoch 1 is older than the current epoch 2`)
+		r, err := rt.Resolve(1, unitID(0))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, r.Config.Epoch)
+
+		// merge into single shard
+		require.NoError(t, rt.SetPartition(types.ShardingScheme{}, []*ShardRoute{newRoute(emptyID, 3)}))
+		r, err = rt.Resolve(1, unitID(0xFF))
+		require.NoError(t, err)
+		require.Equal(t, emptyID, r.ShardID())
+
+		rt.RemovePartition(1)
+		_, err = rt.Resolve(1, unitID(0))
+		require.ErrorIs(t, err, ErrUnknownPartition)
+	})
+
+	t.Run("concurrent updates", func(t *testing.T) {
+		var rt RoutingTable
+		require.NoError(t, rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, 0), newRoute(id1, 0)}))
+		var wg sync.WaitGroup
+		for i := range 10 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_ = rt.SetPartition(scheme, []*ShardRoute{newRoute(id0, uint64(i)), newRoute(id1, uint64(i))})
+			}()
+			go func() {
+				defer wg.Done()
+				r, err := rt.Resolve(1, unitID(0x80))
+				require.NoError(t, err)
+				require.Equal(t, id1, r.ShardID())
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,77 @@
+package mt
+
+import (
+	"crypto"
+	"fmt"
+
+	abhash "github.com/alphabill-org/alphabill-go-base/hash"
+)
+
+/*
+Accumulator calculates the root hash of the canonical Merkle tree (the same
+tree New builds) incrementally, leaf by leaf, without keeping the leaves in
+memory. Only the roots of the complete subtrees are kept so the memory use
+is logarithmic in the number of leaves.
+*/
+type Accumulator struct {
+	hashAlgorithm crypto.Hash
+	// roots of the perfect subtrees, sizes strictly decreasing from the bottom of the stack
+	stack []accItem
+	count int
+}
+
+type accItem struct {
+	hash []byte
+	size int
+}
+
+func NewAccumulator(hashAlgorithm crypto.Hash) *Accumulator {
+	return &Accumulator{hashAlgorithm: hashAlgorithm}
+}
+
+// Add hashes the data and appends it to the tree as the next leaf.
+func (a *Accumulator) Add(leaf Data) error {
+	h, err := leaf.Hash(a.hashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("failed to hash data: %w", err)
+	}
+	return a.AddHash(h)
+}
+
+// AddHash appends leaf hash to the tree.
+func (a *Accumulator) AddHash(leafHash []byte) error {
+	a.stack = append(a.stack, accItem{hash: leafHash, size: 1})
+	a.count++
+	for n := len(a.stack); n > 1 && a.stack[n-2].size == a.stack[n-1].size; n = len(a.stack) {
+		h, err := abhash.HashValues(a.hashAlgorithm, a.stack[n-2].hash, a.stack[n-1].hash)
+		if err != nil {
+			return fmt.Errorf("failed to hash child nodes: %w", err)
+		}
+		a.stack[n-2] = accItem{hash: h, size: 2 * a.stack[n-2].size}
+		a.stack = a.stack[:n-1]
+	}
+	return nil
+}
+
+// Count returns the number of leaves added to the tree.
+func (a *Accumulator) Count() int {
+	return a.count
+}
+
+/*
+GetRootHash returns the root hash of the tree built from the leaves added so far,
+nil when no leaves have been added. More leaves can be added after calling the method.
+*/
+func (a *Accumulator) GetRootHash() ([]byte, error) {
+	if len(a.stack) == 0 {
+		return nil, nil
+	}
+	h := a.stack[len(a.stack)-1].hash
+	for i := len(a.stack) - 2; i >= 0; i-- {
+		var err error
+		if h, err = abhash.HashValues(a.hashAlgorithm, a.stack[i].hash, h); err != nil {
+			return nil, fmt.Errorf("failed to hash child nodes: %w", err)
+		}
+	}
+	return h, nil
+}
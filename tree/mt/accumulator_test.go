@@ -0,0 +1,36 @@
+package mt
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccumulator(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		acc := NewAccumulator(crypto.SHA256)
+		h, err := acc.GetRootHash()
+		require.NoError(t, err)
+		require.Nil(t, h)
+		require.Zero(t, acc.Count())
+	})
+
+	t.Run("same root as New", func(t *testing.T) {
+		acc := NewAccumulator(crypto.SHA256)
+		var data []Data
+		for i := 0; i < 70; i++ {
+			data = append(data, &TestData{hash: makeData(byte(i))})
+			require.NoError(t, acc.Add(data[i]))
+			require.Equal(t, i+1, acc.Count())
+
+			tree, err := New(crypto.SHA256, data)
+			require.NoError(t, err)
+			h, err := acc.GetRootHash()
+			require.NoError(t, err)
+			require.Equal(t, tree.GetRootHash(), h, "leaf count %d", i+1)
+		}
+		// stack holds at most one subtree per bit of the leaf count
+		require.Len(t, acc.stack, 3) // 70 = 0b1000110
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"crypto"
 	"errors"
 	"fmt"
+	"strings"
 
 	abhash "github.com/alphabill-org/alphabill-go-base/hash"
 	"github.com/alphabill-org/alphabill-go-base/types/hex"
@@ -170,6 +171,38 @@ func (s *MerkleTree) output(node *node, prefix string, isTail bool, str *string)
 	}
 }
 
+/*
+DOT returns Graphviz DOT representation of the Merkle Tree. Leaves are labeled with
+the index of the data item and the hash, non-leaf nodes with the hash only.
+*/
+func (s *MerkleTree) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph MerkleTree {\n\tnode [shape=box];\n")
+	if s.root != nil {
+		var nodeCnt, leafCnt int
+		var walk func(n *node) int
+		walk = func(n *node) int {
+			id := nodeCnt
+			nodeCnt++
+			if n.left == nil && n.right == nil {
+				fmt.Fprintf(&sb, "\tn%d [label=\"leaf %d\\n%X\"];\n", id, leafCnt, n.hash)
+				leafCnt++
+				return id
+			}
+			fmt.Fprintf(&sb, "\tn%d [label=\"%X\"];\n", id, n.hash)
+			for _, c := range []*node{n.left, n.right} {
+				if c != nil {
+					fmt.Fprintf(&sb, "\tn%d -> n%d;\n", id, walk(c))
+				}
+			}
+			return id
+		}
+		walk(s.root)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
 func createMerkleTree[T Data](data []T, hashAlgorithm crypto.Hash) (*node, error) {
 	if len(data) == 0 {
 		return &node{hash: make([]byte, hashAlgorithm.Size())}, nil
@@ -275,3 +275,26 @@ func decodeHex(s string) []byte {
 	decode, _ := hex.DecodeString(s)
 	return decode
 }
+
+func TestDOT(t *testing.T) {
+	mt, err := New(crypto.SHA256, []Data{})
+	require.NoError(t, err)
+	require.Equal(t, "digraph MerkleTree {\n\tnode [shape=box];\n}\n", mt.DOT())
+
+	data := []Data{&TestData{hash: []byte{0}}, &TestData{hash: []byte{1}}, &TestData{hash: []byte{2}}}
+	mt, err = New(crypto.SHA256, data)
+	require.NoError(t, err)
+	left := mt.root.left
+	expected := "digraph MerkleTree {\n\tnode [shape=box];\n" +
+		fmt.Sprintf("\tn0 [label=\"%X\"];\n", mt.GetRootHash()) +
+		fmt.Sprintf("\tn1 [label=\"%X\"];\n", left.hash) +
+		"\tn2 [label=\"leaf 0\\n00\"];\n" +
+		"\tn1 -> n2;\n" +
+		"\tn3 [label=\"leaf 1\\n01\"];\n" +
+		"\tn1 -> n3;\n" +
+		"\tn0 -> n1;\n" +
+		"\tn4 [label=\"leaf 2\\n02\"];\n" +
+		"\tn0 -> n4;\n" +
+		"}\n"
+	require.Equal(t, expected, mt.DOT())
+}
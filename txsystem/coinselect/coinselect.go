@@ -0,0 +1,315 @@
+/*
+Package coinselect implements coin selection, ie choosing the units (bills,
+fungible tokens) to spend in order to pay given amount.
+
+Strategy is the pluggable part of the selection, Select validates the
+result of the strategy and calculates the change. Partition specific
+packages use the Selection to create transfer and split orders.
+*/
+package coinselect
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+)
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrNoExactMatch      = errors.New("no unit with exact value")
+	ErrTooManyCoins      = errors.New("payment requires too many units")
+)
+
+type (
+	// Coin is a unit with value which can be spent.
+	Coin struct {
+		ID    types.UnitID
+		Value uint64
+	}
+
+	// Strategy selects the coins to pay "amount" from "coins". Sum of the selected
+	// coins must be at least "amount" and when "maxCoins" is greater than zero at
+	// most that many coins may be selected.
+	Strategy func(coins []Coin, amount uint64, maxCoins int) ([]Coin, error)
+
+	// Params of the coin selection.
+	Params struct {
+		Amount   uint64   // amount to pay
+		Strategy Strategy // when nil MinimizeCount is used
+		MaxCoins int      // max number of units to spend, zero means no limit
+		// fee credit available for the payment, every unit spent requires a
+		// transaction which may cost up to MaxTransactionFee; zero means that
+		// the fee budget is not checked
+		FeeBudget uint64
+	}
+
+	// Selection is the result of the coin selection.
+	Selection struct {
+		// coins to be transferred whole to the receiver
+		Transfer []Coin
+		// coin to be split, ie only part of its value (Value-Change) goes to
+		// the receiver; nil when there is no change
+		Split *Coin
+		// amount left to the owner in the split coin
+		Change uint64
+	}
+)
+
+/*
+Select chooses coins to pay params.Amount using params.Strategy and validates the result.
+"maxFee" is the MaxTransactionFee of the transactions created for the payment, it is
+used to calculate how many units can be spent within params.FeeBudget.
+*/
+func Select(coins []Coin, params Params, maxFee uint64) (*Selection, error) {
+	if params.Amount == 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+	candidates := make([]Coin, 0, len(coins))
+	for _, c := range coins {
+		// zero value units can't be used for payment
+		if c.Value > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: available 0", ErrInsufficientFunds)
+	}
+	maxCoins := params.MaxCoins
+	if params.FeeBudget > 0 {
+		if params.FeeBudget < maxFee {
+			return nil, fmt.Errorf("fee budget %d does not cover the max transaction fee %d", params.FeeBudget, maxFee)
+		}
+		if maxFee > 0 {
+			// n >= 1 and there is at least one candidate so maxCoins doesn't become zero (no limit)
+			if n := params.FeeBudget / maxFee; maxCoins == 0 || n < uint64(maxCoins) {
+				maxCoins = int(min(n, uint64(len(candidates))))
+			}
+		}
+	}
+	strategy := params.Strategy
+	if strategy == nil {
+		strategy = MinimizeCount
+	}
+	selected, err := strategy(candidates, params.Amount, maxCoins)
+	if err != nil {
+		return nil, err
+	}
+	return newSelection(candidates, selected, params.Amount, maxCoins)
+}
+
+/*
+newSelection validates the coins selected by the strategy, "candidates" are the
+coins given to the strategy (ie zero value coins are already filtered out).
+*/
+func newSelection(candidates, selected []Coin, amount uint64, maxCoins int) (*Selection, error) {
+	if len(selected) == 0 {
+		return nil, errors.New("strategy didn't select any units")
+	}
+	if maxCoins > 0 && len(selected) > maxCoins {
+		return nil, fmt.Errorf("%w: strategy selected %d units, max allowed %d", ErrTooManyCoins, len(selected), maxCoins)
+	}
+	var sum uint64
+	for i, c := range selected {
+		if !slices.ContainsFunc(candidates, func(x Coin) bool { return bytes.Equal(x.ID, c.ID) && x.Value == c.Value }) {
+			return nil, fmt.Errorf("strategy selected unknown unit %s", c.ID)
+		}
+		if slices.ContainsFunc(selected[:i], func(x Coin) bool { return bytes.Equal(x.ID, c.ID) }) {
+			return nil, fmt.Errorf("strategy selected unit %s more than once", c.ID)
+		}
+		var ok bool
+		if sum, ok = util.SafeAdd(sum, c.Value); !ok {
+			return nil, errors.New("sum of the selected units overflows")
+		}
+	}
+	if sum < amount {
+		return nil, fmt.Errorf("%w: selected units sum up to %d, need %d", ErrInsufficientFunds, sum, amount)
+	}
+
+	sel := &Selection{Change: sum - amount}
+	if sel.Change == 0 {
+		sel.Transfer = selected
+		return sel, nil
+	}
+	// split the smallest coin which covers the change so that something goes
+	// to the receiver from it
+	idx := -1
+	for i, c := range selected {
+		if c.Value > sel.Change && (idx == -1 || c.Value < selected[idx].Value) {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("selection is not minimal, change %d is not less than value of any selected unit", sel.Change)
+	}
+	sel.Split = &selected[idx]
+	sel.Transfer = slices.Delete(slices.Clone(selected), idx, idx+1)
+	return sel, nil
+}
+
+// TxCount returns number of transactions required to execute the selection.
+func (s *Selection) TxCount() int {
+	if s.Split != nil {
+		return len(s.Transfer) + 1
+	}
+	return len(s.Transfer)
+}
+
+/*
+ExactMatch selects a single coin whose value is equal to the amount, so that
+no change is created. Returns ErrNoExactMatch when there is no such coin.
+*/
+func ExactMatch(coins []Coin, amount uint64, maxCoins int) ([]Coin, error) {
+	idx := slices.IndexFunc(coins, func(c Coin) bool { return c.Value == amount })
+	if idx == -1 {
+		return nil, ErrNoExactMatch
+	}
+	return []Coin{coins[idx]}, nil
+}
+
+/*
+LargestFirst selects the coins in descending order of value until the amount
+is covered.
+*/
+func LargestFirst(coins []Coin, amount uint64, maxCoins int) ([]Coin, error) {
+	sorted := sortByValueDesc(coins)
+	var sum uint64
+	for i, c := range sorted {
+		if maxCoins > 0 && i == maxCoins {
+			break
+		}
+		var ok bool
+		if sum, ok = util.SafeAdd(sum, c.Value); !ok || sum >= amount {
+			return sorted[:i+1], nil
+		}
+	}
+	return nil, insufficientFunds(sorted, amount, maxCoins)
+}
+
+/*
+MinimizeCount selects the minimal number of coins required to cover the amount.
+Out of the selections with minimal number of coins the one with (greedily) the
+smallest change is chosen, ie a single big coin is not split when there is a
+smaller one which covers the amount.
+*/
+func MinimizeCount(coins []Coin, amount uint64, maxCoins int) ([]Coin, error) {
+	sorted := sortByValueDesc(coins)
+	var sum uint64
+	for i, c := range sorted {
+		if maxCoins > 0 && i == maxCoins {
+			break
+		}
+		if s, ok := util.SafeAdd(sum, c.Value); ok && s < amount {
+			sum = s
+			continue
+		}
+		// sorted[i] completes the amount, replace it with the smallest coin which does
+		last := i
+		for j := len(sorted) - 1; j > i; j-- {
+			if s, ok := util.SafeAdd(sum, sorted[j].Value); !ok || s >= amount {
+				last = j
+				break
+			}
+		}
+		return append(slices.Clone(sorted[:i]), sorted[last]), nil
+	}
+	return nil, insufficientFunds(sorted, amount, maxCoins)
+}
+
+/*
+PrivacyPreserving returns strategy which prefers an exact match (no change
+output linking the payment to the payer) and otherwise selects coins in random
+order, dropping the coins which are not needed to cover the amount. When
+"rnd" is nil the default random source is used.
+*/
+func PrivacyPreserving(rnd *rand.Rand) Strategy {
+	return func(coins []Coin, amount uint64, maxCoins int) ([]Coin, error) {
+		if sel, err := ExactMatch(coins, amount, maxCoins); err == nil {
+			return sel, nil
+		}
+		shuffled := slices.Clone(coins)
+		shuffle := rand.Shuffle
+		if rnd != nil {
+			shuffle = rnd.Shuffle
+		}
+		shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		var sum uint64
+		for i, c := range shuffled {
+			var ok bool
+			if sum, ok = util.SafeAdd(sum, c.Value); !ok || sum >= amount {
+				if sel := dropUnneeded(shuffled[:i+1], amount); maxCoins == 0 || len(sel) <= maxCoins {
+					return sel, nil
+				}
+				// random selection requires too many coins, fall back to the minimal selection
+				return MinimizeCount(coins, amount, maxCoins)
+			}
+		}
+		return nil, fmt.Errorf("%w: available %d", ErrInsufficientFunds, sum)
+	}
+}
+
+/*
+FirstOf returns strategy which tries the given strategies in order and returns
+the result of the first one which succeeds.
+*/
+func FirstOf(strategies ...Strategy) Strategy {
+	return func(coins []Coin, amount uint64, maxCoins int) ([]Coin, error) {
+		var errs []error
+		for _, s := range strategies {
+			sel, err := s(coins, amount, maxCoins)
+			if err == nil {
+				return sel, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+/*
+dropUnneeded removes the smallest coins from the selection while the rest
+still covers the amount.
+*/
+func dropUnneeded(selected []Coin, amount uint64) []Coin {
+	sorted := sortByValueDesc(selected)
+	sum, ok := sumOf(sorted)
+	for i := len(sorted) - 1; ok && i > 0; i-- {
+		if sum-sorted[i].Value < amount {
+			continue
+		}
+		sum -= sorted[i].Value
+		sorted = slices.Delete(sorted, i, i+1)
+	}
+	return sorted
+}
+
+func sumOf(coins []Coin) (uint64, bool) {
+	values := make([]uint64, len(coins))
+	for i, c := range coins {
+		values[i] = c.Value
+	}
+	return util.AddUint64(values...)
+}
+
+func sortByValueDesc(coins []Coin) []Coin {
+	sorted := slices.Clone(coins)
+	slices.SortStableFunc(sorted, func(a, b Coin) int { return cmp.Compare(b.Value, a.Value) })
+	return sorted
+}
+
+/*
+insufficientFunds returns error explaining why the amount can't be paid with the
+coins, the "amount" is expected to be more than the strategy was able to select.
+*/
+func insufficientFunds(sorted []Coin, amount uint64, maxCoins int) error {
+	if sum, ok := sumOf(sorted); ok && sum < amount {
+		return fmt.Errorf("%w: available %d", ErrInsufficientFunds, sum)
+	}
+	return fmt.Errorf("%w: amount can't be paid with %d units", ErrTooManyCoins, maxCoins)
+}
@@ -0,0 +1,250 @@
+package coinselect
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func testCoins(values ...uint64) []Coin {
+	coins := make([]Coin, len(values))
+	for i, v := range values {
+		coins[i] = Coin{ID: types.UnitID{byte(i + 1)}, Value: v}
+	}
+	return coins
+}
+
+func values(coins []Coin) []uint64 {
+	res := make([]uint64, len(coins))
+	for i, c := range coins {
+		res[i] = c.Value
+	}
+	return res
+}
+
+func Test_Select(t *testing.T) {
+	coins := testCoins(5, 20, 0, 7, 3)
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := Select(coins, Params{}, 0)
+		require.EqualError(t, err, "amount must be greater than zero")
+
+		_, err = Select(coins, Params{Amount: 1, FeeBudget: 1}, 2)
+		require.EqualError(t, err, "fee budget 1 does not cover the max transaction fee 2")
+
+		_, err = Select(coins, Params{Amount: 100}, 0)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+		require.EqualError(t, err, "insufficient funds: available 35")
+	})
+
+	t.Run("exact amount", func(t *testing.T) {
+		sel, err := Select(coins, Params{Amount: 7}, 0)
+		require.NoError(t, err)
+		require.Equal(t, []Coin{coins[3]}, sel.Transfer)
+		require.Nil(t, sel.Split)
+		require.Zero(t, sel.Change)
+		require.Equal(t, 1, sel.TxCount())
+	})
+
+	t.Run("change", func(t *testing.T) {
+		sel, err := Select(coins, Params{Amount: 26, Strategy: LargestFirst}, 0)
+		require.NoError(t, err)
+		require.Equal(t, []Coin{coins[1]}, sel.Transfer)
+		require.Equal(t, &coins[3], sel.Split)
+		require.EqualValues(t, 1, sel.Change)
+		require.Equal(t, 2, sel.TxCount())
+	})
+
+	t.Run("max coins", func(t *testing.T) {
+		_, err := Select(coins, Params{Amount: 29, MaxCoins: 2}, 0)
+		require.ErrorIs(t, err, ErrTooManyCoins)
+		sel, err := Select(coins, Params{Amount: 29, MaxCoins: 3}, 0)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{20, 7}, values(sel.Transfer))
+		require.Equal(t, &coins[4], sel.Split)
+		require.EqualValues(t, 1, sel.Change)
+	})
+
+	t.Run("fee budget", func(t *testing.T) {
+		// budget allows to pay for two transactions only
+		_, err := Select(coins, Params{Amount: 29, FeeBudget: 5}, 2)
+		require.ErrorIs(t, err, ErrTooManyCoins)
+		// MaxCoins is more restrictive than the fee budget
+		_, err = Select(coins, Params{Amount: 26, MaxCoins: 1, FeeBudget: 10}, 2)
+		require.ErrorIs(t, err, ErrTooManyCoins)
+		sel, err := Select(coins, Params{Amount: 26, FeeBudget: 4}, 2)
+		require.NoError(t, err)
+		require.Equal(t, 2, sel.TxCount())
+	})
+
+	t.Run("no usable coins", func(t *testing.T) {
+		// with fee budget the max number of coins must not become zero (no limit)
+		_, err := Select(nil, Params{Amount: 1, FeeBudget: 10}, 2)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+		require.EqualError(t, err, "insufficient funds: available 0")
+
+		_, err = Select(testCoins(0, 0), Params{Amount: 1, FeeBudget: 10}, 2)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		_, err = Select(nil, Params{Amount: 1}, 0)
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	})
+
+	t.Run("invalid strategy result", func(t *testing.T) {
+		strategy := func(result []Coin, err error) Strategy {
+			return func(coins []Coin, amount uint64, maxCoins int) ([]Coin, error) { return result, err }
+		}
+		expErr := errors.New("nope")
+		_, err := Select(coins, Params{Amount: 1, Strategy: strategy(nil, expErr)}, 0)
+		require.ErrorIs(t, err, expErr)
+
+		_, err = Select(coins, Params{Amount: 1, Strategy: strategy(nil, nil)}, 0)
+		require.EqualError(t, err, "strategy didn't select any units")
+
+		_, err = Select(coins, Params{Amount: 1, MaxCoins: 1, Strategy: strategy(coins[:2], nil)}, 0)
+		require.EqualError(t, err, "payment requires too many units: strategy selected 2 units, max allowed 1")
+
+		_, err = Select(coins, Params{Amount: 1, Strategy: strategy([]Coin{{ID: types.UnitID{9}, Value: 5}}, nil)}, 0)
+		require.EqualError(t, err, "strategy selected unknown unit 09")
+
+		_, err = Select(coins, Params{Amount: 1, Strategy: strategy([]Coin{coins[0], coins[0]}, nil)}, 0)
+		require.EqualError(t, err, "strategy selected unit 01 more than once")
+
+		_, err = Select(coins, Params{Amount: 30, Strategy: strategy(coins[:2], nil)}, 0)
+		require.EqualError(t, err, "insufficient funds: selected units sum up to 25, need 30")
+
+		_, err = Select(coins, Params{Amount: 3, Strategy: strategy([]Coin{coins[0], coins[4]}, nil)}, 0)
+		require.EqualError(t, err, "selection is not minimal, change 5 is not less than value of any selected unit")
+
+		// zero value unit is not given to the strategy so it can't be selected
+		_, err = Select(coins, Params{Amount: 5, Strategy: strategy([]Coin{coins[0], coins[2]}, nil)}, 0)
+		require.EqualError(t, err, "strategy selected unknown unit 03")
+
+		big := testCoins(math.MaxUint64, 1)
+		_, err = Select(big, Params{Amount: 5, Strategy: strategy(big, nil)}, 0)
+		require.EqualError(t, err, "sum of the selected units overflows")
+	})
+}
+
+func Test_ExactMatch(t *testing.T) {
+	coins := testCoins(5, 20, 7)
+	sel, err := ExactMatch(coins, 20, 0)
+	require.NoError(t, err)
+	require.Equal(t, []Coin{coins[1]}, sel)
+
+	sel, err = ExactMatch(coins, 12, 0)
+	require.ErrorIs(t, err, ErrNoExactMatch)
+	require.Nil(t, sel)
+}
+
+func Test_LargestFirst(t *testing.T) {
+	coins := testCoins(5, 20, 7, 3)
+	sel, err := LargestFirst(coins, 21, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20, 7}, values(sel))
+
+	sel, err = LargestFirst(coins, 35, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20, 7, 5, 3}, values(sel))
+
+	_, err = LargestFirst(coins, 36, 0)
+	require.EqualError(t, err, "insufficient funds: available 35")
+
+	_, err = LargestFirst(coins, 30, 2)
+	require.EqualError(t, err, "payment requires too many units: amount can't be paid with 2 units")
+}
+
+func Test_MinimizeCount(t *testing.T) {
+	coins := testCoins(5, 20, 7, 3, 100)
+	// single coin covers the amount, the smallest such coin is used
+	sel, err := MinimizeCount(coins, 6, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{7}, values(sel))
+
+	sel, err = MinimizeCount(coins, 20, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20}, values(sel))
+
+	// two coins are needed, the second is the smallest which completes the amount
+	sel, err = MinimizeCount(coins, 104, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{100, 5}, values(sel))
+
+	sel, err = MinimizeCount(coins, 128, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{100, 20, 7, 3}, values(sel))
+
+	_, err = MinimizeCount(coins, 128, 3)
+	require.ErrorIs(t, err, ErrTooManyCoins)
+
+	_, err = MinimizeCount(coins, 200, 0)
+	require.EqualError(t, err, "insufficient funds: available 135")
+
+	// overflow of the sum means the amount is covered
+	big := testCoins(math.MaxUint64, math.MaxUint64)
+	sel, err = MinimizeCount(big, math.MaxUint64, 0)
+	require.NoError(t, err)
+	require.Len(t, sel, 1)
+}
+
+func Test_PrivacyPreserving(t *testing.T) {
+	coins := testCoins(5, 20, 7, 3, 100, 1, 2)
+	strategy := PrivacyPreserving(rand.New(rand.NewPCG(1, 2)))
+
+	t.Run("exact match", func(t *testing.T) {
+		sel, err := strategy(coins, 7, 0)
+		require.NoError(t, err)
+		require.Equal(t, []Coin{coins[2]}, sel)
+	})
+
+	t.Run("random selection", func(t *testing.T) {
+		for amount := uint64(1); amount <= 138; amount++ {
+			sel, err := strategy(coins, amount, 0)
+			require.NoError(t, err)
+			s, err := newSelection(coins, sel, amount, 0)
+			require.NoError(t, err, "amount %d", amount)
+			require.Equal(t, len(sel), s.TxCount())
+		}
+	})
+
+	t.Run("falls back to minimal selection", func(t *testing.T) {
+		for range 20 {
+			sel, err := strategy(coins, 120, 2)
+			require.NoError(t, err)
+			require.ElementsMatch(t, []uint64{100, 20}, values(sel))
+		}
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		_, err := strategy(coins, 139, 0)
+		require.EqualError(t, err, "insufficient funds: available 138")
+	})
+
+	t.Run("default random source", func(t *testing.T) {
+		sel, err := PrivacyPreserving(nil)(coins, 50, 0)
+		require.NoError(t, err)
+		_, err = newSelection(coins, sel, 50, 0)
+		require.NoError(t, err)
+	})
+}
+
+func Test_FirstOf(t *testing.T) {
+	coins := testCoins(5, 20, 7)
+	strategy := FirstOf(ExactMatch, LargestFirst)
+	sel, err := strategy(coins, 7, 0)
+	require.NoError(t, err)
+	require.Equal(t, []Coin{coins[2]}, sel)
+
+	sel, err = strategy(coins, 8, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20}, values(sel))
+
+	_, err = strategy(coins, 100, 0)
+	require.ErrorIs(t, err, ErrNoExactMatch)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
@@ -0,0 +1,171 @@
+/*
+Package consolidation implements the partition independent part of joining
+several units into one target unit, ie the money dust collection and the
+fungible token burn-and-join flows.
+
+Both flows consist of two steps: first the value of every collected unit is
+transferred to the target unit (dust transfer, burn) and then the proofs of
+these transactions are used to add the value to the target unit (swap, join).
+Partition specific packages create the transaction orders and attributes,
+this package selects the units and matches the proofs back to the plan.
+*/
+package consolidation
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+)
+
+type (
+	// Unit is a unit with value which can be consolidated into the target unit.
+	Unit struct {
+		ID      types.UnitID
+		Value   uint64
+		Counter uint64
+	}
+
+	// Transfer is the partition independent content of the transaction which
+	// transfers the value of the unit to the target unit.
+	Transfer struct {
+		Value             uint64
+		Counter           uint64 // counter of the unit whose value is transferred
+		TargetUnitID      types.UnitID
+		TargetUnitCounter uint64
+	}
+
+	// Plan of the consolidation, ie transaction orders which transfer value of
+	// the units to the target unit.
+	Plan struct {
+		TargetUnitID      types.UnitID
+		TargetUnitCounter uint64
+		Value             uint64 // sum of the values of the units
+		TxType            uint16 // type of the transactions in Orders
+		// transaction orders, ordered by unit ID
+		Orders []*types.TransactionOrder
+	}
+)
+
+/*
+Select returns the units to be consolidated into the "target" unit, ordered by
+unit ID, and the sum of their values. The target unit itself is skipped when
+it is in "units".
+
+When "maxUnits" is greater than zero at most that many units (with the smallest
+values) are selected.
+*/
+func Select(target Unit, units []Unit, maxUnits int) ([]Unit, uint64, error) {
+	selected := make([]Unit, 0, len(units))
+	for _, u := range units {
+		if !bytes.Equal(u.ID, target.ID) {
+			selected = append(selected, u)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, 0, errors.New("no units to consolidate")
+	}
+	// prefer smaller units, when value is the same order by ID for deterministic result
+	slices.SortFunc(selected, func(a, b Unit) int {
+		if a.Value != b.Value {
+			return cmp.Compare(a.Value, b.Value)
+		}
+		return bytes.Compare(a.ID, b.ID)
+	})
+	if maxUnits > 0 && len(selected) > maxUnits {
+		selected = selected[:maxUnits]
+	}
+	// swap and join require proofs in strictly increasing order of unit IDs
+	slices.SortFunc(selected, func(a, b Unit) int { return bytes.Compare(a.ID, b.ID) })
+
+	var sum uint64
+	for i, u := range selected {
+		if i > 0 && bytes.Equal(u.ID, selected[i-1].ID) {
+			return nil, 0, fmt.Errorf("duplicate unit %s", u.ID)
+		}
+		var ok bool
+		if sum, ok = util.SafeAdd(sum, u.Value); !ok {
+			return nil, 0, errors.New("sum of the unit values overflows")
+		}
+	}
+	if _, ok := util.SafeAdd(target.Value, sum); !ok {
+		return nil, 0, errors.New("value of the target unit would overflow")
+	}
+	return selected, sum, nil
+}
+
+/*
+MatchProofs verifies that "proofs" are proofs of successfully executed transactions
+of the plan and returns them in the order of the plan's Orders. The proofs may be
+in any order but there must be a proof for every order of the plan and the value
+and unit counter of every proof must match the order of the plan for the same unit.
+
+The "decode" callback decodes (and validates partition specific fields of) the
+attributes of the transaction, it is called both for the transactions in the
+proofs and for the orders of the plan.
+
+Only the content of the proofs is validated, caller must verify the proofs
+against trust base (see types.TxRecordProof.Verify).
+*/
+func (p *Plan) MatchProofs(proofs []*types.TxRecordProof, decode func(txo *types.TransactionOrder) (*Transfer, error)) ([]*types.TxRecordProof, error) {
+	if len(proofs) != len(p.Orders) {
+		return nil, fmt.Errorf("expected %d proofs, got %d", len(p.Orders), len(proofs))
+	}
+	sorted := make([]*types.TxRecordProof, len(p.Orders))
+	var sum uint64
+	for i, proof := range proofs {
+		if err := proof.IsValid(); err != nil {
+			return nil, fmt.Errorf("invalid proof %d: %w", i, err)
+		}
+		txo, err := proof.GetTransactionOrderV1()
+		if err != nil {
+			return nil, fmt.Errorf("decoding transaction order of proof %d: %w", i, err)
+		}
+		if txo.Type != p.TxType {
+			return nil, fmt.Errorf("proof %d: expected transaction type %d, got %d", i, p.TxType, txo.Type)
+		}
+		if !proof.TxRecord.IsSuccessful() {
+			return nil, fmt.Errorf("proof %d: transaction of unit %s failed with status %d", i, txo.UnitID, proof.TxStatus())
+		}
+		tr, err := decode(txo)
+		if err != nil {
+			return nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		if !bytes.Equal(tr.TargetUnitID, p.TargetUnitID) {
+			return nil, fmt.Errorf("proof %d: target unit %s, expected %s", i, tr.TargetUnitID, p.TargetUnitID)
+		}
+		if tr.TargetUnitCounter != p.TargetUnitCounter {
+			return nil, fmt.Errorf("proof %d: target unit counter %d, expected %d", i, tr.TargetUnitCounter, p.TargetUnitCounter)
+		}
+		idx := slices.IndexFunc(p.Orders, func(tx *types.TransactionOrder) bool { return bytes.Equal(tx.UnitID, txo.UnitID) })
+		if idx == -1 {
+			return nil, fmt.Errorf("proof %d: unit %s is not part of the plan", i, txo.UnitID)
+		}
+		if sorted[idx] != nil {
+			return nil, fmt.Errorf("proof %d: duplicate proof for unit %s", i, txo.UnitID)
+		}
+		planned, err := decode(p.Orders[idx])
+		if err != nil {
+			return nil, fmt.Errorf("decoding order of unit %s of the plan: %w", txo.UnitID, err)
+		}
+		if tr.Value != planned.Value {
+			return nil, fmt.Errorf("proof %d: transferred value %d of unit %s, expected %d", i, tr.Value, txo.UnitID, planned.Value)
+		}
+		if tr.Counter != planned.Counter {
+			return nil, fmt.Errorf("proof %d: counter %d of unit %s, expected %d", i, tr.Counter, txo.UnitID, planned.Counter)
+		}
+		sorted[idx] = proof
+		var ok bool
+		if sum, ok = util.SafeAdd(sum, tr.Value); !ok {
+			return nil, errors.New("sum of the transferred values overflows")
+		}
+	}
+	if sum != p.Value {
+		return nil, fmt.Errorf("sum of the transferred values %d, expected %d", sum, p.Value)
+	}
+	return sorted, nil
+}
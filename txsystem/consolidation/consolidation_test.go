@@ -0,0 +1,195 @@
+package consolidation
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+type testAttributes struct {
+	_                 struct{} `cbor:",toarray"`
+	Value             uint64
+	TargetUnitID      types.UnitID
+	TargetUnitCounter uint64
+	Counter           uint64
+}
+
+func decodeTestAttributes(txo *types.TransactionOrder) (*Transfer, error) {
+	attr := &testAttributes{}
+	if err := txo.UnmarshalAttributes(attr); err != nil {
+		return nil, err
+	}
+	return &Transfer{Value: attr.Value, Counter: attr.Counter, TargetUnitID: attr.TargetUnitID, TargetUnitCounter: attr.TargetUnitCounter}, nil
+}
+
+func Test_Select(t *testing.T) {
+	target := Unit{ID: types.UnitID{1}, Value: 100, Counter: 7}
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, _, err := Select(target, nil, 0)
+		require.EqualError(t, err, "no units to consolidate")
+
+		_, _, err = Select(target, []Unit{target}, 0)
+		require.EqualError(t, err, "no units to consolidate")
+
+		_, _, err = Select(target, []Unit{{ID: types.UnitID{2}, Value: 1}, {ID: types.UnitID{2}, Value: 1}}, 0)
+		require.EqualError(t, err, "duplicate unit 02")
+
+		_, _, err = Select(target, []Unit{{ID: types.UnitID{2}, Value: math.MaxUint64}, {ID: types.UnitID{3}, Value: 1}}, 0)
+		require.EqualError(t, err, "sum of the unit values overflows")
+
+		_, _, err = Select(Unit{ID: types.UnitID{1}, Value: math.MaxUint64}, []Unit{{ID: types.UnitID{2}, Value: 1}}, 0)
+		require.EqualError(t, err, "value of the target unit would overflow")
+	})
+
+	t.Run("smallest units ordered by ID", func(t *testing.T) {
+		units := []Unit{
+			{ID: types.UnitID{5}, Value: 50},
+			target,
+			{ID: types.UnitID{3}, Value: 1, Counter: 2},
+			{ID: types.UnitID{4}, Value: 1, Counter: 3},
+			{ID: types.UnitID{2}, Value: 10, Counter: 4},
+		}
+		selected, sum, err := Select(target, units, 3)
+		require.NoError(t, err)
+		require.EqualValues(t, 12, sum)
+		require.Equal(t, []Unit{units[4], units[2], units[3]}, selected)
+
+		selected, sum, err = Select(target, units, 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 62, sum)
+		require.Equal(t, []Unit{units[4], units[2], units[3], units[0]}, selected)
+	})
+}
+
+func Test_Plan_MatchProofs(t *testing.T) {
+	const txType = 3
+	plan := &Plan{
+		TargetUnitID:      types.UnitID{1},
+		TargetUnitCounter: 7,
+		Value:             5,
+		TxType:            txType,
+	}
+	newOrder := func(t *testing.T, unitID types.UnitID, typ uint16, attr *testAttributes) *types.TransactionOrder {
+		txo, err := types.NewTransactionOrder(types.Payload{NetworkID: 3, PartitionID: 1}, unitID, typ, attr)
+		require.NoError(t, err)
+		return txo
+	}
+	newProof := func(t *testing.T, txo *types.TransactionOrder, status types.TxStatus) *types.TxRecordProof {
+		txoBytes, err := cbor.Marshal(txo)
+		require.NoError(t, err)
+		return &types.TxRecordProof{
+			TxRecord: &types.TransactionRecord{
+				Version:          1,
+				TransactionOrder: txoBytes,
+				ServerMetadata:   &types.ServerMetadata{SuccessIndicator: status},
+			},
+			TxProof: &types.TxProof{Version: 1},
+		}
+	}
+	plan.Orders = []*types.TransactionOrder{
+		newOrder(t, types.UnitID{2}, txType, &testAttributes{Value: 2, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7, Counter: 4}),
+		newOrder(t, types.UnitID{3}, txType, &testAttributes{Value: 3, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7, Counter: 5}),
+	}
+	proofs := []*types.TxRecordProof{
+		newProof(t, plan.Orders[1], types.TxStatusSuccessful),
+		newProof(t, plan.Orders[0], types.TxStatusSuccessful),
+	}
+	// proof for the unit 02 with modified attributes
+	proofWithAttr := func(t *testing.T, attr *testAttributes) *types.TxRecordProof {
+		return newProof(t, newOrder(t, types.UnitID{2}, txType, attr), types.TxStatusSuccessful)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		sorted, err := plan.MatchProofs(proofs, decodeTestAttributes)
+		require.NoError(t, err)
+		require.Equal(t, []*types.TxRecordProof{proofs[1], proofs[0]}, sorted)
+	})
+
+	t.Run("wrong number of proofs", func(t *testing.T) {
+		_, err := plan.MatchProofs(proofs[:1], decodeTestAttributes)
+		require.EqualError(t, err, "expected 2 proofs, got 1")
+	})
+
+	t.Run("invalid proof", func(t *testing.T) {
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], nil}, decodeTestAttributes)
+		require.EqualError(t, err, "invalid proof 1: transaction record proof is nil")
+	})
+
+	t.Run("duplicate proof", func(t *testing.T) {
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], proofs[0]}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: duplicate proof for unit 03")
+	})
+
+	t.Run("failed transaction", func(t *testing.T) {
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], newProof(t, plan.Orders[0], types.TxErrOutOfGas)}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: transaction of unit 02 failed with status 2")
+	})
+
+	t.Run("wrong transaction type", func(t *testing.T) {
+		txo := newOrder(t, types.UnitID{2}, txType+1, &testAttributes{Value: 2, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7})
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], newProof(t, txo, types.TxStatusSuccessful)}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: expected transaction type 3, got 4")
+	})
+
+	t.Run("decoding attributes fails", func(t *testing.T) {
+		expErr := errors.New("invalid attributes")
+		_, err := plan.MatchProofs(proofs, func(txo *types.TransactionOrder) (*Transfer, error) { return nil, expErr })
+		require.ErrorIs(t, err, expErr)
+		require.EqualError(t, err, "proof 0: invalid attributes")
+	})
+
+	t.Run("wrong target", func(t *testing.T) {
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], proofWithAttr(t, &testAttributes{Value: 2, TargetUnitID: types.UnitID{9}, TargetUnitCounter: 7, Counter: 4})}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: target unit 09, expected 01")
+
+		// target unit counter changed between planning and transfer
+		_, err = plan.MatchProofs([]*types.TxRecordProof{proofs[0], proofWithAttr(t, &testAttributes{Value: 2, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 8, Counter: 4})}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: target unit counter 8, expected 7")
+	})
+
+	t.Run("value mismatch", func(t *testing.T) {
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], proofWithAttr(t, &testAttributes{Value: 20, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7, Counter: 4})}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: transferred value 20 of unit 02, expected 2")
+
+		// the sum of the values matches the plan but the values of the units are swapped
+		swapped := []*types.TxRecordProof{
+			newProof(t, newOrder(t, types.UnitID{2}, txType, &testAttributes{Value: 3, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7, Counter: 4}), types.TxStatusSuccessful),
+			newProof(t, newOrder(t, types.UnitID{3}, txType, &testAttributes{Value: 2, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7, Counter: 5}), types.TxStatusSuccessful),
+		}
+		_, err = plan.MatchProofs(swapped, decodeTestAttributes)
+		require.EqualError(t, err, "proof 0: transferred value 3 of unit 02, expected 2")
+
+		// plan's total value doesn't match the orders of the plan
+		plan2 := *plan
+		plan2.Value = 6
+		_, err = plan2.MatchProofs(proofs, decodeTestAttributes)
+		require.EqualError(t, err, "sum of the transferred values 5, expected 6")
+	})
+
+	t.Run("counter mismatch", func(t *testing.T) {
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], proofWithAttr(t, &testAttributes{Value: 2, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7, Counter: 5})}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: counter 5 of unit 02, expected 4")
+	})
+
+	t.Run("decoding planned order fails", func(t *testing.T) {
+		plan2 := *plan
+		txo := plan.Orders[1]
+		txo2 := *txo
+		txo2.Attributes = []byte{0x63, 0x66, 0x6f, 0x6f} // "foo"
+		plan2.Orders = []*types.TransactionOrder{plan.Orders[0], &txo2}
+		_, err := plan2.MatchProofs(proofs, decodeTestAttributes)
+		require.ErrorContains(t, err, "decoding order of unit 03 of the plan: ")
+	})
+
+	t.Run("unit not in plan", func(t *testing.T) {
+		txo := newOrder(t, types.UnitID{8}, txType, &testAttributes{Value: 2, TargetUnitID: plan.TargetUnitID, TargetUnitCounter: 7})
+		_, err := plan.MatchProofs([]*types.TxRecordProof{proofs[0], newProof(t, txo, types.TxStatusSuccessful)}, decodeTestAttributes)
+		require.EqualError(t, err, "proof 1: unit 08 is not part of the plan")
+	})
+}
@@ -1,6 +1,9 @@
 package fc
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/alphabill-org/alphabill-go-base/types"
 )
 
@@ -43,8 +46,9 @@ type (
 	}
 
 	LockFeeCreditAttributes struct {
-		_       struct{} `cbor:",toarray"`
-		Counter uint64   // the transaction counter of the target unit
+		_          struct{} `cbor:",toarray"`
+		LockStatus uint64   // status of the lock, non-zero value means locked (see types.LockReason)
+		Counter    uint64   // the transaction counter of the target unit
 	}
 
 	UnlockFeeCreditAttributes struct {
@@ -62,3 +66,86 @@ func IsFeeCreditTx(tx *types.TransactionOrder) bool {
 		tx.Type == TransactionTypeAddFeeCredit ||
 		tx.Type == TransactionTypeCloseFeeCredit
 }
+
+func (a *AddFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.FeeCreditOwnerPredicate) == 0 {
+		return errors.New("fee credit owner predicate is empty")
+	}
+	if err := a.FeeCreditTransferProof.IsValid(); err != nil {
+		return fmt.Errorf("transfer fee credit proof: %w", err)
+	}
+	return nil
+}
+
+func (a *TransferFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if a.Amount == 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	if a.TargetPartitionID == 0 {
+		return errors.New("target partition ID is unassigned")
+	}
+	if len(a.TargetRecordID) == 0 {
+		return errors.New("target record ID is empty")
+	}
+	return nil
+}
+
+func (a *CloseFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if a.Amount == 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	if len(a.TargetUnitID) == 0 {
+		return errors.New("target unit ID is empty")
+	}
+	return nil
+}
+
+func (a *ReclaimFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if err := a.CloseFeeCreditProof.IsValid(); err != nil {
+		return fmt.Errorf("close fee credit proof: %w", err)
+	}
+	return nil
+}
+
+func (a *LockFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if err := types.LockReason(a.LockStatus).IsValid(); err != nil {
+		return fmt.Errorf("invalid lock status: %w", err)
+	}
+	return nil
+}
+
+func (a *UnlockFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	return nil
+}
+
+/*
+VerifyCounter validates the attributes (see IsValid) and checks that the counter
+of the attributes is the current "counter" of the locked fee credit record.
+*/
+func (a *UnlockFeeCreditAttributes) VerifyCounter(counter uint64) error {
+	if err := a.IsValid(); err != nil {
+		return err
+	}
+	if a.Counter != counter {
+		return fmt.Errorf("counter %d does not match the counter of the fee credit record %d", a.Counter, counter)
+	}
+	return nil
+}
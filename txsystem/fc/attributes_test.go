@@ -0,0 +1,79 @@
+package fc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func testTxRecordProof(t *testing.T, txType uint16) *types.TxRecordProof {
+	txo := &types.TransactionOrder{Version: 1, Payload: types.Payload{Type: txType}}
+	txoBytes, err := txo.MarshalCBOR()
+	require.NoError(t, err)
+	return &types.TxRecordProof{
+		TxRecord: &types.TransactionRecord{
+			Version:          1,
+			TransactionOrder: txoBytes,
+			ServerMetadata:   &types.ServerMetadata{SuccessIndicator: types.TxStatusSuccessful},
+		},
+		TxProof: &types.TxProof{Version: 1},
+	}
+}
+
+func Test_AddFeeCreditAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*AddFeeCreditAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&AddFeeCreditAttributes{FeeCreditTransferProof: testTxRecordProof(t, TransactionTypeTransferFeeCredit)}).IsValid(),
+		"fee credit owner predicate is empty")
+	require.EqualError(t, (&AddFeeCreditAttributes{FeeCreditOwnerPredicate: []byte{1}}).IsValid(),
+		"transfer fee credit proof: transaction record proof is nil")
+	require.NoError(t, (&AddFeeCreditAttributes{FeeCreditOwnerPredicate: []byte{1}, FeeCreditTransferProof: testTxRecordProof(t, TransactionTypeTransferFeeCredit)}).IsValid())
+}
+
+func Test_TransferFeeCreditAttributes_IsValid(t *testing.T) {
+	valid := func() *TransferFeeCreditAttributes {
+		return &TransferFeeCreditAttributes{Amount: 10, TargetPartitionID: 1, TargetRecordID: []byte{1}}
+	}
+	require.NoError(t, valid().IsValid())
+	require.EqualError(t, (*TransferFeeCreditAttributes)(nil).IsValid(), "attributes are nil")
+
+	attr := valid()
+	attr.Amount = 0
+	require.EqualError(t, attr.IsValid(), "amount must be greater than zero")
+
+	attr = valid()
+	attr.TargetPartitionID = 0
+	require.EqualError(t, attr.IsValid(), "target partition ID is unassigned")
+
+	attr = valid()
+	attr.TargetRecordID = nil
+	require.EqualError(t, attr.IsValid(), "target record ID is empty")
+}
+
+func Test_CloseFeeCreditAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*CloseFeeCreditAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&CloseFeeCreditAttributes{TargetUnitID: []byte{1}}).IsValid(), "amount must be greater than zero")
+	require.EqualError(t, (&CloseFeeCreditAttributes{Amount: 1}).IsValid(), "target unit ID is empty")
+	require.NoError(t, (&CloseFeeCreditAttributes{Amount: 1, TargetUnitID: []byte{1}}).IsValid())
+}
+
+func Test_ReclaimFeeCreditAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*ReclaimFeeCreditAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&ReclaimFeeCreditAttributes{}).IsValid(), "close fee credit proof: transaction record proof is nil")
+	require.NoError(t, (&ReclaimFeeCreditAttributes{CloseFeeCreditProof: testTxRecordProof(t, TransactionTypeCloseFeeCredit)}).IsValid())
+}
+
+func Test_LockUnlockFeeCreditAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*LockFeeCreditAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&LockFeeCreditAttributes{Counter: 1}).IsValid(), "invalid lock status: invalid lock reason 0")
+	require.EqualError(t, (&LockFeeCreditAttributes{LockStatus: 99}).IsValid(), "invalid lock status: invalid lock reason 99")
+	require.NoError(t, (&LockFeeCreditAttributes{LockStatus: uint64(types.LockReasonAddFees)}).IsValid())
+
+	require.EqualError(t, (*UnlockFeeCreditAttributes)(nil).IsValid(), "attributes are nil")
+	require.NoError(t, (&UnlockFeeCreditAttributes{}).IsValid())
+
+	require.EqualError(t, (*UnlockFeeCreditAttributes)(nil).VerifyCounter(0), "attributes are nil")
+	require.EqualError(t, (&UnlockFeeCreditAttributes{Counter: 3}).VerifyCounter(4), "counter 3 does not match the counter of the fee credit record 4")
+	require.NoError(t, (&UnlockFeeCreditAttributes{Counter: 4}).VerifyCounter(4))
+}
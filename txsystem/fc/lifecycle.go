@@ -0,0 +1,428 @@
+package fc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+var (
+	ErrInvalidFlowState   = errors.New("invalid fee credit flow state")
+	ErrFeeCreditExpired   = errors.New("latest addition time of the fee credit transfer has passed")
+	ErrTransactionFailed  = errors.New("transaction failed")
+	ErrUnexpectedTxRecord = errors.New("transaction record does not match the fee credit flow")
+)
+
+// FlowState is the state of the fee credit add or reclaim flow.
+type FlowState uint8
+
+const (
+	FlowStateNew FlowState = iota
+	// transferFC (add flow) or closeFC (reclaim flow) order has been created,
+	// waiting for the proof of the transaction
+	FlowStateFirstPending
+	// proof of the first transaction has been received, the second transaction
+	// (addFC or reclaimFC) can be created
+	FlowStateFirstDone
+	// addFC or reclaimFC order has been created, waiting for the proof of the transaction
+	FlowStateSecondPending
+	FlowStateDone
+	// the addFC transaction can't be executed anymore as the latest addition
+	// time of the transferFC has passed, the transferred amount is lost
+	FlowStateExpired
+)
+
+func (s FlowState) String() string {
+	switch s {
+	case FlowStateNew:
+		return "new"
+	case FlowStateFirstPending:
+		return "first pending"
+	case FlowStateFirstDone:
+		return "first done"
+	case FlowStateSecondPending:
+		return "second pending"
+	case FlowStateDone:
+		return "done"
+	case FlowStateExpired:
+		return "expired"
+	default:
+		return fmt.Sprintf("FlowState(%d)", uint8(s))
+	}
+}
+
+type (
+	// FeeCreditRecordIDFunc derives the ID of the fee credit record in the target partition, ie
+	// money.NewFeeCreditRecordIDFromOwnerPredicate or tokens.NewFeeCreditRecordIDFromOwnerPredicate.
+	FeeCreditRecordIDFunc func(pdr *types.PartitionDescriptionRecord, shard types.ShardID, ownerPredicate []byte, latestAdditionTime uint64) (types.UnitID, error)
+
+	AddFeeCreditParams struct {
+		Amount         uint64       // amount to transfer from the bill
+		BillID         types.UnitID // the bill (in money partition) to transfer the fee credit from
+		BillCounter    uint64       // current counter of the bill
+		OwnerPredicate []byte       // owner predicate of the fee credit record
+
+		TargetPDR   *types.PartitionDescriptionRecord // the partition to add the fee credit to
+		TargetShard types.ShardID
+		// latest round of the target partition when the addFC transaction can be executed
+		LatestAdditionTime uint64
+		// ID and counter of the existing fee credit record, when ID is nil new
+		// record ID is derived using FeeCreditRecordID
+		TargetRecordID      types.UnitID
+		TargetRecordCounter *uint64
+		FeeCreditRecordID   FeeCreditRecordIDFunc
+	}
+
+	// AddFeeCreditFlow drives the process of obtaining fee credit in the target partition:
+	// transferFC transaction in the money partition followed by addFC transaction in the
+	// target partition. The addFC must be executed before the target partition reaches
+	// the LatestAdditionTime round, otherwise the transferred amount is lost.
+	//
+	// Orders can be re-created (ie when the previous order timed out without being
+	// executed) as the bill counter (transferFC) and the fee credit record state
+	// (addFC) guarantee that only one of them can be executed.
+	AddFeeCreditFlow struct {
+		state         FlowState
+		params        AddFeeCreditParams
+		recordID      types.UnitID
+		transferProof *types.TxRecordProof
+		addProof      *types.TxRecordProof
+	}
+
+	ReclaimFeeCreditParams struct {
+		RecordID      types.UnitID // the fee credit record to close
+		RecordCounter uint64       // current counter of the fee credit record
+		Amount        uint64       // current balance of the fee credit record
+		BillID        types.UnitID // the bill (in money partition) to reclaim the fee credit to
+		BillCounter   uint64       // current counter of the bill, must not change before reclaimFC is executed
+	}
+
+	// ReclaimFeeCreditFlow drives the process of moving the fee credit back to the bill in
+	// money partition: closeFC transaction in the partition of the fee credit record followed
+	// by reclaimFC transaction in the money partition.
+	ReclaimFeeCreditFlow struct {
+		state        FlowState
+		params       ReclaimFeeCreditParams
+		closeProof   *types.TxRecordProof
+		reclaimProof *types.TxRecordProof
+	}
+)
+
+func NewAddFeeCreditFlow(params AddFeeCreditParams) (*AddFeeCreditFlow, error) {
+	if params.Amount == 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+	if len(params.BillID) == 0 {
+		return nil, errors.New("bill ID is empty")
+	}
+	if len(params.OwnerPredicate) == 0 {
+		return nil, errors.New("owner predicate is empty")
+	}
+	if params.TargetPDR == nil {
+		return nil, errors.New("target partition description is nil")
+	}
+	flow := &AddFeeCreditFlow{params: params, recordID: params.TargetRecordID}
+	if len(flow.recordID) == 0 {
+		if params.TargetRecordCounter != nil {
+			return nil, errors.New("target record counter is set but target record ID is not")
+		}
+		if params.FeeCreditRecordID == nil {
+			return nil, errors.New("fee credit record ID function is required to create new record")
+		}
+		var err error
+		if flow.recordID, err = params.FeeCreditRecordID(params.TargetPDR, params.TargetShard, params.OwnerPredicate, params.LatestAdditionTime); err != nil {
+			return nil, fmt.Errorf("deriving fee credit record ID: %w", err)
+		}
+	}
+	return flow, nil
+}
+
+func (f *AddFeeCreditFlow) State() FlowState { return f.state }
+
+// FeeCreditRecordID returns the ID of the fee credit record the fee credit is added to.
+func (f *AddFeeCreditFlow) FeeCreditRecordID() types.UnitID { return f.recordID }
+
+// AddProof returns the proof of the executed addFC transaction, nil until the flow is done.
+func (f *AddFeeCreditFlow) AddProof() *types.TxRecordProof { return f.addProof }
+
+/*
+TransferOrder returns the transferFC order (without auth proof) to be sent to the
+money partition, "txTemplate" must have NetworkID, PartitionID and ClientMetadata
+of the money partition assigned.
+*/
+func (f *AddFeeCreditFlow) TransferOrder(txTemplate types.Payload) (*types.TransactionOrder, error) {
+	if f.state != FlowStateNew && f.state != FlowStateFirstPending {
+		return nil, fmt.Errorf("%w: can't create transfer order in state %s", ErrInvalidFlowState, f.state)
+	}
+	txo, err := types.NewTransactionOrder(txTemplate, f.params.BillID, TransactionTypeTransferFeeCredit, f.transferAttributes())
+	if err != nil {
+		return nil, fmt.Errorf("creating transfer fee credit order: %w", err)
+	}
+	f.state = FlowStateFirstPending
+	return txo, nil
+}
+
+/*
+SetTransferProof validates that the "proof" is proof of the transferFC transaction of
+the flow. When the transaction failed ErrTransactionFailed is returned and the flow
+returns to the initial state (when the bill counter changed new flow must be created).
+
+Only the content of the proof is validated, caller must verify the proof against
+trust base (see types.TxRecordProof.Verify).
+*/
+func (f *AddFeeCreditFlow) SetTransferProof(proof *types.TxRecordProof) error {
+	if f.state != FlowStateFirstPending {
+		return fmt.Errorf("%w: can't accept transfer proof in state %s", ErrInvalidFlowState, f.state)
+	}
+	txo, err := checkProof(proof, TransactionTypeTransferFeeCredit, f.params.BillID)
+	if err != nil {
+		return err
+	}
+	attr := &TransferFeeCreditAttributes{}
+	if err := txo.UnmarshalAttributes(attr); err != nil {
+		return fmt.Errorf("decoding transfer fee credit attributes: %w", err)
+	}
+	exp := f.transferAttributes()
+	if attr.Amount != exp.Amount || attr.TargetPartitionID != exp.TargetPartitionID || !bytes.Equal(attr.TargetRecordID, exp.TargetRecordID) ||
+		attr.LatestAdditionTime != exp.LatestAdditionTime || !equalCounters(attr.TargetUnitCounter, exp.TargetUnitCounter) || attr.Counter != exp.Counter {
+		return fmt.Errorf("%w: transfer fee credit attributes do not match", ErrUnexpectedTxRecord)
+	}
+	if !proof.TxRecord.IsSuccessful() {
+		f.state = FlowStateNew
+		return fmt.Errorf("%w: transfer fee credit status %d", ErrTransactionFailed, proof.TxStatus())
+	}
+	f.transferProof = proof
+	f.state = FlowStateFirstDone
+	return nil
+}
+
+/*
+AddOrder returns the addFC order (without auth proof) to be sent to the target partition,
+"txTemplate" must have NetworkID, PartitionID and ClientMetadata of the target partition
+assigned. The timeout of the order is capped to the latest addition time so that the order
+which can't be executed anymore is not accepted by the partition.
+
+"currentRound" is the current round of the target partition, when it's past the latest
+addition time ErrFeeCreditExpired is returned and the flow moves into expired state.
+*/
+func (f *AddFeeCreditFlow) AddOrder(txTemplate types.Payload, currentRound uint64) (*types.TransactionOrder, error) {
+	if f.state != FlowStateFirstDone && f.state != FlowStateSecondPending {
+		return nil, fmt.Errorf("%w: can't create add order in state %s", ErrInvalidFlowState, f.state)
+	}
+	if f.IsExpired(currentRound) {
+		return nil, ErrFeeCreditExpired
+	}
+	attr := &AddFeeCreditAttributes{FeeCreditOwnerPredicate: f.params.OwnerPredicate, FeeCreditTransferProof: f.transferProof}
+	txo, err := types.NewTransactionOrder(txTemplate, f.recordID, TransactionTypeAddFeeCredit, attr)
+	if err != nil {
+		return nil, fmt.Errorf("creating add fee credit order: %w", err)
+	}
+	if txo.ClientMetadata == nil {
+		txo.ClientMetadata = &types.ClientMetadata{}
+	}
+	if txo.ClientMetadata.Timeout == 0 || txo.ClientMetadata.Timeout > f.params.LatestAdditionTime {
+		txo.ClientMetadata.Timeout = f.params.LatestAdditionTime
+	}
+	f.state = FlowStateSecondPending
+	return txo, nil
+}
+
+/*
+SetAddProof validates that the "proof" is proof of the addFC transaction of the flow.
+When the transaction failed ErrTransactionFailed is returned and the flow stays in
+the pending state, ie new add order can be created (as long as the transfer hasn't
+expired).
+*/
+func (f *AddFeeCreditFlow) SetAddProof(proof *types.TxRecordProof) error {
+	if f.state != FlowStateSecondPending {
+		return fmt.Errorf("%w: can't accept add proof in state %s", ErrInvalidFlowState, f.state)
+	}
+	txo, err := checkProof(proof, TransactionTypeAddFeeCredit, f.recordID)
+	if err != nil {
+		return err
+	}
+	attr := &AddFeeCreditAttributes{}
+	if err := txo.UnmarshalAttributes(attr); err != nil {
+		return fmt.Errorf("decoding add fee credit attributes: %w", err)
+	}
+	if !bytes.Equal(attr.FeeCreditOwnerPredicate, f.params.OwnerPredicate) {
+		return fmt.Errorf("%w: fee credit owner predicate does not match", ErrUnexpectedTxRecord)
+	}
+	if !proof.TxRecord.IsSuccessful() {
+		return fmt.Errorf("%w: add fee credit status %d", ErrTransactionFailed, proof.TxStatus())
+	}
+	f.addProof = proof
+	f.state = FlowStateDone
+	return nil
+}
+
+/*
+IsExpired returns true when the transferred fee credit can't be added anymore, ie the
+"currentRound" of the target partition is past the latest addition time. When the
+transfer has been executed but not added the flow moves into expired state.
+*/
+func (f *AddFeeCreditFlow) IsExpired(currentRound uint64) bool {
+	if f.state == FlowStateExpired {
+		return true
+	}
+	if currentRound <= f.params.LatestAdditionTime {
+		return false
+	}
+	if f.state == FlowStateFirstDone || f.state == FlowStateSecondPending {
+		f.state = FlowStateExpired
+	}
+	return f.state != FlowStateDone
+}
+
+func (f *AddFeeCreditFlow) transferAttributes() *TransferFeeCreditAttributes {
+	return &TransferFeeCreditAttributes{
+		Amount:             f.params.Amount,
+		TargetPartitionID:  f.params.TargetPDR.PartitionID,
+		TargetRecordID:     f.recordID,
+		LatestAdditionTime: f.params.LatestAdditionTime,
+		TargetUnitCounter:  f.params.TargetRecordCounter,
+		Counter:            f.params.BillCounter,
+	}
+}
+
+func NewReclaimFeeCreditFlow(params ReclaimFeeCreditParams) (*ReclaimFeeCreditFlow, error) {
+	if len(params.RecordID) == 0 {
+		return nil, errors.New("fee credit record ID is empty")
+	}
+	if params.Amount == 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+	if len(params.BillID) == 0 {
+		return nil, errors.New("bill ID is empty")
+	}
+	return &ReclaimFeeCreditFlow{params: params}, nil
+}
+
+func (f *ReclaimFeeCreditFlow) State() FlowState { return f.state }
+
+// ReclaimProof returns the proof of the executed reclaimFC transaction, nil until the flow is done.
+func (f *ReclaimFeeCreditFlow) ReclaimProof() *types.TxRecordProof { return f.reclaimProof }
+
+/*
+CloseOrder returns the closeFC order (without auth proof) to be sent to the partition of
+the fee credit record, "txTemplate" must have NetworkID, PartitionID and ClientMetadata
+of that partition assigned.
+*/
+func (f *ReclaimFeeCreditFlow) CloseOrder(txTemplate types.Payload) (*types.TransactionOrder, error) {
+	if f.state != FlowStateNew && f.state != FlowStateFirstPending {
+		return nil, fmt.Errorf("%w: can't create close order in state %s", ErrInvalidFlowState, f.state)
+	}
+	txo, err := types.NewTransactionOrder(txTemplate, f.params.RecordID, TransactionTypeCloseFeeCredit, f.closeAttributes())
+	if err != nil {
+		return nil, fmt.Errorf("creating close fee credit order: %w", err)
+	}
+	f.state = FlowStateFirstPending
+	return txo, nil
+}
+
+/*
+SetCloseProof validates that the "proof" is proof of the closeFC transaction of the flow.
+When the transaction failed ErrTransactionFailed is returned and the flow returns to the
+initial state.
+*/
+func (f *ReclaimFeeCreditFlow) SetCloseProof(proof *types.TxRecordProof) error {
+	if f.state != FlowStateFirstPending {
+		return fmt.Errorf("%w: can't accept close proof in state %s", ErrInvalidFlowState, f.state)
+	}
+	txo, err := checkProof(proof, TransactionTypeCloseFeeCredit, f.params.RecordID)
+	if err != nil {
+		return err
+	}
+	attr := &CloseFeeCreditAttributes{}
+	if err := txo.UnmarshalAttributes(attr); err != nil {
+		return fmt.Errorf("decoding close fee credit attributes: %w", err)
+	}
+	exp := f.closeAttributes()
+	if attr.Amount != exp.Amount || !bytes.Equal(attr.TargetUnitID, exp.TargetUnitID) || attr.TargetUnitCounter != exp.TargetUnitCounter || attr.Counter != exp.Counter {
+		return fmt.Errorf("%w: close fee credit attributes do not match", ErrUnexpectedTxRecord)
+	}
+	if !proof.TxRecord.IsSuccessful() {
+		f.state = FlowStateNew
+		return fmt.Errorf("%w: close fee credit status %d", ErrTransactionFailed, proof.TxStatus())
+	}
+	f.closeProof = proof
+	f.state = FlowStateFirstDone
+	return nil
+}
+
+/*
+ReclaimOrder returns the reclaimFC order (without auth proof) to be sent to the money
+partition, "txTemplate" must have NetworkID, PartitionID and ClientMetadata of the
+money partition assigned.
+*/
+func (f *ReclaimFeeCreditFlow) ReclaimOrder(txTemplate types.Payload) (*types.TransactionOrder, error) {
+	if f.state != FlowStateFirstDone && f.state != FlowStateSecondPending {
+		return nil, fmt.Errorf("%w: can't create reclaim order in state %s", ErrInvalidFlowState, f.state)
+	}
+	attr := &ReclaimFeeCreditAttributes{CloseFeeCreditProof: f.closeProof}
+	txo, err := types.NewTransactionOrder(txTemplate, f.params.BillID, TransactionTypeReclaimFeeCredit, attr)
+	if err != nil {
+		return nil, fmt.Errorf("creating reclaim fee credit order: %w", err)
+	}
+	f.state = FlowStateSecondPending
+	return txo, nil
+}
+
+/*
+SetReclaimProof validates that the "proof" is proof of the reclaimFC transaction of the
+flow. When the transaction failed ErrTransactionFailed is returned and the flow stays in
+the pending state, ie new reclaim order can be created.
+*/
+func (f *ReclaimFeeCreditFlow) SetReclaimProof(proof *types.TxRecordProof) error {
+	if f.state != FlowStateSecondPending {
+		return fmt.Errorf("%w: can't accept reclaim proof in state %s", ErrInvalidFlowState, f.state)
+	}
+	if _, err := checkProof(proof, TransactionTypeReclaimFeeCredit, f.params.BillID); err != nil {
+		return err
+	}
+	if !proof.TxRecord.IsSuccessful() {
+		return fmt.Errorf("%w: reclaim fee credit status %d", ErrTransactionFailed, proof.TxStatus())
+	}
+	f.reclaimProof = proof
+	f.state = FlowStateDone
+	return nil
+}
+
+func (f *ReclaimFeeCreditFlow) closeAttributes() *CloseFeeCreditAttributes {
+	return &CloseFeeCreditAttributes{
+		Amount:            f.params.Amount,
+		TargetUnitID:      f.params.BillID,
+		TargetUnitCounter: f.params.BillCounter,
+		Counter:           f.params.RecordCounter,
+	}
+}
+
+/*
+checkProof validates the proof and returns the transaction order of it when it's of type
+"txType" and targets unit "unitID". The status of the transaction is not checked.
+*/
+func checkProof(proof *types.TxRecordProof, txType uint16, unitID types.UnitID) (*types.TransactionOrder, error) {
+	if err := proof.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid proof: %w", err)
+	}
+	txo, err := proof.GetTransactionOrderV1()
+	if err != nil {
+		return nil, fmt.Errorf("decoding transaction order: %w", err)
+	}
+	if txo.Type != txType {
+		return nil, fmt.Errorf("%w: expected transaction type %d, got %d", ErrUnexpectedTxRecord, txType, txo.Type)
+	}
+	if !bytes.Equal(txo.UnitID, unitID) {
+		return nil, fmt.Errorf("%w: expected unit %s, got %s", ErrUnexpectedTxRecord, unitID, txo.UnitID)
+	}
+	return txo, nil
+}
+
+func equalCounters(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,243 @@
+package fc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func newTestProof(t *testing.T, txo *types.TransactionOrder, status types.TxStatus) *types.TxRecordProof {
+	txoBytes, err := txo.MarshalCBOR()
+	require.NoError(t, err)
+	return &types.TxRecordProof{
+		TxRecord: &types.TransactionRecord{
+			Version:          1,
+			TransactionOrder: txoBytes,
+			ServerMetadata:   &types.ServerMetadata{SuccessIndicator: status},
+		},
+		TxProof: &types.TxProof{Version: 1},
+	}
+}
+
+func Test_AddFeeCreditFlow(t *testing.T) {
+	targetPDR := &types.PartitionDescriptionRecord{Version: 1, NetworkID: 5, PartitionID: 2, UnitIDLen: 64, TypeIDLen: 8}
+	moneyTmpl := types.Payload{NetworkID: 5, PartitionID: 1, ClientMetadata: &types.ClientMetadata{Timeout: 100, MaxTransactionFee: 2}}
+	targetTmpl := types.Payload{NetworkID: 5, PartitionID: 2, ClientMetadata: &types.ClientMetadata{Timeout: 1000, MaxTransactionFee: 2}}
+	recordID := types.UnitID{1, 2, 3, 4, 5, 6, 7, 8, 16}
+	fcrIDFunc := func(pdr *types.PartitionDescriptionRecord, shard types.ShardID, ownerPredicate []byte, latestAdditionTime uint64) (types.UnitID, error) {
+		require.Equal(t, targetPDR, pdr)
+		require.EqualValues(t, 500, latestAdditionTime)
+		return recordID, nil
+	}
+	params := func() AddFeeCreditParams {
+		return AddFeeCreditParams{
+			Amount:             50,
+			BillID:             types.UnitID{1, 1},
+			BillCounter:        3,
+			OwnerPredicate:     []byte{0x53},
+			TargetPDR:          targetPDR,
+			LatestAdditionTime: 500,
+			FeeCreditRecordID:  fcrIDFunc,
+		}
+	}
+
+	t.Run("invalid params", func(t *testing.T) {
+		var testCases = []struct {
+			modify func(p *AddFeeCreditParams)
+			errMsg string
+		}{
+			{func(p *AddFeeCreditParams) { p.Amount = 0 }, "amount must be greater than zero"},
+			{func(p *AddFeeCreditParams) { p.BillID = nil }, "bill ID is empty"},
+			{func(p *AddFeeCreditParams) { p.OwnerPredicate = nil }, "owner predicate is empty"},
+			{func(p *AddFeeCreditParams) { p.TargetPDR = nil }, "target partition description is nil"},
+			{func(p *AddFeeCreditParams) { p.TargetRecordCounter = new(uint64) }, "target record counter is set but target record ID is not"},
+			{func(p *AddFeeCreditParams) { p.FeeCreditRecordID = nil }, "fee credit record ID function is required to create new record"},
+			{func(p *AddFeeCreditParams) {
+				p.FeeCreditRecordID = func(*types.PartitionDescriptionRecord, types.ShardID, []byte, uint64) (types.UnitID, error) {
+					return nil, errors.New("boom")
+				}
+			}, "deriving fee credit record ID: boom"},
+		}
+		for _, tc := range testCases {
+			p := params()
+			tc.modify(&p)
+			_, err := NewAddFeeCreditFlow(p)
+			require.EqualError(t, err, tc.errMsg)
+		}
+	})
+
+	t.Run("existing record", func(t *testing.T) {
+		p := params()
+		p.TargetRecordID = types.UnitID{9}
+		p.TargetRecordCounter = new(uint64)
+		p.FeeCreditRecordID = nil
+		flow, err := NewAddFeeCreditFlow(p)
+		require.NoError(t, err)
+		require.Equal(t, types.UnitID{9}, flow.FeeCreditRecordID())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		flow, err := NewAddFeeCreditFlow(params())
+		require.NoError(t, err)
+		require.Equal(t, FlowStateNew, flow.State())
+		require.Equal(t, recordID, flow.FeeCreditRecordID())
+
+		_, err = flow.AddOrder(targetTmpl, 1)
+		require.ErrorIs(t, err, ErrInvalidFlowState)
+
+		transfer, err := flow.TransferOrder(moneyTmpl)
+		require.NoError(t, err)
+		require.Equal(t, FlowStateFirstPending, flow.State())
+		require.Equal(t, TransactionTypeTransferFeeCredit, transfer.Type)
+		require.Equal(t, types.UnitID{1, 1}, transfer.UnitID)
+		attr := &TransferFeeCreditAttributes{}
+		require.NoError(t, transfer.UnmarshalAttributes(attr))
+		require.Equal(t, &TransferFeeCreditAttributes{Amount: 50, TargetPartitionID: 2, TargetRecordID: recordID, LatestAdditionTime: 500, Counter: 3}, attr)
+
+		// proof of some other transaction
+		other := *transfer
+		other.UnitID = types.UnitID{1, 2}
+		require.ErrorIs(t, flow.SetTransferProof(newTestProof(t, &other, types.TxStatusSuccessful)), ErrUnexpectedTxRecord)
+		require.NoError(t, other.SetAttributes(&TransferFeeCreditAttributes{Amount: 51, TargetPartitionID: 2, TargetRecordID: recordID, LatestAdditionTime: 500, Counter: 3}))
+		other.UnitID = transfer.UnitID
+		require.EqualError(t, flow.SetTransferProof(newTestProof(t, &other, types.TxStatusSuccessful)),
+			"transaction record does not match the fee credit flow: transfer fee credit attributes do not match")
+		require.Equal(t, FlowStateFirstPending, flow.State())
+
+		transferProof := newTestProof(t, transfer, types.TxStatusSuccessful)
+		require.NoError(t, flow.SetTransferProof(transferProof))
+		require.Equal(t, FlowStateFirstDone, flow.State())
+
+		add, err := flow.AddOrder(targetTmpl, 400)
+		require.NoError(t, err)
+		require.Equal(t, FlowStateSecondPending, flow.State())
+		require.Equal(t, TransactionTypeAddFeeCredit, add.Type)
+		require.Equal(t, recordID, add.UnitID)
+		// timeout is capped to latest addition time
+		require.EqualValues(t, 500, add.Timeout())
+		addAttr := &AddFeeCreditAttributes{}
+		require.NoError(t, add.UnmarshalAttributes(addAttr))
+		require.Equal(t, []byte{0x53}, addAttr.FeeCreditOwnerPredicate)
+		require.NotNil(t, addAttr.FeeCreditTransferProof)
+
+		// failed add, may retry
+		require.ErrorIs(t, flow.SetAddProof(newTestProof(t, add, types.TxStatusFailed)), ErrTransactionFailed)
+		require.Equal(t, FlowStateSecondPending, flow.State())
+		add, err = flow.AddOrder(targetTmpl, 450)
+		require.NoError(t, err)
+
+		addProof := newTestProof(t, add, types.TxStatusSuccessful)
+		require.NoError(t, flow.SetAddProof(addProof))
+		require.Equal(t, FlowStateDone, flow.State())
+		require.Equal(t, addProof, flow.AddProof())
+		require.False(t, flow.IsExpired(1000))
+
+		_, err = flow.TransferOrder(moneyTmpl)
+		require.ErrorIs(t, err, ErrInvalidFlowState)
+	})
+
+	t.Run("failed transfer", func(t *testing.T) {
+		flow, err := NewAddFeeCreditFlow(params())
+		require.NoError(t, err)
+		transfer, err := flow.TransferOrder(moneyTmpl)
+		require.NoError(t, err)
+		require.ErrorIs(t, flow.SetTransferProof(newTestProof(t, transfer, types.TxStatusFailed)), ErrTransactionFailed)
+		require.Equal(t, FlowStateNew, flow.State())
+		require.ErrorIs(t, flow.SetTransferProof(newTestProof(t, transfer, types.TxStatusSuccessful)), ErrInvalidFlowState)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		flow, err := NewAddFeeCreditFlow(params())
+		require.NoError(t, err)
+		transfer, err := flow.TransferOrder(moneyTmpl)
+		require.NoError(t, err)
+		require.NoError(t, flow.SetTransferProof(newTestProof(t, transfer, types.TxStatusSuccessful)))
+
+		require.False(t, flow.IsExpired(500))
+		_, err = flow.AddOrder(targetTmpl, 501)
+		require.ErrorIs(t, err, ErrFeeCreditExpired)
+		require.Equal(t, FlowStateExpired, flow.State())
+		require.True(t, flow.IsExpired(0))
+
+		_, err = flow.AddOrder(targetTmpl, 1)
+		require.ErrorIs(t, err, ErrInvalidFlowState)
+	})
+}
+
+func Test_ReclaimFeeCreditFlow(t *testing.T) {
+	params := ReclaimFeeCreditParams{RecordID: types.UnitID{1, 16}, RecordCounter: 4, Amount: 30, BillID: types.UnitID{2, 1}, BillCounter: 7}
+	tmpl := types.Payload{NetworkID: 5, PartitionID: 2, ClientMetadata: &types.ClientMetadata{Timeout: 100}}
+
+	t.Run("invalid params", func(t *testing.T) {
+		p := params
+		p.RecordID = nil
+		_, err := NewReclaimFeeCreditFlow(p)
+		require.EqualError(t, err, "fee credit record ID is empty")
+
+		p = params
+		p.Amount = 0
+		_, err = NewReclaimFeeCreditFlow(p)
+		require.EqualError(t, err, "amount must be greater than zero")
+
+		p = params
+		p.BillID = nil
+		_, err = NewReclaimFeeCreditFlow(p)
+		require.EqualError(t, err, "bill ID is empty")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		flow, err := NewReclaimFeeCreditFlow(params)
+		require.NoError(t, err)
+
+		_, err = flow.ReclaimOrder(tmpl)
+		require.ErrorIs(t, err, ErrInvalidFlowState)
+
+		closeTx, err := flow.CloseOrder(tmpl)
+		require.NoError(t, err)
+		require.Equal(t, TransactionTypeCloseFeeCredit, closeTx.Type)
+		require.Equal(t, params.RecordID, closeTx.UnitID)
+		attr := &CloseFeeCreditAttributes{}
+		require.NoError(t, closeTx.UnmarshalAttributes(attr))
+		require.Equal(t, &CloseFeeCreditAttributes{Amount: 30, TargetUnitID: params.BillID, TargetUnitCounter: 7, Counter: 4}, attr)
+
+		require.ErrorIs(t, flow.SetCloseProof(newTestProof(t, closeTx, types.TxStatusFailed)), ErrTransactionFailed)
+		require.Equal(t, FlowStateNew, flow.State())
+		closeTx, err = flow.CloseOrder(tmpl)
+		require.NoError(t, err)
+		require.NoError(t, flow.SetCloseProof(newTestProof(t, closeTx, types.TxStatusSuccessful)))
+		require.Equal(t, FlowStateFirstDone, flow.State())
+
+		reclaim, err := flow.ReclaimOrder(tmpl)
+		require.NoError(t, err)
+		require.Equal(t, TransactionTypeReclaimFeeCredit, reclaim.Type)
+		require.Equal(t, params.BillID, reclaim.UnitID)
+		reclaimAttr := &ReclaimFeeCreditAttributes{}
+		require.NoError(t, reclaim.UnmarshalAttributes(reclaimAttr))
+		require.NoError(t, reclaimAttr.IsValid())
+
+		require.ErrorIs(t, flow.SetReclaimProof(newTestProof(t, closeTx, types.TxStatusSuccessful)), ErrUnexpectedTxRecord)
+		require.ErrorIs(t, flow.SetReclaimProof(newTestProof(t, reclaim, types.TxStatusFailed)), ErrTransactionFailed)
+		require.Equal(t, FlowStateSecondPending, flow.State())
+		proof := newTestProof(t, reclaim, types.TxStatusSuccessful)
+		require.NoError(t, flow.SetReclaimProof(proof))
+		require.Equal(t, FlowStateDone, flow.State())
+		require.Equal(t, proof, flow.ReclaimProof())
+	})
+
+	t.Run("invalid proof", func(t *testing.T) {
+		flow, err := NewReclaimFeeCreditFlow(params)
+		require.NoError(t, err)
+		_, err = flow.CloseOrder(tmpl)
+		require.NoError(t, err)
+		require.EqualError(t, flow.SetCloseProof(nil), "invalid proof: transaction record proof is nil")
+	})
+}
+
+func Test_FlowState_String(t *testing.T) {
+	require.Equal(t, "new", FlowStateNew.String())
+	require.Equal(t, "expired", FlowStateExpired.String())
+	require.Equal(t, "FlowState(42)", FlowState(42).String())
+}
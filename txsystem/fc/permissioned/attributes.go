@@ -1,6 +1,10 @@
 package permissioned
 
-import "github.com/alphabill-org/alphabill-go-base/types"
+import (
+	"errors"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
 
 const (
 	TransactionTypeSetFeeCredit    uint16 = 20
@@ -35,3 +39,23 @@ func IsFeeCreditTx(tx *types.TransactionOrder) bool {
 	}
 	return tx.Type >= TransactionTypeSetFeeCredit && tx.Type <= TransactionTypeDeleteFeeCredit
 }
+
+func (a *SetFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.OwnerPredicate) == 0 {
+		return errors.New("owner predicate is empty")
+	}
+	if a.Amount == 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	return nil
+}
+
+func (a *DeleteFeeCreditAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	return nil
+}
@@ -1,7 +1,11 @@
 package money
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
 )
 
 const (
@@ -47,3 +51,77 @@ type (
 		OwnerPredicate []byte
 	}
 )
+
+func (a *TransferAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if a.TargetValue == 0 {
+		return errors.New("target value must be greater than zero")
+	}
+	if len(a.NewOwnerPredicate) == 0 {
+		return errors.New("new owner predicate is empty")
+	}
+	return nil
+}
+
+func (a *TransferDCAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if a.Value == 0 {
+		return errors.New("value must be greater than zero")
+	}
+	if len(a.TargetUnitID) == 0 {
+		return errors.New("target unit ID is empty")
+	}
+	return nil
+}
+
+func (a *SplitAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.TargetUnits) == 0 {
+		return errors.New("target units are empty")
+	}
+	var sum uint64
+	for i, tu := range a.TargetUnits {
+		if err := tu.IsValid(); err != nil {
+			return fmt.Errorf("target unit %d: %w", i, err)
+		}
+		var ok bool
+		if sum, ok = util.SafeAdd(sum, tu.Amount); !ok {
+			return errors.New("sum of the target unit amounts overflows")
+		}
+	}
+	return nil
+}
+
+func (a *SwapDCAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.DustTransferProofs) == 0 {
+		return errors.New("dust transfer proofs are empty")
+	}
+	for i, p := range a.DustTransferProofs {
+		if err := p.IsValid(); err != nil {
+			return fmt.Errorf("dust transfer proof %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (tu *TargetUnit) IsValid() error {
+	if tu == nil {
+		return errors.New("target unit is nil")
+	}
+	if tu.Amount == 0 {
+		return errors.New("amount must be greater than zero")
+	}
+	if len(tu.OwnerPredicate) == 0 {
+		return errors.New("owner predicate is empty")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func testTxRecordProof(t *testing.T) *types.TxRecordProof {
+	txo := &types.TransactionOrder{Version: 1, Payload: types.Payload{Type: TransactionTypeTransDC}}
+	txoBytes, err := txo.MarshalCBOR()
+	require.NoError(t, err)
+	return &types.TxRecordProof{
+		TxRecord: &types.TransactionRecord{
+			Version:          1,
+			TransactionOrder: txoBytes,
+			ServerMetadata:   &types.ServerMetadata{SuccessIndicator: types.TxStatusSuccessful},
+		},
+		TxProof: &types.TxProof{Version: 1},
+	}
+}
+
+func Test_TransferAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*TransferAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&TransferAttributes{NewOwnerPredicate: []byte{1}}).IsValid(), "target value must be greater than zero")
+	require.EqualError(t, (&TransferAttributes{TargetValue: 1}).IsValid(), "new owner predicate is empty")
+	require.NoError(t, (&TransferAttributes{TargetValue: 1, NewOwnerPredicate: []byte{1}}).IsValid())
+}
+
+func Test_TransferDCAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*TransferDCAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&TransferDCAttributes{TargetUnitID: []byte{1}}).IsValid(), "value must be greater than zero")
+	require.EqualError(t, (&TransferDCAttributes{Value: 1}).IsValid(), "target unit ID is empty")
+	require.NoError(t, (&TransferDCAttributes{Value: 1, TargetUnitID: []byte{1}}).IsValid())
+}
+
+func Test_SplitAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*SplitAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&SplitAttributes{}).IsValid(), "target units are empty")
+	require.EqualError(t, (&SplitAttributes{TargetUnits: []*TargetUnit{nil}}).IsValid(), "target unit 0: target unit is nil")
+	require.EqualError(t, (&SplitAttributes{TargetUnits: []*TargetUnit{{Amount: 1, OwnerPredicate: []byte{1}}, {OwnerPredicate: []byte{1}}}}).IsValid(),
+		"target unit 1: amount must be greater than zero")
+	require.EqualError(t, (&SplitAttributes{TargetUnits: []*TargetUnit{{Amount: 1}}}).IsValid(), "target unit 0: owner predicate is empty")
+	require.EqualError(t, (&SplitAttributes{TargetUnits: []*TargetUnit{{Amount: math.MaxUint64, OwnerPredicate: []byte{1}}, {Amount: 1, OwnerPredicate: []byte{1}}}}).IsValid(),
+		"sum of the target unit amounts overflows")
+	require.NoError(t, (&SplitAttributes{TargetUnits: []*TargetUnit{{Amount: 1, OwnerPredicate: []byte{1}}, {Amount: 2, OwnerPredicate: []byte{2}}}}).IsValid())
+}
+
+func Test_SwapDCAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*SwapDCAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&SwapDCAttributes{}).IsValid(), "dust transfer proofs are empty")
+	require.EqualError(t, (&SwapDCAttributes{DustTransferProofs: []*types.TxRecordProof{testTxRecordProof(t), nil}}).IsValid(),
+		"dust transfer proof 1: transaction record proof is nil")
+	require.NoError(t, (&SwapDCAttributes{DustTransferProofs: []*types.TxRecordProof{testTxRecordProof(t)}}).IsValid())
+}
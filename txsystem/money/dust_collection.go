@@ -0,0 +1,112 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/consolidation"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+type (
+	// Bill is a unit of the money partition, ie unit ID and its data.
+	Bill struct {
+		ID   types.UnitID
+		Data *BillData
+	}
+
+	// DustCollectionPlan describes dust collection (joining several bills into
+	// one target bill): dust transfer orders which have to be signed and sent
+	// to the money partition, once these have been executed the proofs of the
+	// transfers are to be used to create swap transaction.
+	DustCollectionPlan struct {
+		TargetUnitID      types.UnitID
+		TargetUnitCounter uint64 // counter of the target bill, swap must happen before target bill changes
+		Value             uint64 // sum of the values of the dust bills
+		// dust transfer orders (without auth proof), ordered by unit ID
+		Transfers []*types.TransactionOrder
+	}
+)
+
+/*
+PlanDustCollection creates plan to join the "bills" into the "target" bill.
+
+The "txTemplate" must have NetworkID, PartitionID and ClientMetadata fields
+assigned, these are copied into all the transaction orders created.
+
+When "maxBills" is greater than zero at most that many bills (with the smallest
+values) are collected.
+*/
+func PlanDustCollection(target Bill, bills []Bill, maxBills int, txTemplate types.Payload) (*DustCollectionPlan, error) {
+	if len(target.ID) == 0 || target.Data == nil {
+		return nil, errors.New("target bill is not assigned")
+	}
+	units := make([]consolidation.Unit, len(bills))
+	for i, b := range bills {
+		if len(b.ID) == 0 || b.Data == nil {
+			return nil, fmt.Errorf("bill %d is not assigned", i)
+		}
+		units[i] = consolidation.Unit{ID: b.ID, Value: b.Data.Value, Counter: b.Data.Counter}
+	}
+	dust, value, err := consolidation.Select(consolidation.Unit{ID: target.ID, Value: target.Data.Value}, units, maxBills)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &DustCollectionPlan{
+		TargetUnitID:      target.ID,
+		TargetUnitCounter: target.Data.Counter,
+		Value:             value,
+		Transfers:         make([]*types.TransactionOrder, len(dust)),
+	}
+	for i, b := range dust {
+		attr := &TransferDCAttributes{
+			Value:             b.Value,
+			TargetUnitID:      target.ID,
+			TargetUnitCounter: target.Data.Counter,
+			Counter:           b.Counter,
+		}
+		if plan.Transfers[i], err = types.NewTransactionOrder(txTemplate, b.ID, TransactionTypeTransDC, attr); err != nil {
+			return nil, fmt.Errorf("creating dust transfer order for bill %s: %w", b.ID, err)
+		}
+	}
+	return plan, nil
+}
+
+/*
+SwapAttributes verifies that "proofs" are proofs of successfully executed dust
+transfers of the plan (see consolidation.Plan.MatchProofs) and returns swap
+attributes.
+*/
+func (p *DustCollectionPlan) SwapAttributes(proofs []*types.TxRecordProof) (*SwapDCAttributes, error) {
+	plan := consolidation.Plan{
+		TargetUnitID:      p.TargetUnitID,
+		TargetUnitCounter: p.TargetUnitCounter,
+		Value:             p.Value,
+		TxType:            TransactionTypeTransDC,
+		Orders:            p.Transfers,
+	}
+	sorted, err := plan.MatchProofs(proofs, func(txo *types.TransactionOrder) (*consolidation.Transfer, error) {
+		attr := &TransferDCAttributes{}
+		if err := txo.UnmarshalAttributes(attr); err != nil {
+			return nil, fmt.Errorf("decoding dust transfer attributes: %w", err)
+		}
+		return &consolidation.Transfer{Value: attr.Value, Counter: attr.Counter, TargetUnitID: attr.TargetUnitID, TargetUnitCounter: attr.TargetUnitCounter}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SwapDCAttributes{DustTransferProofs: sorted}, nil
+}
+
+/*
+SwapOrder verifies the proofs (see SwapAttributes) and returns swap transaction
+order (without auth proof) for the target bill of the plan.
+*/
+func (p *DustCollectionPlan) SwapOrder(proofs []*types.TxRecordProof, txTemplate types.Payload) (*types.TransactionOrder, error) {
+	attr, err := p.SwapAttributes(proofs)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewTransactionOrder(txTemplate, p.TargetUnitID, TransactionTypeSwapDC, attr)
+}
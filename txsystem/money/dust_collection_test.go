@@ -0,0 +1,107 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_PlanDustCollection(t *testing.T) {
+	txTemplate := types.Payload{
+		NetworkID:      3,
+		PartitionID:    DefaultPartitionID,
+		ClientMetadata: &types.ClientMetadata{Timeout: 10, MaxTransactionFee: 2, FeeCreditRecordID: []byte{0xf}},
+	}
+	newBill := func(id byte, value, counter uint64) Bill {
+		return Bill{ID: types.UnitID{0, id, BillUnitType}, Data: &BillData{Value: value, Counter: counter}}
+	}
+	target := newBill(1, 100, 7)
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := PlanDustCollection(Bill{}, []Bill{newBill(2, 1, 0)}, 0, txTemplate)
+		require.EqualError(t, err, "target bill is not assigned")
+
+		_, err = PlanDustCollection(target, []Bill{newBill(2, 1, 0), {}}, 0, txTemplate)
+		require.EqualError(t, err, "bill 1 is not assigned")
+
+		_, err = PlanDustCollection(target, []Bill{target}, 0, txTemplate)
+		require.EqualError(t, err, "no units to consolidate")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		bills := []Bill{newBill(5, 50, 1), target, newBill(3, 1, 2), newBill(4, 1, 3), newBill(2, 10, 4)}
+		plan, err := PlanDustCollection(target, bills, 3, txTemplate)
+		require.NoError(t, err)
+		require.Equal(t, target.ID, plan.TargetUnitID)
+		require.EqualValues(t, 7, plan.TargetUnitCounter)
+		require.EqualValues(t, 12, plan.Value)
+		require.Len(t, plan.Transfers, 3)
+		// the smallest bills, ordered by ID
+		for i, b := range []Bill{bills[4], bills[2], bills[3]} {
+			txo := plan.Transfers[i]
+			require.Equal(t, b.ID, txo.UnitID)
+			require.Equal(t, TransactionTypeTransDC, txo.Type)
+			require.Equal(t, txTemplate.NetworkID, txo.NetworkID)
+			require.Equal(t, txTemplate.ClientMetadata, txo.ClientMetadata)
+			require.NotSame(t, txTemplate.ClientMetadata, txo.ClientMetadata)
+			attr := &TransferDCAttributes{}
+			require.NoError(t, txo.UnmarshalAttributes(attr))
+			require.Equal(t, &TransferDCAttributes{Value: b.Data.Value, TargetUnitID: target.ID, TargetUnitCounter: 7, Counter: b.Data.Counter}, attr)
+		}
+	})
+}
+
+func Test_DustCollectionPlan_SwapAttributes(t *testing.T) {
+	txTemplate := types.Payload{NetworkID: 3, PartitionID: DefaultPartitionID}
+	target := Bill{ID: types.UnitID{0, 1, BillUnitType}, Data: &BillData{Value: 100, Counter: 7}}
+	bills := []Bill{
+		{ID: types.UnitID{0, 2, BillUnitType}, Data: &BillData{Value: 2, Counter: 1}},
+		{ID: types.UnitID{0, 3, BillUnitType}, Data: &BillData{Value: 3, Counter: 1}},
+	}
+	plan, err := PlanDustCollection(target, bills, 0, txTemplate)
+	require.NoError(t, err)
+
+	newProof := func(t *testing.T, txo *types.TransactionOrder, status types.TxStatus) *types.TxRecordProof {
+		txoBytes, err := txo.MarshalCBOR()
+		require.NoError(t, err)
+		return &types.TxRecordProof{
+			TxRecord: &types.TransactionRecord{
+				Version:          1,
+				TransactionOrder: txoBytes,
+				ServerMetadata:   &types.ServerMetadata{SuccessIndicator: status},
+			},
+			TxProof: &types.TxProof{Version: 1},
+		}
+	}
+	proofs := []*types.TxRecordProof{
+		newProof(t, plan.Transfers[1], types.TxStatusSuccessful),
+		newProof(t, plan.Transfers[0], types.TxStatusSuccessful),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		attr, err := plan.SwapAttributes(proofs)
+		require.NoError(t, err)
+		require.Equal(t, []*types.TxRecordProof{proofs[1], proofs[0]}, attr.DustTransferProofs)
+
+		txo, err := plan.SwapOrder(proofs, txTemplate)
+		require.NoError(t, err)
+		require.Equal(t, target.ID, txo.UnitID)
+		require.Equal(t, TransactionTypeSwapDC, txo.Type)
+		swapAttr := &SwapDCAttributes{}
+		require.NoError(t, txo.UnmarshalAttributes(swapAttr))
+		require.Len(t, swapAttr.DustTransferProofs, 2)
+	})
+
+	t.Run("invalid dust transfer", func(t *testing.T) {
+		txo := *plan.Transfers[0]
+		require.NoError(t, txo.SetAttributes(&TransferDCAttributes{Value: 2, TargetUnitID: types.UnitID{0, 9, BillUnitType}, TargetUnitCounter: 7, Counter: 1}))
+		_, err := plan.SwapAttributes([]*types.TxRecordProof{proofs[0], newProof(t, &txo, types.TxStatusSuccessful)})
+		require.EqualError(t, err, "proof 1: target unit 000901, expected 000101")
+
+		txo.Type = TransactionTypeTransfer
+		_, err = plan.SwapAttributes([]*types.TxRecordProof{proofs[0], newProof(t, &txo, types.TxStatusSuccessful)})
+		require.EqualError(t, err, "proof 1: expected transaction type 3, got 1")
+	})
+}
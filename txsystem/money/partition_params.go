@@ -0,0 +1,84 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+/*
+Keys of the money partition parameters in the PartitionDescriptionRecord.PartitionParams,
+ie in the "partitionParams" object of the shard configuration JSON. The keys are
+defined here, tools generating the shard configuration must use these constants.
+*/
+const (
+	ParamInitialBillValue          = "initialBillValue"
+	ParamInitialBillOwnerPredicate = "initialBillOwnerPredicate"
+	ParamDCMoneySupplyValue        = "dcMoneySupplyValue"
+)
+
+// PartitionParams are the money partition specific parameters of the shard configuration.
+type PartitionParams struct {
+	InitialBillValue          uint64    // value of the bill created in the genesis
+	InitialBillOwnerPredicate hex.Bytes // owner predicate of the initial bill
+	DCMoneySupplyValue        uint64    // value of the dust collector money supply bill created in the genesis
+}
+
+/*
+ParsePartitionParams decodes and validates the money partition parameters of
+the shard configuration "pdr" (see PartitionDescriptionRecord.PartitionParams).
+*/
+func ParsePartitionParams(pdr *types.PartitionDescriptionRecord) (*PartitionParams, error) {
+	if pdr == nil {
+		return nil, types.ErrSystemDescriptionIsNil
+	}
+	if pdr.PartitionTypeID != PartitionTypeID {
+		return nil, fmt.Errorf("expected money partition type %d, got %d", PartitionTypeID, pdr.PartitionTypeID)
+	}
+	p := &PartitionParams{}
+	d := types.NewParamsDecoder(pdr.PartitionParams)
+	d.Uint64(ParamInitialBillValue, &p.InitialBillValue)
+	d.Bytes(ParamInitialBillOwnerPredicate, &p.InitialBillOwnerPredicate)
+	d.Uint64(ParamDCMoneySupplyValue, &p.DCMoneySupplyValue)
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	if err := p.IsValid(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+/*
+Encode returns the parameters as PartitionDescriptionRecord.PartitionParams map,
+parameters with zero value are omitted.
+*/
+func (p *PartitionParams) Encode() map[string]string {
+	m := map[string]string{}
+	if p.InitialBillValue != 0 {
+		m[ParamInitialBillValue] = strconv.FormatUint(p.InitialBillValue, 10)
+	}
+	if len(p.InitialBillOwnerPredicate) != 0 {
+		m[ParamInitialBillOwnerPredicate] = string(hex.Encode(p.InitialBillOwnerPredicate))
+	}
+	if p.DCMoneySupplyValue != 0 {
+		m[ParamDCMoneySupplyValue] = strconv.FormatUint(p.DCMoneySupplyValue, 10)
+	}
+	return m
+}
+
+func (p *PartitionParams) IsValid() error {
+	if p == nil {
+		return errors.New("partition params are nil")
+	}
+	if p.InitialBillValue != 0 && len(p.InitialBillOwnerPredicate) == 0 {
+		return errors.New("initial bill owner predicate is required when initial bill value is set")
+	}
+	if p.InitialBillValue == 0 && len(p.InitialBillOwnerPredicate) != 0 {
+		return errors.New("initial bill value is required when initial bill owner predicate is set")
+	}
+	return nil
+}
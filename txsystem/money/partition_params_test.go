@@ -0,0 +1,81 @@
+package money
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_PartitionParams(t *testing.T) {
+	parse := func(params map[string]string) (*PartitionParams, error) {
+		return ParsePartitionParams(&types.PartitionDescriptionRecord{PartitionTypeID: PartitionTypeID, PartitionParams: params})
+	}
+	t.Run("encode and parse", func(t *testing.T) {
+		p := &PartitionParams{InitialBillValue: 100, InitialBillOwnerPredicate: []byte{1, 2}, DCMoneySupplyValue: 5}
+		m := p.Encode()
+		require.Equal(t, map[string]string{"initialBillValue": "100", "initialBillOwnerPredicate": "0x0102", "dcMoneySupplyValue": "5"}, m)
+		p2, err := parse(m)
+		require.NoError(t, err)
+		require.Equal(t, p, p2)
+
+		// all the params are optional
+		p2, err = parse(nil)
+		require.NoError(t, err)
+		require.Equal(t, &PartitionParams{}, p2)
+		require.Empty(t, p2.Encode())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		p, err := parse(map[string]string{"initialBillValue": "100"})
+		require.EqualError(t, err, "initial bill owner predicate is required when initial bill value is set")
+		require.Nil(t, p)
+
+		p, err = parse(map[string]string{"initialBillOwnerPredicate": "0x01"})
+		require.EqualError(t, err, "initial bill value is required when initial bill owner predicate is set")
+		require.Nil(t, p)
+
+		p, err = parse(map[string]string{"initialBillValue": "1e3"})
+		require.EqualError(t, err, `invalid value of the partition parameter "initialBillValue": strconv.ParseUint: parsing "1e3": invalid syntax`)
+		require.Nil(t, p)
+
+		p, err = parse(map[string]string{"dcMoneySupply": "1"})
+		require.ErrorContains(t, err, `unknown partition parameter "dcMoneySupply"`)
+		require.Nil(t, p)
+
+		var nilParams *PartitionParams
+		require.EqualError(t, nilParams.IsValid(), "partition params are nil")
+	})
+
+	t.Run("PDR", func(t *testing.T) {
+		pdr := &types.PartitionDescriptionRecord{
+			Version:         1,
+			NetworkID:       5,
+			PartitionID:     DefaultPartitionID,
+			PartitionTypeID: PartitionTypeID,
+			TypeIDLen:       8,
+			UnitIDLen:       256,
+			T2Timeout:       2500 * time.Millisecond,
+			PartitionParams: map[string]string{"initialBillValue": "100"},
+		}
+		// IsValid doesn't check the partition params, IsValidWith does
+		require.NoError(t, pdr.IsValid())
+		require.EqualError(t, pdr.IsValidWith(types.ParamsValidator(ParsePartitionParams)), "invalid partition parameters: initial bill owner predicate is required when initial bill value is set")
+		_, err := ParsePartitionParams(pdr)
+		require.EqualError(t, err, "initial bill owner predicate is required when initial bill value is set")
+
+		pdr.PartitionParams["initialBillOwnerPredicate"] = "0x01"
+		p, err := ParsePartitionParams(pdr)
+		require.NoError(t, err)
+		require.EqualValues(t, 100, p.InitialBillValue)
+
+		pdr.PartitionTypeID = PartitionTypeID + 1
+		_, err = ParsePartitionParams(pdr)
+		require.EqualError(t, err, "expected money partition type 1, got 2")
+
+		_, err = ParsePartitionParams(nil)
+		require.ErrorIs(t, err, types.ErrSystemDescriptionIsNil)
+	})
+}
@@ -0,0 +1,57 @@
+package money
+
+import (
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/coinselect"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+/*
+PlanPayment selects bills to pay "params.Amount" to the "receiver" (owner predicate)
+and returns the transaction orders (without auth proofs) to execute the payment:
+transfer orders for the bills which are spent whole and split order for the bill
+which leaves change to the owner.
+
+The "txTemplate" must have NetworkID, PartitionID and ClientMetadata fields
+assigned, these are copied into all the transaction orders created.
+*/
+func PlanPayment(bills []Bill, receiver []byte, params coinselect.Params, txTemplate types.Payload) ([]*types.TransactionOrder, error) {
+	coins := make([]coinselect.Coin, len(bills))
+	byID := make(map[string]Bill, len(bills))
+	for i, b := range bills {
+		if len(b.ID) == 0 || b.Data == nil {
+			return nil, fmt.Errorf("bill %d is not assigned", i)
+		}
+		coins[i] = coinselect.Coin{ID: b.ID, Value: b.Data.Value}
+		byID[string(b.ID)] = b
+	}
+	sel, err := coinselect.Select(coins, params, txTemplate.ClientMetadata.GetMaxFee())
+	if err != nil {
+		return nil, fmt.Errorf("selecting bills: %w", err)
+	}
+
+	txs := make([]*types.TransactionOrder, 0, sel.TxCount())
+	for _, c := range sel.Transfer {
+		b := byID[string(c.ID)]
+		attr := &TransferAttributes{TargetValue: b.Data.Value, NewOwnerPredicate: receiver, Counter: b.Data.Counter}
+		txo, err := types.NewTransactionOrder(txTemplate, b.ID, TransactionTypeTransfer, attr)
+		if err != nil {
+			return nil, fmt.Errorf("creating transfer order for bill %s: %w", b.ID, err)
+		}
+		txs = append(txs, txo)
+	}
+	if sel.Split != nil {
+		b := byID[string(sel.Split.ID)]
+		attr := &SplitAttributes{
+			TargetUnits: []*TargetUnit{{Amount: b.Data.Value - sel.Change, OwnerPredicate: receiver}},
+			Counter:     b.Data.Counter,
+		}
+		txo, err := types.NewTransactionOrder(txTemplate, b.ID, TransactionTypeSplit, attr)
+		if err != nil {
+			return nil, fmt.Errorf("creating split order for bill %s: %w", b.ID, err)
+		}
+		txs = append(txs, txo)
+	}
+	return txs, nil
+}
@@ -0,0 +1,65 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/coinselect"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_PlanPayment(t *testing.T) {
+	txTemplate := types.Payload{
+		NetworkID:      3,
+		PartitionID:    DefaultPartitionID,
+		ClientMetadata: &types.ClientMetadata{Timeout: 10, MaxTransactionFee: 2, FeeCreditRecordID: []byte{0xf}},
+	}
+	newBill := func(id byte, value, counter uint64) Bill {
+		return Bill{ID: types.UnitID{0, id, BillUnitType}, Data: &BillData{Value: value, Counter: counter}}
+	}
+	bills := []Bill{newBill(1, 10, 1), newBill(2, 50, 2), newBill(3, 5, 3)}
+	receiver := []byte{0x53, 0x51}
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := PlanPayment([]Bill{bills[0], {}}, receiver, coinselect.Params{Amount: 1}, txTemplate)
+		require.EqualError(t, err, "bill 1 is not assigned")
+
+		_, err = PlanPayment(bills, receiver, coinselect.Params{Amount: 100}, txTemplate)
+		require.ErrorIs(t, err, coinselect.ErrInsufficientFunds)
+
+		// fee budget allows single transaction only
+		_, err = PlanPayment(bills, receiver, coinselect.Params{Amount: 55, FeeBudget: 3}, txTemplate)
+		require.ErrorIs(t, err, coinselect.ErrTooManyCoins)
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		txs, err := PlanPayment(bills, receiver, coinselect.Params{Amount: 10}, txTemplate)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		require.Equal(t, bills[0].ID, txs[0].UnitID)
+		require.Equal(t, TransactionTypeTransfer, txs[0].Type)
+		require.Equal(t, txTemplate.ClientMetadata, txs[0].ClientMetadata)
+		attr := &TransferAttributes{}
+		require.NoError(t, txs[0].UnmarshalAttributes(attr))
+		require.Equal(t, &TransferAttributes{TargetValue: 10, NewOwnerPredicate: receiver, Counter: 1}, attr)
+	})
+
+	t.Run("transfer and split", func(t *testing.T) {
+		txs, err := PlanPayment(bills, receiver, coinselect.Params{Amount: 58}, txTemplate)
+		require.NoError(t, err)
+		require.Len(t, txs, 2)
+
+		require.Equal(t, bills[1].ID, txs[0].UnitID)
+		require.Equal(t, TransactionTypeTransfer, txs[0].Type)
+		transfer := &TransferAttributes{}
+		require.NoError(t, txs[0].UnmarshalAttributes(transfer))
+		require.Equal(t, &TransferAttributes{TargetValue: 50, NewOwnerPredicate: receiver, Counter: 2}, transfer)
+
+		require.Equal(t, bills[0].ID, txs[1].UnitID)
+		require.Equal(t, TransactionTypeSplit, txs[1].Type)
+		split := &SplitAttributes{}
+		require.NoError(t, txs[1].UnmarshalAttributes(split))
+		require.Equal(t, &SplitAttributes{TargetUnits: []*TargetUnit{{Amount: 8, OwnerPredicate: receiver}}, Counter: 1}, split)
+	})
+}
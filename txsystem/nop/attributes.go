@@ -7,6 +7,8 @@
 // changes security-related fields e.g. the counter.
 package nop
 
+import "errors"
+
 const (
 	TransactionTypeNOP uint16 = 22
 )
@@ -20,3 +22,10 @@ type (
 		Counter *uint64 // the target unit counter
 	}
 )
+
+func (a *Attributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	return nil
+}
@@ -1,6 +1,14 @@
 package orchestration
 
-import "github.com/alphabill-org/alphabill-go-base/types"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
+)
 
 const (
 	PartitionTypeID    types.PartitionTypeID = 4
@@ -34,3 +42,43 @@ type (
 		Stake       uint64   // total amount of staked Alpha by the validator
 	}
 )
+
+func (a *AddVarAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if err := a.Var.IsValid(); err != nil {
+		return fmt.Errorf("validator assignment record: %w", err)
+	}
+	return nil
+}
+
+func (v *ValidatorAssignmentRecord) IsValid() error {
+	return v.ValidatorAssignment.IsValid()
+}
+
+func (v *ValidatorAssignment) IsValid() error {
+	if len(v.Validators) == 0 {
+		return errors.New("validators are empty")
+	}
+	var totalStake uint64
+	for i, vi := range v.Validators {
+		if len(vi.ValidatorID) == 0 {
+			return fmt.Errorf("validator %d: validator ID is empty", i)
+		}
+		if slices.ContainsFunc(v.Validators[:i], func(x ValidatorInfo) bool { return bytes.Equal(x.ValidatorID, vi.ValidatorID) }) {
+			return fmt.Errorf("validator %d: duplicate validator ID %X", i, vi.ValidatorID)
+		}
+		var ok bool
+		if totalStake, ok = util.SafeAdd(totalStake, vi.Stake); !ok {
+			return errors.New("sum of the validator stakes overflows")
+		}
+	}
+	if v.QuorumSize == 0 {
+		return errors.New("quorum size must be greater than zero")
+	}
+	if v.QuorumSize > totalStake {
+		return fmt.Errorf("quorum size %d exceeds the total stake %d", v.QuorumSize, totalStake)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package orchestration
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddVarAttributes_IsValid(t *testing.T) {
+	valid := func() *AddVarAttributes {
+		return &AddVarAttributes{Var: ValidatorAssignmentRecord{
+			EpochNumber: 1,
+			ValidatorAssignment: ValidatorAssignment{
+				Validators: []ValidatorInfo{{ValidatorID: []byte{1}, Stake: 2}, {ValidatorID: []byte{2}, Stake: 3}},
+				QuorumSize: 4,
+			},
+		}}
+	}
+	require.NoError(t, valid().IsValid())
+	require.EqualError(t, (*AddVarAttributes)(nil).IsValid(), "attributes are nil")
+
+	var testCases = []struct {
+		modify func(va *ValidatorAssignment)
+		errMsg string
+	}{
+		{func(va *ValidatorAssignment) { va.Validators = nil }, "validators are empty"},
+		{func(va *ValidatorAssignment) { va.Validators[1].ValidatorID = nil }, "validator 1: validator ID is empty"},
+		{func(va *ValidatorAssignment) { va.Validators[1].ValidatorID = []byte{1} }, "validator 1: duplicate validator ID 01"},
+		{func(va *ValidatorAssignment) { va.Validators[1].Stake = math.MaxUint64 }, "sum of the validator stakes overflows"},
+		{func(va *ValidatorAssignment) { va.QuorumSize = 0 }, "quorum size must be greater than zero"},
+		{func(va *ValidatorAssignment) { va.QuorumSize = 6 }, "quorum size 6 exceeds the total stake 5"},
+	}
+	for _, tc := range testCases {
+		attr := valid()
+		tc.modify(&attr.Var.ValidatorAssignment)
+		require.EqualError(t, attr.IsValid(), "validator assignment record: "+tc.errMsg)
+	}
+}
@@ -0,0 +1,66 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+/*
+Keys of the orchestration partition parameters in the PartitionDescriptionRecord.PartitionParams,
+ie in the "partitionParams" object of the shard configuration JSON. The keys are
+defined here, tools generating the shard configuration must use these constants.
+*/
+const (
+	ParamOwnerPredicate = "ownerPredicate"
+)
+
+// PartitionParams are the orchestration partition specific parameters of the shard configuration.
+type PartitionParams struct {
+	// predicate which must be satisfied to add validator assignment records,
+	// optional, nil when not set in the shard configuration
+	OwnerPredicate hex.Bytes
+}
+
+/*
+ParsePartitionParams decodes and validates the orchestration partition parameters of
+the shard configuration "pdr" (see PartitionDescriptionRecord.PartitionParams).
+*/
+func ParsePartitionParams(pdr *types.PartitionDescriptionRecord) (*PartitionParams, error) {
+	if pdr == nil {
+		return nil, types.ErrSystemDescriptionIsNil
+	}
+	if pdr.PartitionTypeID != PartitionTypeID {
+		return nil, fmt.Errorf("expected orchestration partition type %d, got %d", PartitionTypeID, pdr.PartitionTypeID)
+	}
+	p := &PartitionParams{}
+	d := types.NewParamsDecoder(pdr.PartitionParams)
+	d.Bytes(ParamOwnerPredicate, &p.OwnerPredicate)
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	if err := p.IsValid(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+/*
+Encode returns the parameters as PartitionDescriptionRecord.PartitionParams map.
+*/
+func (p *PartitionParams) Encode() map[string]string {
+	m := map[string]string{}
+	if len(p.OwnerPredicate) != 0 {
+		m[ParamOwnerPredicate] = string(hex.Encode(p.OwnerPredicate))
+	}
+	return m
+}
+
+func (p *PartitionParams) IsValid() error {
+	if p == nil {
+		return errors.New("partition params are nil")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package orchestration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_PartitionParams(t *testing.T) {
+	parse := func(params map[string]string) (*PartitionParams, error) {
+		return ParsePartitionParams(&types.PartitionDescriptionRecord{PartitionTypeID: PartitionTypeID, PartitionParams: params})
+	}
+	p := &PartitionParams{OwnerPredicate: []byte{1, 2}}
+	m := p.Encode()
+	require.Equal(t, map[string]string{"ownerPredicate": "0x0102"}, m)
+	p2, err := parse(m)
+	require.NoError(t, err)
+	require.Equal(t, p, p2)
+
+	// owner predicate is optional
+	p2, err = parse(nil)
+	require.NoError(t, err)
+	require.Empty(t, p2.OwnerPredicate)
+	require.Empty(t, p2.Encode())
+
+	p2, err = parse(map[string]string{"ownerPredicate": "0x01", "owner": "0x01"})
+	require.EqualError(t, err, `unknown partition parameter "owner" (known parameters are ["ownerPredicate"])`)
+	require.Nil(t, p2)
+
+	var nilParams *PartitionParams
+	require.EqualError(t, nilParams.IsValid(), "partition params are nil")
+
+	pdr := &types.PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       5,
+		PartitionID:     DefaultPartitionID,
+		PartitionTypeID: PartitionTypeID,
+		TypeIDLen:       8,
+		UnitIDLen:       256,
+		T2Timeout:       2500 * time.Millisecond,
+	}
+	// PDR without partition params is valid
+	require.NoError(t, pdr.IsValid())
+	require.NoError(t, pdr.IsValidWith(types.ParamsValidator(ParsePartitionParams)))
+	p2, err = ParsePartitionParams(pdr)
+	require.NoError(t, err)
+	require.Empty(t, p2.OwnerPredicate)
+
+	// IsValid doesn't check the partition params, IsValidWith does
+	pdr.PartitionParams = map[string]string{ParamOwnerPredicate: "nope"}
+	require.NoError(t, pdr.IsValid())
+	require.ErrorContains(t, pdr.IsValidWith(types.ParamsValidator(ParsePartitionParams)), "invalid partition parameters: ")
+
+	pdr.PartitionParams = m
+	p2, err = ParsePartitionParams(pdr)
+	require.NoError(t, err)
+	require.Equal(t, p, p2)
+
+	pdr.PartitionTypeID = PartitionTypeID + 1
+	_, err = ParsePartitionParams(pdr)
+	require.EqualError(t, err, "expected orchestration partition type 4, got 5")
+
+	_, err = ParsePartitionParams(nil)
+	require.ErrorIs(t, err, types.ErrSystemDescriptionIsNil)
+}
@@ -0,0 +1,245 @@
+/*
+Package preflight implements stateless validation of transaction orders, ie the
+checks which can be done without the state of the partition. Wallets and gateways
+use it to reject malformed orders before sending them to the validators.
+
+Passing the pre-flight validation doesn't mean that the transaction will be
+executed successfully, the predicates, counters, balances etc are checked by
+the partition.
+*/
+package preflight
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/fc"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/fc/permissioned"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/nop"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/orchestration"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/tokens"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+var (
+	ErrUnknownPartitionType = errors.New("unknown partition type")
+	ErrUnknownTxType        = errors.New("unknown transaction type")
+)
+
+type (
+	// Attributes is implemented by all the transaction attribute structs.
+	Attributes interface {
+		IsValid() error
+	}
+
+	// pdrAttributes is implemented by the attribute structs whose validation
+	// depends on the partition description (ie the unit ID lengths).
+	pdrAttributes interface {
+		IsValidFor(pdr *types.PartitionDescriptionRecord) error
+	}
+
+	txDef struct {
+		unitType uint32 // type of the target unit, zero when any type is accepted
+		attr     func() Attributes
+		// unit IDs referenced by the attributes, optional
+		refs func(attr Attributes) []unitRef
+	}
+
+	unitRef struct {
+		name     string
+		id       types.UnitID
+		unitType uint32
+	}
+)
+
+// feeCreditRecordUnitType is the same in all the partitions which use fee credit.
+const feeCreditRecordUnitType = money.FeeCreditRecordUnitType
+
+var partitions = map[types.PartitionTypeID]map[uint16]txDef{
+	money.PartitionTypeID: {
+		money.TransactionTypeTransfer: {unitType: money.BillUnitType, attr: func() Attributes { return &money.TransferAttributes{} }},
+		money.TransactionTypeSplit:    {unitType: money.BillUnitType, attr: func() Attributes { return &money.SplitAttributes{} }},
+		money.TransactionTypeTransDC: {
+			unitType: money.BillUnitType,
+			attr:     func() Attributes { return &money.TransferDCAttributes{} },
+			refs: func(a Attributes) []unitRef {
+				return []unitRef{{name: "target unit", id: a.(*money.TransferDCAttributes).TargetUnitID, unitType: money.BillUnitType}}
+			},
+		},
+		money.TransactionTypeSwapDC:         {unitType: money.BillUnitType, attr: func() Attributes { return &money.SwapDCAttributes{} }},
+		fc.TransactionTypeTransferFeeCredit: {unitType: money.BillUnitType, attr: func() Attributes { return &fc.TransferFeeCreditAttributes{} }},
+		fc.TransactionTypeReclaimFeeCredit:  {unitType: money.BillUnitType, attr: func() Attributes { return &fc.ReclaimFeeCreditAttributes{} }},
+		fc.TransactionTypeAddFeeCredit:      {unitType: money.FeeCreditRecordUnitType, attr: func() Attributes { return &fc.AddFeeCreditAttributes{} }},
+		fc.TransactionTypeCloseFeeCredit:    {unitType: money.FeeCreditRecordUnitType, attr: func() Attributes { return &fc.CloseFeeCreditAttributes{} }},
+		nop.TransactionTypeNOP:              {attr: func() Attributes { return &nop.Attributes{} }},
+	},
+	tokens.PartitionTypeID: {
+		tokens.TransactionTypeDefineFT: {
+			unitType: tokens.FungibleTokenTypeUnitType,
+			attr:     func() Attributes { return &tokens.DefineFungibleTokenAttributes{} },
+		},
+		tokens.TransactionTypeDefineNFT: {
+			unitType: tokens.NonFungibleTokenTypeUnitType,
+			attr:     func() Attributes { return &tokens.DefineNonFungibleTokenAttributes{} },
+		},
+		tokens.TransactionTypeMintFT: {
+			unitType: tokens.FungibleTokenUnitType,
+			attr:     func() Attributes { return &tokens.MintFungibleTokenAttributes{} },
+			refs: func(a Attributes) []unitRef {
+				return []unitRef{{name: "token type", id: a.(*tokens.MintFungibleTokenAttributes).TypeID, unitType: tokens.FungibleTokenTypeUnitType}}
+			},
+		},
+		tokens.TransactionTypeMintNFT: {
+			unitType: tokens.NonFungibleTokenUnitType,
+			attr:     func() Attributes { return &tokens.MintNonFungibleTokenAttributes{} },
+			refs: func(a Attributes) []unitRef {
+				return []unitRef{{name: "token type", id: a.(*tokens.MintNonFungibleTokenAttributes).TypeID, unitType: tokens.NonFungibleTokenTypeUnitType}}
+			},
+		},
+		tokens.TransactionTypeTransferFT: {
+			unitType: tokens.FungibleTokenUnitType,
+			attr:     func() Attributes { return &tokens.TransferFungibleTokenAttributes{} },
+			refs: func(a Attributes) []unitRef {
+				return []unitRef{{name: "token type", id: a.(*tokens.TransferFungibleTokenAttributes).TypeID, unitType: tokens.FungibleTokenTypeUnitType}}
+			},
+		},
+		tokens.TransactionTypeTransferNFT: {
+			unitType: tokens.NonFungibleTokenUnitType,
+			attr:     func() Attributes { return &tokens.TransferNonFungibleTokenAttributes{} },
+			refs: func(a Attributes) []unitRef {
+				return []unitRef{{name: "token type", id: a.(*tokens.TransferNonFungibleTokenAttributes).TypeID, unitType: tokens.NonFungibleTokenTypeUnitType}}
+			},
+		},
+		tokens.TransactionTypeSplitFT: {
+			unitType: tokens.FungibleTokenUnitType,
+			attr:     func() Attributes { return &tokens.SplitFungibleTokenAttributes{} },
+			refs: func(a Attributes) []unitRef {
+				return []unitRef{{name: "token type", id: a.(*tokens.SplitFungibleTokenAttributes).TypeID, unitType: tokens.FungibleTokenTypeUnitType}}
+			},
+		},
+		tokens.TransactionTypeBurnFT: {
+			unitType: tokens.FungibleTokenUnitType,
+			attr:     func() Attributes { return &tokens.BurnFungibleTokenAttributes{} },
+			refs: func(a Attributes) []unitRef {
+				attr := a.(*tokens.BurnFungibleTokenAttributes)
+				return []unitRef{
+					{name: "token type", id: attr.TypeID, unitType: tokens.FungibleTokenTypeUnitType},
+					{name: "target token", id: attr.TargetTokenID, unitType: tokens.FungibleTokenUnitType},
+				}
+			},
+		},
+		tokens.TransactionTypeJoinFT:             {unitType: tokens.FungibleTokenUnitType, attr: func() Attributes { return &tokens.JoinFungibleTokenAttributes{} }},
+		tokens.TransactionTypeUpdateNFT:          {unitType: tokens.NonFungibleTokenUnitType, attr: func() Attributes { return &tokens.UpdateNonFungibleTokenAttributes{} }},
+		fc.TransactionTypeAddFeeCredit:           {unitType: tokens.FeeCreditRecordUnitType, attr: func() Attributes { return &fc.AddFeeCreditAttributes{} }},
+		fc.TransactionTypeCloseFeeCredit:         {unitType: tokens.FeeCreditRecordUnitType, attr: func() Attributes { return &fc.CloseFeeCreditAttributes{} }},
+		permissioned.TransactionTypeSetFeeCredit: {unitType: tokens.FeeCreditRecordUnitType, attr: func() Attributes { return &permissioned.SetFeeCreditAttributes{} }},
+		permissioned.TransactionTypeDeleteFeeCredit: {
+			unitType: tokens.FeeCreditRecordUnitType,
+			attr:     func() Attributes { return &permissioned.DeleteFeeCreditAttributes{} },
+		},
+		nop.TransactionTypeNOP: {attr: func() Attributes { return &nop.Attributes{} }},
+	},
+	orchestration.PartitionTypeID: {
+		orchestration.TransactionTypeAddVAR: {unitType: orchestration.VarUnitType, attr: func() Attributes { return &orchestration.AddVarAttributes{} }},
+	},
+}
+
+/*
+Validate performs the stateless checks of the transaction order "txo" which is to be
+sent to the shard described by "pdr":
+  - network and partition identifiers;
+  - the transaction type is supported by the partition type;
+  - the unit ID (and unit IDs referenced by the attributes) are of correct length
+    and type, the target unit belongs into the shard;
+  - client metadata is present, fee credit record ID is valid;
+  - attributes can be decoded and are valid (see Attributes.IsValid, attributes
+    which refer to other units are validated against "pdr" when they implement
+    IsValidFor).
+*/
+func Validate(txo *types.TransactionOrder, pdr *types.PartitionDescriptionRecord) error {
+	if txo == nil {
+		return types.ErrTransactionOrderIsNil
+	}
+	if txo.Version != 1 {
+		return types.ErrInvalidVersion(txo)
+	}
+	if pdr == nil {
+		return types.ErrSystemDescriptionIsNil
+	}
+	if txo.NetworkID != pdr.NetworkID {
+		return fmt.Errorf("invalid network %d, expected %d", txo.NetworkID, pdr.NetworkID)
+	}
+	if txo.PartitionID != pdr.PartitionID {
+		return fmt.Errorf("invalid partition %s, expected %s", txo.PartitionID, pdr.PartitionID)
+	}
+	txTypes, ok := partitions[pdr.PartitionTypeID]
+	if !ok {
+		return fmt.Errorf("%w %d", ErrUnknownPartitionType, pdr.PartitionTypeID)
+	}
+	def, ok := txTypes[txo.Type]
+	if !ok {
+		return fmt.Errorf("%w %d for partition type %d", ErrUnknownTxType, txo.Type, pdr.PartitionTypeID)
+	}
+
+	if err := pdr.UnitIDValidator(pdr.ShardID)(txo.UnitID); err != nil {
+		return fmt.Errorf("invalid unit ID: %w", err)
+	}
+	if err := checkUnitType(pdr, txo.UnitID, def.unitType); err != nil {
+		return fmt.Errorf("invalid unit ID: %w", err)
+	}
+
+	if txo.ClientMetadata == nil {
+		return errors.New("client metadata is missing")
+	}
+	if txo.ClientMetadata.Timeout == 0 {
+		return errors.New("timeout is unassigned")
+	}
+	if fcrID := txo.FeeCreditRecordID(); len(fcrID) != 0 {
+		if pdr.PartitionTypeID == orchestration.PartitionTypeID {
+			return errors.New("partition doesn't use fee credit, fee credit record ID must be empty")
+		}
+		if err := checkUnitType(pdr, fcrID, feeCreditRecordUnitType); err != nil {
+			return fmt.Errorf("invalid fee credit record ID: %w", err)
+		}
+	}
+	if txo.StateLock != nil {
+		if err := txo.StateLock.IsValid(); err != nil {
+			return fmt.Errorf("invalid state lock: %w", err)
+		}
+	}
+
+	attr := def.attr()
+	if err := txo.UnmarshalAttributes(attr); err != nil {
+		return fmt.Errorf("decoding attributes: %w", err)
+	}
+	if err := validateAttributes(attr, pdr); err != nil {
+		return fmt.Errorf("invalid attributes: %w", err)
+	}
+	if def.refs != nil {
+		for _, ref := range def.refs(attr) {
+			if err := checkUnitType(pdr, ref.id, ref.unitType); err != nil {
+				return fmt.Errorf("invalid %s ID: %w", ref.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateAttributes(attr Attributes, pdr *types.PartitionDescriptionRecord) error {
+	if a, ok := attr.(pdrAttributes); ok {
+		return a.IsValidFor(pdr)
+	}
+	return attr.IsValid()
+}
+
+func checkUnitType(pdr *types.PartitionDescriptionRecord, id types.UnitID, unitType uint32) error {
+	typ, err := pdr.ExtractUnitType(id)
+	if err != nil {
+		return err
+	}
+	if unitType == 0 || typ == unitType {
+		return nil
+	}
+	return fmt.Errorf("expected unit type %d, got %d", unitType, typ)
+}
@@ -0,0 +1,164 @@
+package preflight
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/money"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/orchestration"
+	"github.com/alphabill-org/alphabill-go-base/txsystem/tokens"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_Validate(t *testing.T) {
+	pdr := &types.PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       5,
+		PartitionID:     money.DefaultPartitionID,
+		PartitionTypeID: money.PartitionTypeID,
+		UnitIDLen:       64,
+		TypeIDLen:       8,
+	}
+	unitID := func(id, unitType byte) types.UnitID { return types.UnitID{id, 0, 0, 0, 0, 0, 0, 0, unitType} }
+	newTxo := func(t *testing.T, txType uint16, id types.UnitID, attr any) *types.TransactionOrder {
+		txo := &types.TransactionOrder{
+			Version: 1,
+			Payload: types.Payload{
+				NetworkID:      pdr.NetworkID,
+				PartitionID:    pdr.PartitionID,
+				UnitID:         id,
+				Type:           txType,
+				ClientMetadata: &types.ClientMetadata{Timeout: 10, MaxTransactionFee: 1, FeeCreditRecordID: unitID(9, money.FeeCreditRecordUnitType)},
+			},
+		}
+		require.NoError(t, txo.SetAttributes(attr))
+		return txo
+	}
+	validTransfer := func(t *testing.T) *types.TransactionOrder {
+		return newTxo(t, money.TransactionTypeTransfer, unitID(1, money.BillUnitType), &money.TransferAttributes{TargetValue: 5, NewOwnerPredicate: []byte{1}})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, Validate(validTransfer(t), pdr))
+
+		txo := newTxo(t, money.TransactionTypeTransDC, unitID(1, money.BillUnitType), &money.TransferDCAttributes{Value: 5, TargetUnitID: unitID(2, money.BillUnitType)})
+		require.NoError(t, Validate(txo, pdr))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		require.ErrorIs(t, Validate(nil, pdr), types.ErrTransactionOrderIsNil)
+		require.ErrorIs(t, Validate(validTransfer(t), nil), types.ErrSystemDescriptionIsNil)
+
+		txo := validTransfer(t)
+		txo.Version = 2
+		require.EqualError(t, Validate(txo, pdr), "invalid version (type *types.TransactionOrder)")
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		var testCases = []struct {
+			name   string
+			modify func(txo *types.TransactionOrder)
+			errMsg string
+		}{
+			{"network", func(txo *types.TransactionOrder) { txo.NetworkID = 1 }, "invalid network 1, expected 5"},
+			{"partition", func(txo *types.TransactionOrder) { txo.PartitionID = 2 }, "invalid partition 00000002, expected 00000001"},
+			{"tx type", func(txo *types.TransactionOrder) { txo.Type = 99 }, "unknown transaction type 99 for partition type 1"},
+			{"unit ID length", func(txo *types.TransactionOrder) { txo.UnitID = txo.UnitID[1:] }, "invalid unit ID: expected 9 byte unit ID, got 8 bytes"},
+			{"unit type", func(txo *types.TransactionOrder) { txo.UnitID = unitID(1, 2) }, "invalid unit ID: expected unit type 1, got 2"},
+			{"client metadata", func(txo *types.TransactionOrder) { txo.ClientMetadata = nil }, "client metadata is missing"},
+			{"timeout", func(txo *types.TransactionOrder) { txo.ClientMetadata.Timeout = 0 }, "timeout is unassigned"},
+			{"FCR ID", func(txo *types.TransactionOrder) {
+				txo.ClientMetadata.FeeCreditRecordID = unitID(1, money.BillUnitType)
+			},
+				"invalid fee credit record ID: expected unit type 16, got 1"},
+			{"state lock", func(txo *types.TransactionOrder) { txo.StateLock = &types.StateLock{} }, "invalid state lock: missing execution predicate"},
+			{"attributes encoding", func(txo *types.TransactionOrder) { txo.Attributes = []byte{0xff} }, "decoding attributes: cbor: unexpected \"break\" code"},
+		}
+		for _, tc := range testCases {
+			txo := validTransfer(t)
+			tc.modify(txo)
+			require.EqualError(t, Validate(txo, pdr), tc.errMsg, tc.name)
+		}
+
+		txo := validTransfer(t)
+		txo.Type = 99
+		require.ErrorIs(t, Validate(txo, pdr), ErrUnknownTxType)
+	})
+
+	t.Run("invalid attributes", func(t *testing.T) {
+		txo := newTxo(t, money.TransactionTypeTransfer, unitID(1, money.BillUnitType), &money.TransferAttributes{NewOwnerPredicate: []byte{1}})
+		require.EqualError(t, Validate(txo, pdr), "invalid attributes: target value must be greater than zero")
+
+		txo = newTxo(t, money.TransactionTypeTransDC, unitID(1, money.BillUnitType), &money.TransferDCAttributes{Value: 5, TargetUnitID: []byte{1}})
+		require.EqualError(t, Validate(txo, pdr), "invalid target unit ID: expected unit ID length 9 bytes, got 1 bytes")
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		pdr := *pdr
+		pdr.PartitionID = tokens.DefaultPartitionID
+		pdr.PartitionTypeID = tokens.PartitionTypeID
+		newTxo := func(txType uint16, id types.UnitID, attr any) *types.TransactionOrder {
+			txo := &types.TransactionOrder{
+				Version: 1,
+				Payload: types.Payload{NetworkID: pdr.NetworkID, PartitionID: pdr.PartitionID, UnitID: id, Type: txType, ClientMetadata: &types.ClientMetadata{Timeout: 10}},
+			}
+			require.NoError(t, txo.SetAttributes(attr))
+			return txo
+		}
+		define := &tokens.DefineFungibleTokenAttributes{
+			Symbol:                   "FT",
+			SubTypeCreationPredicate: []byte{1},
+			TokenMintingPredicate:    []byte{1},
+			TokenTypeOwnerPredicate:  []byte{1},
+		}
+		txo := newTxo(tokens.TransactionTypeDefineFT, unitID(1, tokens.FungibleTokenTypeUnitType), define)
+		require.NoError(t, Validate(txo, &pdr))
+
+		define.ParentTypeID = unitID(2, tokens.FungibleTokenTypeUnitType)
+		txo = newTxo(tokens.TransactionTypeDefineFT, unitID(1, tokens.FungibleTokenTypeUnitType), define)
+		require.NoError(t, Validate(txo, &pdr))
+
+		define.ParentTypeID = unitID(2, tokens.NonFungibleTokenTypeUnitType)
+		txo = newTxo(tokens.TransactionTypeDefineFT, unitID(1, tokens.FungibleTokenTypeUnitType), define)
+		require.EqualError(t, Validate(txo, &pdr), "invalid attributes: invalid parent type ID: expected unit type 1, got 2")
+
+		define.ParentTypeID = []byte{1, 2}
+		txo = newTxo(tokens.TransactionTypeDefineFT, unitID(1, tokens.FungibleTokenTypeUnitType), define)
+		require.EqualError(t, Validate(txo, &pdr), "invalid attributes: invalid parent type ID: expected unit ID length 9 bytes, got 2 bytes")
+
+		burn := &tokens.BurnFungibleTokenAttributes{TypeID: unitID(2, tokens.FungibleTokenTypeUnitType), Value: 1, TargetTokenID: unitID(3, tokens.FungibleTokenTypeUnitType)}
+		txo = newTxo(tokens.TransactionTypeBurnFT, unitID(1, tokens.FungibleTokenUnitType), burn)
+		require.EqualError(t, Validate(txo, &pdr), "invalid target token ID: expected unit type 3, got 1")
+
+		txo = newTxo(tokens.TransactionTypeMintNFT, unitID(1, tokens.NonFungibleTokenUnitType), &tokens.MintNonFungibleTokenAttributes{})
+		require.EqualError(t, Validate(txo, &pdr), "invalid attributes: token type ID is empty")
+	})
+
+	t.Run("orchestration", func(t *testing.T) {
+		pdr := *pdr
+		pdr.PartitionID = orchestration.DefaultPartitionID
+		pdr.PartitionTypeID = orchestration.PartitionTypeID
+		txo := &types.TransactionOrder{
+			Version: 1,
+			Payload: types.Payload{
+				NetworkID:      pdr.NetworkID,
+				PartitionID:    pdr.PartitionID,
+				UnitID:         unitID(1, orchestration.VarUnitType),
+				Type:           orchestration.TransactionTypeAddVAR,
+				ClientMetadata: &types.ClientMetadata{Timeout: 10},
+			},
+		}
+		attr := &orchestration.AddVarAttributes{Var: orchestration.ValidatorAssignmentRecord{
+			ValidatorAssignment: orchestration.ValidatorAssignment{Validators: []orchestration.ValidatorInfo{{ValidatorID: []byte{1}, Stake: 1}}, QuorumSize: 1},
+		}}
+		require.NoError(t, txo.SetAttributes(attr))
+		require.NoError(t, Validate(txo, &pdr))
+
+		txo.ClientMetadata.FeeCreditRecordID = unitID(2, 16)
+		require.EqualError(t, Validate(txo, &pdr), "partition doesn't use fee credit, fee credit record ID must be empty")
+
+		pdr.PartitionTypeID = 99
+		require.ErrorIs(t, Validate(txo, &pdr), ErrUnknownPartitionType)
+	})
+}
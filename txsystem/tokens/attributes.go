@@ -2,9 +2,12 @@ package tokens
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/util"
 )
 
 const (
@@ -23,6 +26,22 @@ const (
 	TransactionTypeUpdateNFT   uint16 = 10
 )
 
+/*
+Limits of the attribute fields. These are the limits enforced by the tokens
+transaction system of the Alphabill node (txsystem/tokens package of
+github.com/alphabill-org/alphabill) and must be kept in sync with it; the
+NFT metadata (see NFTMetadata) uses the same limits.
+*/
+const (
+	MaxSymbolLength   = 16
+	MaxNameLength     = 256
+	MaxIconTypeLength = 64
+	MaxIconDataLength = 64 * 1024
+	MaxURILength      = 4096
+	MaxDataLength     = 65536
+	MaxDecimalPlaces  = 8
+)
+
 type (
 	DefineNonFungibleTokenAttributes struct {
 		_                        struct{}     `cbor:",toarray"`
@@ -115,6 +134,17 @@ type (
 		_               struct{}               `cbor:",toarray"`
 		BurnTokenProofs []*types.TxRecordProof // the transaction records and proofs that burned the source tokens
 	}
+
+	LockTokenAttributes struct {
+		_          struct{} `cbor:",toarray"`
+		LockStatus uint64   // status of the lock, non-zero value means locked (see types.LockReason)
+		Counter    uint64   // the transaction counter of this token
+	}
+
+	UnlockTokenAttributes struct {
+		_       struct{} `cbor:",toarray"`
+		Counter uint64   // the transaction counter of this token
+	}
 )
 
 func (i *Icon) Copy() *Icon {
@@ -126,3 +156,251 @@ func (i *Icon) Copy() *Icon {
 		Data: bytes.Clone(i.Data),
 	}
 }
+
+func (a *DefineNonFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if err := validateTypeDescription(a.Symbol, a.Name, a.Icon); err != nil {
+		return err
+	}
+	return errors.Join(
+		requirePredicate("sub-type creation predicate", a.SubTypeCreationPredicate),
+		requirePredicate("token minting predicate", a.TokenMintingPredicate),
+		requirePredicate("token type owner predicate", a.TokenTypeOwnerPredicate),
+		requirePredicate("data update predicate", a.DataUpdatePredicate),
+	)
+}
+
+/*
+IsValidFor validates the attributes (see IsValid) and the unit IDs in the attributes
+against the partition description "pdr", ie the parent type ID must be of correct
+length and a non-fungible token type ID.
+*/
+func (a *DefineNonFungibleTokenAttributes) IsValidFor(pdr *types.PartitionDescriptionRecord) error {
+	if err := a.IsValid(); err != nil {
+		return err
+	}
+	return validateParentTypeID(pdr, a.ParentTypeID, NonFungibleTokenTypeUnitType)
+}
+
+func (a *DefineFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if err := validateTypeDescription(a.Symbol, a.Name, a.Icon); err != nil {
+		return err
+	}
+	if a.DecimalPlaces > MaxDecimalPlaces {
+		return fmt.Errorf("invalid decimal places, maximum allowed %d, got %d", MaxDecimalPlaces, a.DecimalPlaces)
+	}
+	return errors.Join(
+		requirePredicate("sub-type creation predicate", a.SubTypeCreationPredicate),
+		requirePredicate("token minting predicate", a.TokenMintingPredicate),
+		requirePredicate("token type owner predicate", a.TokenTypeOwnerPredicate),
+	)
+}
+
+/*
+IsValidFor validates the attributes (see IsValid) and the unit IDs in the attributes
+against the partition description "pdr", ie the parent type ID must be of correct
+length and a fungible token type ID.
+*/
+func (a *DefineFungibleTokenAttributes) IsValidFor(pdr *types.PartitionDescriptionRecord) error {
+	if err := a.IsValid(); err != nil {
+		return err
+	}
+	return validateParentTypeID(pdr, a.ParentTypeID, FungibleTokenTypeUnitType)
+}
+
+func (a *MintNonFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.TypeID) == 0 {
+		return errors.New("token type ID is empty")
+	}
+	if len(a.Name) > MaxNameLength {
+		return fmt.Errorf("name length exceeds the allowed maximum of %d bytes", MaxNameLength)
+	}
+	if len(a.URI) > MaxURILength {
+		return fmt.Errorf("URI length exceeds the allowed maximum of %d bytes", MaxURILength)
+	}
+	if a.URI != "" && !util.IsValidURI(a.URI) {
+		return fmt.Errorf("URI %q is invalid", a.URI)
+	}
+	if len(a.Data) > MaxDataLength {
+		return fmt.Errorf("data exceeds the maximum allowed size of %d bytes", MaxDataLength)
+	}
+	return errors.Join(
+		requirePredicate("owner predicate", a.OwnerPredicate),
+		requirePredicate("data update predicate", a.DataUpdatePredicate),
+	)
+}
+
+func (a *TransferNonFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.TypeID) == 0 {
+		return errors.New("token type ID is empty")
+	}
+	return requirePredicate("new owner predicate", a.NewOwnerPredicate)
+}
+
+func (a *UpdateNonFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.Data) > MaxDataLength {
+		return fmt.Errorf("data exceeds the maximum allowed size of %d bytes", MaxDataLength)
+	}
+	return nil
+}
+
+func (a *MintFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.TypeID) == 0 {
+		return errors.New("token type ID is empty")
+	}
+	if a.Value == 0 {
+		return errors.New("token must have value greater than zero")
+	}
+	return requirePredicate("owner predicate", a.OwnerPredicate)
+}
+
+func (a *TransferFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.TypeID) == 0 {
+		return errors.New("token type ID is empty")
+	}
+	if a.Value == 0 {
+		return errors.New("value must be greater than zero")
+	}
+	return requirePredicate("new owner predicate", a.NewOwnerPredicate)
+}
+
+func (a *SplitFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.TypeID) == 0 {
+		return errors.New("token type ID is empty")
+	}
+	if a.TargetValue == 0 {
+		return errors.New("target value must be greater than zero")
+	}
+	return requirePredicate("new owner predicate", a.NewOwnerPredicate)
+}
+
+func (a *BurnFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.TypeID) == 0 {
+		return errors.New("token type ID is empty")
+	}
+	if a.Value == 0 {
+		return errors.New("value must be greater than zero")
+	}
+	if len(a.TargetTokenID) == 0 {
+		return errors.New("target token ID is empty")
+	}
+	return nil
+}
+
+func (a *JoinFungibleTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if len(a.BurnTokenProofs) == 0 {
+		return errors.New("burn token proofs are empty")
+	}
+	for i, p := range a.BurnTokenProofs {
+		if err := p.IsValid(); err != nil {
+			return fmt.Errorf("burn token proof %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (a *LockTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	if err := types.LockReason(a.LockStatus).IsValid(); err != nil {
+		return fmt.Errorf("invalid lock status: %w", err)
+	}
+	return nil
+}
+
+func (a *UnlockTokenAttributes) IsValid() error {
+	if a == nil {
+		return errors.New("attributes are nil")
+	}
+	return nil
+}
+
+/*
+VerifyCounter validates the attributes (see IsValid) and checks that the counter
+of the attributes is the current "counter" of the locked token.
+*/
+func (a *UnlockTokenAttributes) VerifyCounter(counter uint64) error {
+	if err := a.IsValid(); err != nil {
+		return err
+	}
+	if a.Counter != counter {
+		return fmt.Errorf("counter %d does not match the counter of the token %d", a.Counter, counter)
+	}
+	return nil
+}
+
+func validateTypeDescription(symbol, name string, icon *Icon) error {
+	if symbol == "" {
+		return errors.New("symbol is empty")
+	}
+	if len(symbol) > MaxSymbolLength {
+		return fmt.Errorf("symbol length exceeds the allowed maximum of %d bytes", MaxSymbolLength)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("name length exceeds the allowed maximum of %d bytes", MaxNameLength)
+	}
+	if icon != nil {
+		if len(icon.Type) > MaxIconTypeLength {
+			return fmt.Errorf("icon type length exceeds the allowed maximum of %d bytes", MaxIconTypeLength)
+		}
+		if len(icon.Data) > MaxIconDataLength {
+			return fmt.Errorf("icon data length exceeds the allowed maximum of %d bytes", MaxIconDataLength)
+		}
+	}
+	return nil
+}
+
+// validateParentTypeID checks the optional parent type ID of the token type definition.
+func validateParentTypeID(pdr *types.PartitionDescriptionRecord, id types.UnitID, unitType uint32) error {
+	if len(id) == 0 {
+		return nil
+	}
+	if pdr == nil {
+		return types.ErrSystemDescriptionIsNil
+	}
+	typ, err := pdr.ExtractUnitType(id)
+	if err != nil {
+		return fmt.Errorf("invalid parent type ID: %w", err)
+	}
+	if typ != unitType {
+		return fmt.Errorf("invalid parent type ID: expected unit type %d, got %d", unitType, typ)
+	}
+	return nil
+}
+
+func requirePredicate(name string, predicate []byte) error {
+	if len(predicate) == 0 {
+		return fmt.Errorf("%s is empty", name)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_DefineTokenTypeAttributes_IsValid(t *testing.T) {
+	validFT := func() *DefineFungibleTokenAttributes {
+		return &DefineFungibleTokenAttributes{
+			Symbol:                   "FT",
+			Name:                     "fungible",
+			Icon:                     &Icon{Type: "image/png", Data: []byte{1}},
+			DecimalPlaces:            MaxDecimalPlaces,
+			SubTypeCreationPredicate: []byte{1},
+			TokenMintingPredicate:    []byte{2},
+			TokenTypeOwnerPredicate:  []byte{3},
+		}
+	}
+	validNFT := func() *DefineNonFungibleTokenAttributes {
+		return &DefineNonFungibleTokenAttributes{
+			Symbol:                   "NFT",
+			SubTypeCreationPredicate: []byte{1},
+			TokenMintingPredicate:    []byte{2},
+			TokenTypeOwnerPredicate:  []byte{3},
+			DataUpdatePredicate:      []byte{4},
+		}
+	}
+	require.NoError(t, validFT().IsValid())
+	require.NoError(t, validNFT().IsValid())
+	require.EqualError(t, (*DefineFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (*DefineNonFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+
+	var testCases = []struct {
+		modify func(ft *DefineFungibleTokenAttributes)
+		errMsg string
+	}{
+		{func(a *DefineFungibleTokenAttributes) { a.Symbol = "" }, "symbol is empty"},
+		{func(a *DefineFungibleTokenAttributes) { a.Symbol = strings.Repeat("x", MaxSymbolLength+1) }, "symbol length exceeds the allowed maximum of 16 bytes"},
+		{func(a *DefineFungibleTokenAttributes) { a.Name = strings.Repeat("x", MaxNameLength+1) }, "name length exceeds the allowed maximum of 256 bytes"},
+		{func(a *DefineFungibleTokenAttributes) { a.Icon.Type = strings.Repeat("x", MaxIconTypeLength+1) }, "icon type length exceeds the allowed maximum of 64 bytes"},
+		{func(a *DefineFungibleTokenAttributes) { a.Icon.Data = make([]byte, MaxIconDataLength+1) }, "icon data length exceeds the allowed maximum of 65536 bytes"},
+		{func(a *DefineFungibleTokenAttributes) { a.DecimalPlaces = MaxDecimalPlaces + 1 }, "invalid decimal places, maximum allowed 8, got 9"},
+		{func(a *DefineFungibleTokenAttributes) { a.SubTypeCreationPredicate = nil }, "sub-type creation predicate is empty"},
+		{func(a *DefineFungibleTokenAttributes) { a.TokenMintingPredicate = nil }, "token minting predicate is empty"},
+		{func(a *DefineFungibleTokenAttributes) { a.TokenTypeOwnerPredicate = nil }, "token type owner predicate is empty"},
+	}
+	for _, tc := range testCases {
+		attr := validFT()
+		tc.modify(attr)
+		require.EqualError(t, attr.IsValid(), tc.errMsg)
+	}
+
+	attr := validNFT()
+	attr.DataUpdatePredicate = nil
+	require.EqualError(t, attr.IsValid(), "data update predicate is empty")
+	attr.Symbol = strings.Repeat("x", MaxSymbolLength+1)
+	require.EqualError(t, attr.IsValid(), "symbol length exceeds the allowed maximum of 16 bytes")
+	attr.Symbol = ""
+	require.EqualError(t, attr.IsValid(), "symbol is empty")
+}
+
+func Test_DefineTokenTypeAttributes_IsValidFor(t *testing.T) {
+	pdr := &types.PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       5,
+		PartitionID:     DefaultPartitionID,
+		PartitionTypeID: PartitionTypeID,
+		TypeIDLen:       8,
+		UnitIDLen:       256,
+	}
+	typeID := func(unitType uint32) types.UnitID {
+		id, err := pdr.ComposeUnitID(types.ShardID{}, unitType, func(b []byte) error { b[0] = 1; return nil })
+		require.NoError(t, err)
+		return id
+	}
+	ft := &DefineFungibleTokenAttributes{
+		Symbol:                   "FT",
+		SubTypeCreationPredicate: []byte{1},
+		TokenMintingPredicate:    []byte{2},
+		TokenTypeOwnerPredicate:  []byte{3},
+	}
+	nft := &DefineNonFungibleTokenAttributes{
+		Symbol:                   "NFT",
+		SubTypeCreationPredicate: []byte{1},
+		TokenMintingPredicate:    []byte{2},
+		TokenTypeOwnerPredicate:  []byte{3},
+		DataUpdatePredicate:      []byte{4},
+	}
+
+	// parent type is optional
+	require.NoError(t, ft.IsValidFor(pdr))
+	require.NoError(t, nft.IsValidFor(pdr))
+
+	ft.ParentTypeID = typeID(FungibleTokenTypeUnitType)
+	nft.ParentTypeID = typeID(NonFungibleTokenTypeUnitType)
+	require.NoError(t, ft.IsValidFor(pdr))
+	require.NoError(t, nft.IsValidFor(pdr))
+	require.ErrorIs(t, ft.IsValidFor(nil), types.ErrSystemDescriptionIsNil)
+
+	ft.ParentTypeID = typeID(NonFungibleTokenTypeUnitType)
+	nft.ParentTypeID = typeID(FungibleTokenTypeUnitType)
+	require.EqualError(t, ft.IsValidFor(pdr), "invalid parent type ID: expected unit type 1, got 2")
+	require.EqualError(t, nft.IsValidFor(pdr), "invalid parent type ID: expected unit type 2, got 1")
+
+	ft.ParentTypeID = types.UnitID{1, 2}
+	require.EqualError(t, ft.IsValidFor(pdr), "invalid parent type ID: expected unit ID length 33 bytes, got 2 bytes")
+
+	// stateless validation is done first
+	ft.Symbol = ""
+	require.EqualError(t, ft.IsValidFor(pdr), "symbol is empty")
+}
+
+func Test_NonFungibleTokenAttributes_IsValid(t *testing.T) {
+	t.Run("mint", func(t *testing.T) {
+		valid := func() *MintNonFungibleTokenAttributes {
+			return &MintNonFungibleTokenAttributes{TypeID: []byte{1}, URI: "https://example.com", OwnerPredicate: []byte{2}, DataUpdatePredicate: []byte{3}}
+		}
+		require.NoError(t, valid().IsValid())
+		require.EqualError(t, (*MintNonFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+
+		var testCases = []struct {
+			modify func(a *MintNonFungibleTokenAttributes)
+			errMsg string
+		}{
+			{func(a *MintNonFungibleTokenAttributes) { a.TypeID = nil }, "token type ID is empty"},
+			{func(a *MintNonFungibleTokenAttributes) { a.Name = strings.Repeat("x", MaxNameLength+1) }, "name length exceeds the allowed maximum of 256 bytes"},
+			{func(a *MintNonFungibleTokenAttributes) { a.URI = strings.Repeat("x", MaxURILength+1) }, "URI length exceeds the allowed maximum of 4096 bytes"},
+			{func(a *MintNonFungibleTokenAttributes) { a.URI = "invalid" }, `URI "invalid" is invalid`},
+			{func(a *MintNonFungibleTokenAttributes) { a.Data = make([]byte, MaxDataLength+1) }, "data exceeds the maximum allowed size of 65536 bytes"},
+			{func(a *MintNonFungibleTokenAttributes) { a.OwnerPredicate = nil }, "owner predicate is empty"},
+			{func(a *MintNonFungibleTokenAttributes) { a.DataUpdatePredicate = nil }, "data update predicate is empty"},
+		}
+		for _, tc := range testCases {
+			attr := valid()
+			tc.modify(attr)
+			require.EqualError(t, attr.IsValid(), tc.errMsg)
+		}
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		require.EqualError(t, (*TransferNonFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+		require.EqualError(t, (&TransferNonFungibleTokenAttributes{NewOwnerPredicate: []byte{1}}).IsValid(), "token type ID is empty")
+		require.EqualError(t, (&TransferNonFungibleTokenAttributes{TypeID: []byte{1}}).IsValid(), "new owner predicate is empty")
+		require.NoError(t, (&TransferNonFungibleTokenAttributes{TypeID: []byte{1}, NewOwnerPredicate: []byte{1}}).IsValid())
+	})
+
+	t.Run("update", func(t *testing.T) {
+		require.EqualError(t, (*UpdateNonFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+		require.EqualError(t, (&UpdateNonFungibleTokenAttributes{Data: make([]byte, MaxDataLength+1)}).IsValid(), "data exceeds the maximum allowed size of 65536 bytes")
+		require.NoError(t, (&UpdateNonFungibleTokenAttributes{}).IsValid())
+	})
+}
+
+func Test_FungibleTokenAttributes_IsValid(t *testing.T) {
+	typeID := types.UnitID{1}
+	owner := []byte{2}
+
+	require.EqualError(t, (*MintFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&MintFungibleTokenAttributes{Value: 1, OwnerPredicate: owner}).IsValid(), "token type ID is empty")
+	require.EqualError(t, (&MintFungibleTokenAttributes{TypeID: typeID, OwnerPredicate: owner}).IsValid(), "token must have value greater than zero")
+	require.EqualError(t, (&MintFungibleTokenAttributes{TypeID: typeID, Value: 1}).IsValid(), "owner predicate is empty")
+	require.NoError(t, (&MintFungibleTokenAttributes{TypeID: typeID, Value: 1, OwnerPredicate: owner}).IsValid())
+
+	require.EqualError(t, (*TransferFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&TransferFungibleTokenAttributes{Value: 1, NewOwnerPredicate: owner}).IsValid(), "token type ID is empty")
+	require.EqualError(t, (&TransferFungibleTokenAttributes{TypeID: typeID, NewOwnerPredicate: owner}).IsValid(), "value must be greater than zero")
+	require.EqualError(t, (&TransferFungibleTokenAttributes{TypeID: typeID, Value: 1}).IsValid(), "new owner predicate is empty")
+	require.NoError(t, (&TransferFungibleTokenAttributes{TypeID: typeID, Value: 1, NewOwnerPredicate: owner}).IsValid())
+
+	require.EqualError(t, (*SplitFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&SplitFungibleTokenAttributes{TargetValue: 1, NewOwnerPredicate: owner}).IsValid(), "token type ID is empty")
+	require.EqualError(t, (&SplitFungibleTokenAttributes{TypeID: typeID, NewOwnerPredicate: owner}).IsValid(), "target value must be greater than zero")
+	require.EqualError(t, (&SplitFungibleTokenAttributes{TypeID: typeID, TargetValue: 1}).IsValid(), "new owner predicate is empty")
+	require.NoError(t, (&SplitFungibleTokenAttributes{TypeID: typeID, TargetValue: 1, NewOwnerPredicate: owner}).IsValid())
+
+	require.EqualError(t, (*BurnFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&BurnFungibleTokenAttributes{Value: 1, TargetTokenID: []byte{3}}).IsValid(), "token type ID is empty")
+	require.EqualError(t, (&BurnFungibleTokenAttributes{TypeID: typeID, TargetTokenID: []byte{3}}).IsValid(), "value must be greater than zero")
+	require.EqualError(t, (&BurnFungibleTokenAttributes{TypeID: typeID, Value: 1}).IsValid(), "target token ID is empty")
+	require.NoError(t, (&BurnFungibleTokenAttributes{TypeID: typeID, Value: 1, TargetTokenID: []byte{3}}).IsValid())
+
+	require.EqualError(t, (*JoinFungibleTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&JoinFungibleTokenAttributes{}).IsValid(), "burn token proofs are empty")
+	require.EqualError(t, (&JoinFungibleTokenAttributes{BurnTokenProofs: []*types.TxRecordProof{nil}}).IsValid(), "burn token proof 0: transaction record proof is nil")
+}
+
+func Test_LockUnlockTokenAttributes_IsValid(t *testing.T) {
+	require.EqualError(t, (*LockTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.EqualError(t, (&LockTokenAttributes{Counter: 1}).IsValid(), "invalid lock status: invalid lock reason 0")
+	require.EqualError(t, (&LockTokenAttributes{LockStatus: 99}).IsValid(), "invalid lock status: invalid lock reason 99")
+	require.NoError(t, (&LockTokenAttributes{LockStatus: uint64(types.LockReasonCollectDust)}).IsValid())
+
+	require.EqualError(t, (*UnlockTokenAttributes)(nil).IsValid(), "attributes are nil")
+	require.NoError(t, (&UnlockTokenAttributes{}).IsValid())
+
+	require.EqualError(t, (*UnlockTokenAttributes)(nil).VerifyCounter(0), "attributes are nil")
+	require.EqualError(t, (&UnlockTokenAttributes{Counter: 3}).VerifyCounter(4), "counter 3 does not match the counter of the token 4")
+	require.NoError(t, (&UnlockTokenAttributes{Counter: 4}).VerifyCounter(4))
+}
@@ -0,0 +1,171 @@
+package tokens
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/consolidation"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+type (
+	// FungibleToken is a fungible token unit, ie unit ID and its data.
+	FungibleToken struct {
+		ID   types.UnitID
+		Data *FungibleTokenData
+	}
+
+	// JoinPlan describes joining several fungible tokens of the same type into
+	// one target token: burn orders which have to be signed and sent to the
+	// tokens partition, once these have been executed the proofs of the burn
+	// transactions are to be used to create join transaction.
+	JoinPlan struct {
+		TypeID             types.UnitID
+		TargetTokenID      types.UnitID
+		TargetTokenCounter uint64 // counter of the target token, join must happen before target token changes
+		Value              uint64 // sum of the values of the burned tokens
+		// burn orders (without auth proof), ordered by unit ID
+		Burns []*types.TransactionOrder
+	}
+)
+
+/*
+GroupFungibleTokensByType groups the tokens by their type, groups are ordered
+by type ID and tokens inside the group by token ID.
+*/
+func GroupFungibleTokensByType(tokens []FungibleToken) ([][]FungibleToken, error) {
+	groups := map[string][]FungibleToken{}
+	for i, t := range tokens {
+		if len(t.ID) == 0 || t.Data == nil {
+			return nil, fmt.Errorf("token %d is not assigned", i)
+		}
+		groups[string(t.Data.TypeID)] = append(groups[string(t.Data.TypeID)], t)
+	}
+	res := make([][]FungibleToken, 0, len(groups))
+	for _, g := range groups {
+		slices.SortFunc(g, func(a, b FungibleToken) int { return bytes.Compare(a.ID, b.ID) })
+		res = append(res, g)
+	}
+	slices.SortFunc(res, func(a, b []FungibleToken) int { return bytes.Compare(a[0].Data.TypeID, b[0].Data.TypeID) })
+	return res, nil
+}
+
+/*
+PlanJoins groups the tokens by type and creates join plan for every type which
+has more than one token. The token with the biggest value is used as the target
+token. See PlanJoin for the description of the other arguments.
+*/
+func PlanJoins(tokens []FungibleToken, maxTokens int, txTemplate types.Payload) ([]*JoinPlan, error) {
+	groups, err := GroupFungibleTokensByType(tokens)
+	if err != nil {
+		return nil, err
+	}
+	var plans []*JoinPlan
+	for _, g := range groups {
+		if len(g) < 2 {
+			continue
+		}
+		target := slices.MaxFunc(g, func(a, b FungibleToken) int { return cmp.Compare(a.Data.Value, b.Data.Value) })
+		plan, err := PlanJoin(target, g, maxTokens, txTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("token type %s: %w", target.Data.TypeID, err)
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+/*
+PlanJoin creates plan to join the "tokens" into the "target" token. All the tokens
+must be of the same type as the target token.
+
+The "txTemplate" must have NetworkID, PartitionID and ClientMetadata fields
+assigned, these are copied into all the transaction orders created.
+
+When "maxTokens" is greater than zero at most that many tokens (with the smallest
+values) are burned.
+*/
+func PlanJoin(target FungibleToken, tokens []FungibleToken, maxTokens int, txTemplate types.Payload) (*JoinPlan, error) {
+	if len(target.ID) == 0 || target.Data == nil {
+		return nil, errors.New("target token is not assigned")
+	}
+	units := make([]consolidation.Unit, len(tokens))
+	for i, t := range tokens {
+		if len(t.ID) == 0 || t.Data == nil {
+			return nil, fmt.Errorf("token %d is not assigned", i)
+		}
+		if !bytes.Equal(t.Data.TypeID, target.Data.TypeID) {
+			return nil, fmt.Errorf("token %s is of type %s, expected %s", t.ID, t.Data.TypeID, target.Data.TypeID)
+		}
+		units[i] = consolidation.Unit{ID: t.ID, Value: t.Data.Value, Counter: t.Data.Counter}
+	}
+	burn, value, err := consolidation.Select(consolidation.Unit{ID: target.ID, Value: target.Data.Value}, units, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &JoinPlan{
+		TypeID:             target.Data.TypeID,
+		TargetTokenID:      target.ID,
+		TargetTokenCounter: target.Data.Counter,
+		Value:              value,
+		Burns:              make([]*types.TransactionOrder, len(burn)),
+	}
+	for i, t := range burn {
+		attr := &BurnFungibleTokenAttributes{
+			TypeID:             target.Data.TypeID,
+			Value:              t.Value,
+			TargetTokenID:      target.ID,
+			TargetTokenCounter: target.Data.Counter,
+			Counter:            t.Counter,
+		}
+		if plan.Burns[i], err = types.NewTransactionOrder(txTemplate, t.ID, TransactionTypeBurnFT, attr); err != nil {
+			return nil, fmt.Errorf("creating burn order for token %s: %w", t.ID, err)
+		}
+	}
+	return plan, nil
+}
+
+/*
+JoinAttributes verifies that "proofs" are proofs of successfully executed burn
+transactions of the plan (see consolidation.Plan.MatchProofs) and returns join
+attributes. All the burned tokens must be of the type of the plan.
+*/
+func (p *JoinPlan) JoinAttributes(proofs []*types.TxRecordProof) (*JoinFungibleTokenAttributes, error) {
+	plan := consolidation.Plan{
+		TargetUnitID:      p.TargetTokenID,
+		TargetUnitCounter: p.TargetTokenCounter,
+		Value:             p.Value,
+		TxType:            TransactionTypeBurnFT,
+		Orders:            p.Burns,
+	}
+	sorted, err := plan.MatchProofs(proofs, func(txo *types.TransactionOrder) (*consolidation.Transfer, error) {
+		attr := &BurnFungibleTokenAttributes{}
+		if err := txo.UnmarshalAttributes(attr); err != nil {
+			return nil, fmt.Errorf("decoding burn attributes: %w", err)
+		}
+		if !bytes.Equal(attr.TypeID, p.TypeID) {
+			return nil, fmt.Errorf("burned token type %s, expected %s", attr.TypeID, p.TypeID)
+		}
+		return &consolidation.Transfer{Value: attr.Value, Counter: attr.Counter, TargetUnitID: attr.TargetTokenID, TargetUnitCounter: attr.TargetTokenCounter}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &JoinFungibleTokenAttributes{BurnTokenProofs: sorted}, nil
+}
+
+/*
+JoinOrder verifies the proofs (see JoinAttributes) and returns join transaction
+order (without auth proof) for the target token of the plan.
+*/
+func (p *JoinPlan) JoinOrder(proofs []*types.TxRecordProof, txTemplate types.Payload) (*types.TransactionOrder, error) {
+	attr, err := p.JoinAttributes(proofs)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewTransactionOrder(txTemplate, p.TargetTokenID, TransactionTypeJoinFT, attr)
+}
@@ -0,0 +1,150 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func newTestToken(typ, id byte, value, counter uint64) FungibleToken {
+	return FungibleToken{
+		ID:   types.UnitID{0, id, FungibleTokenUnitType},
+		Data: &FungibleTokenData{TypeID: types.UnitID{0, typ, FungibleTokenTypeUnitType}, Value: value, Counter: counter},
+	}
+}
+
+func Test_GroupFungibleTokensByType(t *testing.T) {
+	groups, err := GroupFungibleTokensByType(nil)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+
+	_, err = GroupFungibleTokensByType([]FungibleToken{newTestToken(1, 1, 1, 0), {}})
+	require.EqualError(t, err, "token 1 is not assigned")
+
+	tokens := []FungibleToken{newTestToken(2, 4, 1, 0), newTestToken(1, 3, 1, 0), newTestToken(2, 2, 1, 0), newTestToken(1, 1, 1, 0)}
+	groups, err = GroupFungibleTokensByType(tokens)
+	require.NoError(t, err)
+	require.Equal(t, [][]FungibleToken{{tokens[3], tokens[1]}, {tokens[2], tokens[0]}}, groups)
+}
+
+func Test_PlanJoin(t *testing.T) {
+	txTemplate := types.Payload{
+		NetworkID:      3,
+		PartitionID:    DefaultPartitionID,
+		ClientMetadata: &types.ClientMetadata{Timeout: 10, MaxTransactionFee: 2, FeeCreditRecordID: []byte{0xf}},
+	}
+	target := newTestToken(1, 1, 100, 7)
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := PlanJoin(FungibleToken{}, []FungibleToken{newTestToken(1, 2, 1, 0)}, 0, txTemplate)
+		require.EqualError(t, err, "target token is not assigned")
+
+		_, err = PlanJoin(target, []FungibleToken{newTestToken(1, 2, 1, 0), {}}, 0, txTemplate)
+		require.EqualError(t, err, "token 1 is not assigned")
+
+		_, err = PlanJoin(target, []FungibleToken{target}, 0, txTemplate)
+		require.EqualError(t, err, "no units to consolidate")
+
+		_, err = PlanJoin(target, []FungibleToken{newTestToken(2, 2, 1, 0)}, 0, txTemplate)
+		require.EqualError(t, err, "token 000203 is of type 000201, expected 000101")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		tokens := []FungibleToken{newTestToken(1, 5, 50, 1), target, newTestToken(1, 3, 1, 2), newTestToken(1, 4, 1, 3), newTestToken(1, 2, 10, 4)}
+		plan, err := PlanJoin(target, tokens, 3, txTemplate)
+		require.NoError(t, err)
+		require.Equal(t, target.ID, plan.TargetTokenID)
+		require.Equal(t, target.Data.TypeID, plan.TypeID)
+		require.EqualValues(t, 7, plan.TargetTokenCounter)
+		require.EqualValues(t, 12, plan.Value)
+		require.Len(t, plan.Burns, 3)
+		for i, tok := range []FungibleToken{tokens[4], tokens[2], tokens[3]} {
+			txo := plan.Burns[i]
+			require.Equal(t, tok.ID, txo.UnitID)
+			require.Equal(t, TransactionTypeBurnFT, txo.Type)
+			require.Equal(t, txTemplate.ClientMetadata, txo.ClientMetadata)
+			require.NotSame(t, txTemplate.ClientMetadata, txo.ClientMetadata)
+			attr := &BurnFungibleTokenAttributes{}
+			require.NoError(t, txo.UnmarshalAttributes(attr))
+			require.Equal(t, &BurnFungibleTokenAttributes{TypeID: tok.Data.TypeID, Value: tok.Data.Value, TargetTokenID: target.ID, TargetTokenCounter: 7, Counter: tok.Data.Counter}, attr)
+		}
+	})
+}
+
+func Test_PlanJoins(t *testing.T) {
+	tokens := []FungibleToken{
+		newTestToken(1, 1, 5, 0),
+		newTestToken(2, 2, 5, 0),
+		newTestToken(1, 3, 9, 0),
+		newTestToken(1, 4, 1, 0),
+		newTestToken(3, 5, 1, 0),
+		newTestToken(3, 6, 2, 0),
+	}
+	plans, err := PlanJoins(tokens, 0, types.Payload{})
+	require.NoError(t, err)
+	// type 2 has only one token
+	require.Len(t, plans, 2)
+	require.Equal(t, tokens[2].ID, plans[0].TargetTokenID)
+	require.Len(t, plans[0].Burns, 2)
+	require.EqualValues(t, 6, plans[0].Value)
+	require.Equal(t, tokens[5].ID, plans[1].TargetTokenID)
+	require.Len(t, plans[1].Burns, 1)
+	require.EqualValues(t, 1, plans[1].Value)
+
+	_, err = PlanJoins([]FungibleToken{{}}, 0, types.Payload{})
+	require.EqualError(t, err, "token 0 is not assigned")
+}
+
+func Test_JoinPlan_JoinAttributes(t *testing.T) {
+	txTemplate := types.Payload{NetworkID: 3, PartitionID: DefaultPartitionID}
+	target := newTestToken(1, 1, 100, 7)
+	plan, err := PlanJoin(target, []FungibleToken{newTestToken(1, 2, 2, 1), newTestToken(1, 3, 3, 1)}, 0, txTemplate)
+	require.NoError(t, err)
+
+	newProof := func(t *testing.T, txo *types.TransactionOrder, status types.TxStatus) *types.TxRecordProof {
+		txoBytes, err := txo.MarshalCBOR()
+		require.NoError(t, err)
+		return &types.TxRecordProof{
+			TxRecord: &types.TransactionRecord{
+				Version:          1,
+				TransactionOrder: txoBytes,
+				ServerMetadata:   &types.ServerMetadata{SuccessIndicator: status},
+			},
+			TxProof: &types.TxProof{Version: 1},
+		}
+	}
+	burnWithAttr := func(t *testing.T, attr *BurnFungibleTokenAttributes) *types.TxRecordProof {
+		txo := *plan.Burns[0]
+		require.NoError(t, txo.SetAttributes(attr))
+		return newProof(t, &txo, types.TxStatusSuccessful)
+	}
+	proofs := []*types.TxRecordProof{
+		newProof(t, plan.Burns[1], types.TxStatusSuccessful),
+		newProof(t, plan.Burns[0], types.TxStatusSuccessful),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		attr, err := plan.JoinAttributes(proofs)
+		require.NoError(t, err)
+		require.Equal(t, []*types.TxRecordProof{proofs[1], proofs[0]}, attr.BurnTokenProofs)
+
+		txo, err := plan.JoinOrder(proofs, txTemplate)
+		require.NoError(t, err)
+		require.Equal(t, target.ID, txo.UnitID)
+		require.Equal(t, TransactionTypeJoinFT, txo.Type)
+		joinAttr := &JoinFungibleTokenAttributes{}
+		require.NoError(t, txo.UnmarshalAttributes(joinAttr))
+		require.Len(t, joinAttr.BurnTokenProofs, 2)
+	})
+
+	t.Run("attributes mismatch", func(t *testing.T) {
+		_, err := plan.JoinAttributes([]*types.TxRecordProof{proofs[0], burnWithAttr(t, &BurnFungibleTokenAttributes{TypeID: types.UnitID{9}, Value: 2, TargetTokenID: target.ID, TargetTokenCounter: 7})})
+		require.EqualError(t, err, "proof 1: burned token type 09, expected 000101")
+
+		// target token counter changed between planning and burning
+		_, err = plan.JoinAttributes([]*types.TxRecordProof{proofs[0], burnWithAttr(t, &BurnFungibleTokenAttributes{TypeID: plan.TypeID, Value: 2, TargetTokenID: target.ID, TargetTokenCounter: 8})})
+		require.EqualError(t, err, "proof 1: target unit counter 8, expected 7")
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Package metadata implements the content checks of the NFT metadata standard (see
+tokens.NFTMetadata) which require media sniffing and image decoding. It is kept
+separate from the tokens package so that users of the tokens package do not have
+to link the image decoders.
+*/
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/tokens"
+)
+
+// MaxIconDimension is the max width and height of the icon in pixels.
+const MaxIconDimension = 1024
+
+/*
+CheckIcon checks that the icon follows the NFT metadata standard: the icon passes
+tokens.Icon.CheckStandard, the data is image of the declared type and within the
+dimension limits. Dimensions of the SVG images are not checked.
+*/
+func CheckIcon(icon *tokens.Icon) error {
+	if err := icon.CheckStandard(); err != nil {
+		return err
+	}
+	width, height, err := IconDimensions(icon)
+	if err != nil {
+		return err
+	}
+	if width > MaxIconDimension || height > MaxIconDimension {
+		return fmt.Errorf("icon is %dx%d pixels, allowed %dx%d", width, height, MaxIconDimension, MaxIconDimension)
+	}
+	return nil
+}
+
+/*
+IconDimensions sniffs the width and height of the icon image, returns error when
+the data is not of the declared type. For SVG images zero dimensions are returned.
+*/
+func IconDimensions(icon *tokens.Icon) (width, height int, err error) {
+	if icon == nil {
+		return 0, 0, errors.New("icon is nil")
+	}
+	switch icon.Type {
+	case "image/svg+xml":
+		if !utf8.Valid(icon.Data) || !bytes.Contains(icon.Data, []byte("<svg")) {
+			return 0, 0, errors.New("icon data is not SVG image")
+		}
+		return 0, 0, nil
+	case "image/webp":
+		return webpDimensions(icon.Data)
+	}
+	if ct := http.DetectContentType(icon.Data); ct != icon.Type {
+		return 0, 0, fmt.Errorf("icon data is of type %q, declared %q", ct, icon.Type)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(icon.Data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("decoding icon image: %w", err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+/*
+webpDimensions returns the canvas size of the WebP image, see
+https://developers.google.com/speed/webp/docs/riff_container
+*/
+func webpDimensions(data []byte) (width, height int, err error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, errors.New("icon data is not WebP image")
+	}
+	chunk := data[20:]
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// frame tag (3 bytes) and start code (3 bytes) are followed by 14 bit dimensions
+		if chunk[3] != 0x9d || chunk[4] != 0x01 || chunk[5] != 0x2a {
+			return 0, 0, errors.New("invalid WebP VP8 frame header")
+		}
+		return int(binary.LittleEndian.Uint16(chunk[6:]) & 0x3fff), int(binary.LittleEndian.Uint16(chunk[8:]) & 0x3fff), nil
+	case "VP8L":
+		if chunk[0] != 0x2f {
+			return 0, 0, errors.New("invalid WebP VP8L signature")
+		}
+		bits := binary.LittleEndian.Uint32(chunk[1:])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, nil
+	case "VP8X":
+		w := uint32(chunk[4]) | uint32(chunk[5])<<8 | uint32(chunk[6])<<16
+		h := uint32(chunk[7]) | uint32(chunk[8])<<8 | uint32(chunk[9])<<16
+		return int(w) + 1, int(h) + 1, nil
+	default:
+		return 0, 0, fmt.Errorf("unsupported WebP chunk %q", data[12:16])
+	}
+}
@@ -0,0 +1,77 @@
+package metadata
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/tokens"
+)
+
+func Test_CheckIcon(t *testing.T) {
+	encode := func(enc func(*bytes.Buffer, image.Image) error, w, h int) []byte {
+		buf := &bytes.Buffer{}
+		require.NoError(t, enc(buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+		return buf.Bytes()
+	}
+	pngEnc := func(b *bytes.Buffer, img image.Image) error { return png.Encode(b, img) }
+	jpegEnc := func(b *bytes.Buffer, img image.Image) error { return jpeg.Encode(b, img, nil) }
+	gifEnc := func(b *bytes.Buffer, img image.Image) error { return gif.Encode(b, img, nil) }
+
+	t.Run("valid", func(t *testing.T) {
+		for _, icon := range []*tokens.Icon{
+			{Type: "image/png", Data: encode(pngEnc, 32, 16)},
+			{Type: "image/jpeg", Data: encode(jpegEnc, 32, 16)},
+			{Type: "image/gif", Data: encode(gifEnc, 32, 16)},
+			{Type: "image/webp", Data: webpVP8X(32, 16)},
+			{Type: "image/webp", Data: webpVP8L(32, 16)},
+			{Type: "image/webp", Data: webpVP8(32, 16)},
+		} {
+			require.NoError(t, CheckIcon(icon), icon.Type)
+			w, h, err := IconDimensions(icon)
+			require.NoError(t, err)
+			require.Equal(t, []int{32, 16}, []int{w, h}, icon.Type)
+		}
+		icon := &tokens.Icon{Type: "image/svg+xml", Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="32" height="32"></svg>`)}
+		require.NoError(t, CheckIcon(icon))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		require.EqualError(t, CheckIcon(nil), "icon is nil")
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/bmp", Data: []byte{1}}), `icon type "image/bmp" is not allowed`)
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/png"}), "icon data is empty")
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/png", Data: make([]byte, tokens.MaxIconDataLength+1)}), "icon is 65537 bytes, allowed 65536")
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/jpeg", Data: encode(pngEnc, 2, 2)}), `icon data is of type "image/png", declared "image/jpeg"`)
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/png", Data: encode(pngEnc, MaxIconDimension+1, 2)}), "icon is 1025x2 pixels, allowed 1024x1024")
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/webp", Data: webpVP8X(2, MaxIconDimension+1)}), "icon is 2x1025 pixels, allowed 1024x1024")
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/webp", Data: encode(pngEnc, 2, 2)}), "icon data is not WebP image")
+		require.EqualError(t, CheckIcon(&tokens.Icon{Type: "image/svg+xml", Data: []byte("<html></html>")}), "icon data is not SVG image")
+
+		data := encode(pngEnc, 2, 2)
+		require.ErrorContains(t, CheckIcon(&tokens.Icon{Type: "image/png", Data: data[:20]}), "decoding icon image")
+	})
+}
+
+func webpHeader(chunk string, payload []byte) []byte {
+	data := append([]byte("RIFF\x00\x00\x00\x00WEBP"+chunk+"\x00\x00\x00\x00"), payload...)
+	return append(data, make([]byte, 32)...)
+}
+
+func webpVP8X(w, h int) []byte {
+	w, h = w-1, h-1
+	return webpHeader("VP8X", []byte{0, 0, 0, 0, byte(w), byte(w >> 8), byte(w >> 16), byte(h), byte(h >> 8), byte(h >> 16)})
+}
+
+func webpVP8L(w, h int) []byte {
+	bits := uint32(w-1) | uint32(h-1)<<14
+	return webpHeader("VP8L", []byte{0x2f, byte(bits), byte(bits >> 8), byte(bits >> 16), byte(bits >> 24)})
+}
+
+func webpVP8(w, h int) []byte {
+	return webpHeader("VP8 ", []byte{0, 0, 0, 0x9d, 0x01, 0x2a, byte(w), byte(w >> 8), byte(h), byte(h >> 8)})
+}
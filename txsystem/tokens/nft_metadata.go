@@ -0,0 +1,343 @@
+package tokens
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+/*
+Limits of the NFT metadata standard. These are not enforced by the tokens partition,
+wallets and marketplaces use them (see MintNonFungibleTokenAttributes.ValidateMetadata)
+to make sure the tokens they create and display have consistent metadata.
+Length of the token name, URI, data and icon are limited by the limits of the
+attribute fields (MaxNameLength, MaxURILength, MaxDataLength, MaxIconDataLength).
+*/
+const (
+	MaxNFTDescriptionLength   = 4096 // max length of the metadata description in bytes
+	MaxNFTAttributes          = 100  // max number of attributes in the metadata
+	MaxNFTAttributeLength     = 256  // max length of the attribute trait type and value in bytes
+	MaxNFTMedia               = 16   // max number of media items in the metadata
+	nftMetadataContentHashLen = sha256.Size
+)
+
+var (
+	// NFTURISchemes are the URI schemes allowed by the NFT metadata standard.
+	NFTURISchemes = []string{"https", "ipfs", "ar"}
+
+	// IconTypes are the MIME types of the icons allowed by the NFT metadata standard.
+	IconTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "image/svg+xml"}
+
+	ErrNotNFTMetadata = errors.New("data is not NFT metadata")
+)
+
+type (
+	// NFTMetadata is the metadata of the non-fungible token, stored in the Data field
+	// of the token, encoded either as CBOR (see EncodeNFTMetadata) or as JSON object.
+	// Hashes in the metadata are SHA-256 hashes of the content of the resource.
+	NFTMetadata struct {
+		_           struct{}        `cbor:",toarray"`
+		Version     types.ABVersion `json:"version"`
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Attributes  []*NFTAttribute `json:"attributes,omitempty"`
+		Media       []*NFTMedia     `json:"media,omitempty"`
+		// hash of the content of the resource the token URI refers to, binds the
+		// content to the token so that it can be verified (see VerifyURIContent)
+		URIHash hex.Bytes `json:"uriHash,omitempty"`
+	}
+
+	// NFTAttribute is a trait of the token, ie "color": "red".
+	NFTAttribute struct {
+		_         struct{} `cbor:",toarray"`
+		TraitType string   `json:"traitType"`
+		Value     string   `json:"value"`
+	}
+
+	// NFTMedia is a media file (image, video, 3D model...) associated with the token.
+	NFTMedia struct {
+		_    struct{}  `cbor:",toarray"`
+		URI  string    `json:"uri"`
+		Type string    `json:"type"` // the MIME content type of the media
+		Hash hex.Bytes `json:"hash"` // hash of the content of the media
+	}
+)
+
+/*
+ParseNFTMetadata decodes the token data as NFT metadata. Data starting with "{"
+is decoded as JSON, otherwise as CBOR. ErrNotNFTMetadata is returned when data
+can't be decoded, the metadata is not validated (see NFTMetadata.IsValid).
+*/
+func ParseNFTMetadata(data []byte) (*NFTMetadata, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: data is empty", ErrNotNFTMetadata)
+	}
+	md := &NFTMetadata{}
+	if data[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(md); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotNFTMetadata, err)
+		}
+		if dec.More() {
+			return nil, fmt.Errorf("%w: unexpected data after the JSON object", ErrNotNFTMetadata)
+		}
+		return md, nil
+	}
+	if err := cbor.Unmarshal(data, md); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotNFTMetadata, err)
+	}
+	return md, nil
+}
+
+// EncodeNFTMetadata returns CBOR encoding of the metadata to be used as token data.
+func EncodeNFTMetadata(md *NFTMetadata) ([]byte, error) {
+	if err := md.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	return cbor.Marshal(md)
+}
+
+func (md *NFTMetadata) GetVersion() types.ABVersion {
+	if md != nil && md.Version != 0 {
+		return md.Version
+	}
+	return 1
+}
+
+func (md *NFTMetadata) MarshalCBOR() ([]byte, error) {
+	type alias NFTMetadata
+	if md.Version == 0 {
+		md.Version = md.GetVersion()
+	}
+	return cbor.Marshal((*alias)(md))
+}
+
+func (md *NFTMetadata) UnmarshalCBOR(data []byte) error {
+	type alias NFTMetadata
+	if err := cbor.Unmarshal(data, (*alias)(md)); err != nil {
+		return err
+	}
+	return types.EnsureVersion(md, md.Version, 1)
+}
+
+func (md *NFTMetadata) IsValid() error {
+	if md == nil {
+		return errors.New("metadata is nil")
+	}
+	if md.GetVersion() != 1 {
+		return fmt.Errorf("invalid version (type %T)", md)
+	}
+	if err := validateNFTText("name", md.Name, MaxNameLength); err != nil {
+		return err
+	}
+	if md.Name == "" {
+		return errors.New("name is required")
+	}
+	if err := validateNFTText("description", md.Description, MaxNFTDescriptionLength); err != nil {
+		return err
+	}
+	if len(md.Attributes) > MaxNFTAttributes {
+		return fmt.Errorf("metadata has %d attributes, allowed %d", len(md.Attributes), MaxNFTAttributes)
+	}
+	for i, a := range md.Attributes {
+		if err := a.IsValid(); err != nil {
+			return fmt.Errorf("attribute %d: %w", i, err)
+		}
+		if slices.ContainsFunc(md.Attributes[:i], func(x *NFTAttribute) bool { return x.TraitType == a.TraitType }) {
+			return fmt.Errorf("attribute %d: duplicate trait type %q", i, a.TraitType)
+		}
+	}
+	if len(md.Media) > MaxNFTMedia {
+		return fmt.Errorf("metadata has %d media items, allowed %d", len(md.Media), MaxNFTMedia)
+	}
+	for i, m := range md.Media {
+		if err := m.IsValid(); err != nil {
+			return fmt.Errorf("media %d: %w", i, err)
+		}
+	}
+	if md.URIHash != nil && len(md.URIHash) != nftMetadataContentHashLen {
+		return fmt.Errorf("invalid URI hash length %d, expected %d", len(md.URIHash), nftMetadataContentHashLen)
+	}
+	return nil
+}
+
+/*
+VerifyURIContent checks that the "content" (the resource the token URI refers to)
+matches the URI hash of the metadata.
+*/
+func (md *NFTMetadata) VerifyURIContent(content []byte) error {
+	if len(md.URIHash) == 0 {
+		return errors.New("metadata does not contain URI hash")
+	}
+	return verifyContentHash(md.URIHash, content)
+}
+
+func (a *NFTAttribute) IsValid() error {
+	if a == nil {
+		return errors.New("attribute is nil")
+	}
+	if a.TraitType == "" {
+		return errors.New("trait type is required")
+	}
+	if err := validateNFTText("trait type", a.TraitType, MaxNFTAttributeLength); err != nil {
+		return err
+	}
+	return validateNFTText("value", a.Value, MaxNFTAttributeLength)
+}
+
+func (m *NFTMedia) IsValid() error {
+	if m == nil {
+		return errors.New("media is nil")
+	}
+	if err := ValidateNFTURI(m.URI); err != nil {
+		return err
+	}
+	if m.URI == "" {
+		return errors.New("URI is required")
+	}
+	if err := validateMIMEType(m.Type); err != nil {
+		return err
+	}
+	if len(m.Hash) != nftMetadataContentHashLen {
+		return fmt.Errorf("invalid hash length %d, expected %d", len(m.Hash), nftMetadataContentHashLen)
+	}
+	return nil
+}
+
+// VerifyContent checks that the "content" (the resource the media URI refers to)
+// matches the hash of the media.
+func (m *NFTMedia) VerifyContent(content []byte) error {
+	return verifyContentHash(m.Hash, content)
+}
+
+/*
+ValidateMetadata checks that the attributes follow the NFT metadata standard:
+length limits of the fields, allowed URI scheme and, when data is present, that
+it is valid NFT metadata.
+*/
+func (a *MintNonFungibleTokenAttributes) ValidateMetadata() error {
+	if err := validateNFTText("name", a.Name, MaxNameLength); err != nil {
+		return err
+	}
+	if err := ValidateNFTURI(a.URI); err != nil {
+		return err
+	}
+	md, err := validateNFTData(a.Data)
+	if err != nil {
+		return err
+	}
+	if md != nil && len(md.URIHash) != 0 && a.URI == "" {
+		return errors.New("metadata contains URI hash but token has no URI")
+	}
+	return nil
+}
+
+/*
+ValidateMetadata checks that the new data of the token follows the NFT metadata
+standard, see MintNonFungibleTokenAttributes.ValidateMetadata.
+*/
+func (a *UpdateNonFungibleTokenAttributes) ValidateMetadata() error {
+	_, err := validateNFTData(a.Data)
+	return err
+}
+
+/*
+ValidateNFTURI checks the length and scheme (see NFTURISchemes) of the URI.
+Empty URI is valid as the URI of the token is optional.
+*/
+func ValidateNFTURI(uri string) error {
+	if uri == "" {
+		return nil
+	}
+	if len(uri) > MaxURILength {
+		return fmt.Errorf("URI is %d bytes long, allowed %d", len(uri), MaxURILength)
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid URI: %w", err)
+	}
+	if !slices.Contains(NFTURISchemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("URI scheme %q is not allowed", u.Scheme)
+	}
+	if u.Host == "" && u.Opaque == "" {
+		return errors.New("URI must have host")
+	}
+	return nil
+}
+
+/*
+CheckStandard checks that the icon follows the NFT metadata standard: MIME type is
+one of IconTypes and the data is within the size limit. This is a wallet and
+marketplace policy, not a validity rule of the tokens partition. Content of the
+data (ie that it is image of the declared type and within the dimension limits)
+is checked by the metadata.CheckIcon.
+*/
+func (i *Icon) CheckStandard() error {
+	if i == nil {
+		return errors.New("icon is nil")
+	}
+	if !slices.Contains(IconTypes, i.Type) {
+		return fmt.Errorf("icon type %q is not allowed", i.Type)
+	}
+	if len(i.Data) == 0 {
+		return errors.New("icon data is empty")
+	}
+	if len(i.Data) > MaxIconDataLength {
+		return fmt.Errorf("icon is %d bytes, allowed %d", len(i.Data), MaxIconDataLength)
+	}
+	return nil
+}
+
+func validateNFTData(data []byte) (*NFTMetadata, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if len(data) > MaxDataLength {
+		return nil, fmt.Errorf("data is %d bytes, allowed %d", len(data), MaxDataLength)
+	}
+	md, err := ParseNFTMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := md.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	return md, nil
+}
+
+func validateNFTText(field, value string, maxLen int) error {
+	if len(value) > maxLen {
+		return fmt.Errorf("%s is %d bytes long, allowed %d", field, len(value), maxLen)
+	}
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%s is not valid UTF-8", field)
+	}
+	return nil
+}
+
+func validateMIMEType(mimeType string) error {
+	typ, subtype, ok := strings.Cut(mimeType, "/")
+	if !ok || typ == "" || subtype == "" || strings.ContainsAny(mimeType, " \t;") {
+		return fmt.Errorf("invalid MIME type %q", mimeType)
+	}
+	return nil
+}
+
+func verifyContentHash(hash, content []byte) error {
+	h := sha256.Sum256(content)
+	if !bytes.Equal(hash, h[:]) {
+		return fmt.Errorf("content hash %X does not match %X", h[:], hash)
+	}
+	return nil
+}
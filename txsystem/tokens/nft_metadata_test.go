@@ -0,0 +1,168 @@
+package tokens
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+)
+
+func validNFTMetadata() *NFTMetadata {
+	content := sha256.Sum256([]byte("content"))
+	media := sha256.Sum256([]byte("media"))
+	return &NFTMetadata{
+		Name:        "Test NFT",
+		Description: "test token",
+		Attributes:  []*NFTAttribute{{TraitType: "color", Value: "red"}, {TraitType: "size", Value: "XL"}},
+		Media:       []*NFTMedia{{URI: "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi", Type: "video/mp4", Hash: media[:]}},
+		URIHash:     content[:],
+	}
+}
+
+func Test_NFTMetadata_encoding(t *testing.T) {
+	md := validNFTMetadata()
+
+	t.Run("CBOR", func(t *testing.T) {
+		data, err := EncodeNFTMetadata(md)
+		require.NoError(t, err)
+		md2, err := ParseNFTMetadata(data)
+		require.NoError(t, err)
+		require.NoError(t, cbor.VerifyCanonical(data, md2))
+		require.EqualValues(t, 1, md2.Version)
+		md2.Version = md.Version
+		require.Equal(t, md, md2)
+
+		_, err = EncodeNFTMetadata(&NFTMetadata{})
+		require.EqualError(t, err, "invalid metadata: name is required")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		md2, err := ParseNFTMetadata([]byte(`{"name":"Test NFT","description":"test token","attributes":[{"traitType":"color","value":"red"},{"traitType":"size","value":"XL"}],` +
+			`"media":[{"uri":"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi","type":"video/mp4","hash":"0x` + strings.ToUpper(hexStr(md.Media[0].Hash)) + `"}],` +
+			`"uriHash":"0x` + hexStr(md.URIHash) + `"}`))
+		require.NoError(t, err)
+		require.NoError(t, md2.IsValid())
+		md2.Version = md.Version
+		require.Equal(t, md, md2)
+	})
+
+	t.Run("not metadata", func(t *testing.T) {
+		for _, data := range [][]byte{nil, {1, 2, 3}, []byte(`{"name":"x","foo":1}`), []byte(`{"name":"x"} {}`), []byte("free form data")} {
+			_, err := ParseNFTMetadata(data)
+			require.ErrorIs(t, err, ErrNotNFTMetadata, "data %q", data)
+		}
+	})
+}
+
+func hexStr(b []byte) string {
+	const digits = "0123456789abcdef"
+	var sb strings.Builder
+	for _, c := range b {
+		sb.WriteByte(digits[c>>4])
+		sb.WriteByte(digits[c&0xf])
+	}
+	return sb.String()
+}
+
+func Test_NFTMetadata_IsValid(t *testing.T) {
+	require.NoError(t, validNFTMetadata().IsValid())
+	require.NoError(t, (&NFTMetadata{Name: "x"}).IsValid())
+
+	var testCases = []struct {
+		modify func(md *NFTMetadata)
+		errMsg string
+	}{
+		{func(md *NFTMetadata) { md.Version = 2 }, "invalid version (type *tokens.NFTMetadata)"},
+		{func(md *NFTMetadata) { md.Name = "" }, "name is required"},
+		{func(md *NFTMetadata) { md.Name = strings.Repeat("x", MaxNameLength+1) }, "name is 257 bytes long, allowed 256"},
+		{func(md *NFTMetadata) { md.Name = "\xff" }, "name is not valid UTF-8"},
+		{func(md *NFTMetadata) { md.Description = strings.Repeat("x", MaxNFTDescriptionLength+1) }, "description is 4097 bytes long, allowed 4096"},
+		{func(md *NFTMetadata) { md.Attributes = make([]*NFTAttribute, MaxNFTAttributes+1) }, "metadata has 101 attributes, allowed 100"},
+		{func(md *NFTMetadata) { md.Attributes[1] = nil }, "attribute 1: attribute is nil"},
+		{func(md *NFTMetadata) { md.Attributes[1].TraitType = "" }, "attribute 1: trait type is required"},
+		{func(md *NFTMetadata) { md.Attributes[1].TraitType = "color" }, `attribute 1: duplicate trait type "color"`},
+		{func(md *NFTMetadata) { md.Attributes[0].Value = strings.Repeat("x", 257) }, "attribute 0: value is 257 bytes long, allowed 256"},
+		{func(md *NFTMetadata) { md.Media = make([]*NFTMedia, MaxNFTMedia+1) }, "metadata has 17 media items, allowed 16"},
+		{func(md *NFTMetadata) { md.Media[0] = nil }, "media 0: media is nil"},
+		{func(md *NFTMetadata) { md.Media[0].URI = "" }, "media 0: URI is required"},
+		{func(md *NFTMetadata) { md.Media[0].URI = "ftp://host/file" }, `media 0: URI scheme "ftp" is not allowed`},
+		{func(md *NFTMetadata) { md.Media[0].Type = "video" }, `media 0: invalid MIME type "video"`},
+		{func(md *NFTMetadata) { md.Media[0].Hash = []byte{1} }, "media 0: invalid hash length 1, expected 32"},
+		{func(md *NFTMetadata) { md.URIHash = []byte{1} }, "invalid URI hash length 1, expected 32"},
+	}
+	for _, tc := range testCases {
+		md := validNFTMetadata()
+		tc.modify(md)
+		require.EqualError(t, md.IsValid(), tc.errMsg)
+	}
+	require.EqualError(t, (*NFTMetadata)(nil).IsValid(), "metadata is nil")
+}
+
+func Test_NFTMetadata_content_hash(t *testing.T) {
+	md := validNFTMetadata()
+	require.NoError(t, md.VerifyURIContent([]byte("content")))
+	require.ErrorContains(t, md.VerifyURIContent([]byte("other")), "does not match")
+	require.NoError(t, md.Media[0].VerifyContent([]byte("media")))
+	require.ErrorContains(t, md.Media[0].VerifyContent([]byte("content")), "does not match")
+
+	md.URIHash = nil
+	require.EqualError(t, md.VerifyURIContent([]byte("content")), "metadata does not contain URI hash")
+}
+
+func Test_ValidateNFTURI(t *testing.T) {
+	for _, uri := range []string{"", "https://example.com/nft/1.json", "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/1.json", "ar://tx-id", "HTTPS://example.com"} {
+		require.NoError(t, ValidateNFTURI(uri), uri)
+	}
+	require.EqualError(t, ValidateNFTURI("http://example.com"), `URI scheme "http" is not allowed`)
+	require.EqualError(t, ValidateNFTURI("example.com/file"), `URI scheme "" is not allowed`)
+	require.EqualError(t, ValidateNFTURI("https:///file"), "URI must have host")
+	require.ErrorContains(t, ValidateNFTURI("https://exa mple.com"), "invalid URI")
+	require.EqualError(t, ValidateNFTURI("https://example.com/"+strings.Repeat("x", MaxURILength)), "URI is 4116 bytes long, allowed 4096")
+}
+
+func Test_NFTAttributes_ValidateMetadata(t *testing.T) {
+	data, err := EncodeNFTMetadata(validNFTMetadata())
+	require.NoError(t, err)
+
+	t.Run("mint", func(t *testing.T) {
+		attr := &MintNonFungibleTokenAttributes{Name: "nft", URI: "https://example.com/nft", Data: data}
+		require.NoError(t, attr.ValidateMetadata())
+		// URI and data are optional
+		require.NoError(t, (&MintNonFungibleTokenAttributes{Name: "nft"}).ValidateMetadata())
+
+		attr.Name = strings.Repeat("x", MaxNameLength+1)
+		require.EqualError(t, attr.ValidateMetadata(), "name is 257 bytes long, allowed 256")
+
+		attr = &MintNonFungibleTokenAttributes{Name: "nft", URI: "file:///etc/passwd"}
+		require.EqualError(t, attr.ValidateMetadata(), `URI scheme "file" is not allowed`)
+
+		attr = &MintNonFungibleTokenAttributes{Name: "nft", Data: data}
+		require.EqualError(t, attr.ValidateMetadata(), "metadata contains URI hash but token has no URI")
+
+		attr = &MintNonFungibleTokenAttributes{Name: "nft", Data: []byte("free form")}
+		require.ErrorIs(t, attr.ValidateMetadata(), ErrNotNFTMetadata)
+
+		attr = &MintNonFungibleTokenAttributes{Name: "nft", Data: []byte(`{"name":""}`)}
+		require.EqualError(t, attr.ValidateMetadata(), "invalid metadata: name is required")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		require.NoError(t, (&UpdateNonFungibleTokenAttributes{Data: data}).ValidateMetadata())
+		require.NoError(t, (&UpdateNonFungibleTokenAttributes{}).ValidateMetadata())
+		require.EqualError(t, (&UpdateNonFungibleTokenAttributes{Data: make([]byte, MaxDataLength+1)}).ValidateMetadata(), "data is 65537 bytes, allowed 65536")
+		require.ErrorIs(t, (&UpdateNonFungibleTokenAttributes{Data: []byte{0}}).ValidateMetadata(), ErrNotNFTMetadata)
+	})
+}
+
+func Test_Icon_CheckStandard(t *testing.T) {
+	require.NoError(t, (&Icon{Type: "image/png", Data: []byte{1}}).CheckStandard())
+	require.NoError(t, (&Icon{Type: "image/svg+xml", Data: []byte("<svg/>")}).CheckStandard())
+
+	require.EqualError(t, (*Icon)(nil).CheckStandard(), "icon is nil")
+	require.EqualError(t, (&Icon{Type: "image/bmp", Data: []byte{1}}).CheckStandard(), `icon type "image/bmp" is not allowed`)
+	require.EqualError(t, (&Icon{Type: "image/png"}).CheckStandard(), "icon data is empty")
+	require.EqualError(t, (&Icon{Type: "image/png", Data: make([]byte, MaxIconDataLength+1)}).CheckStandard(), "icon is 65537 bytes, allowed 65536")
+}
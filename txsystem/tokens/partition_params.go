@@ -0,0 +1,83 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+/*
+Keys of the tokens partition parameters in the PartitionDescriptionRecord.PartitionParams,
+ie in the "partitionParams" object of the shard configuration JSON. The keys are
+defined here, tools generating the shard configuration must use these constants.
+*/
+const (
+	ParamAdminOwnerPredicate = "adminOwnerPredicate"
+	ParamFeelessMode         = "feelessMode"
+)
+
+// PartitionParams are the tokens partition specific parameters of the shard configuration.
+type PartitionParams struct {
+	// when set the partition is in permissioned mode, ie fee credit records are
+	// managed by the admin (see the permissioned package)
+	AdminOwnerPredicate hex.Bytes
+	// transactions are not charged for, allowed only in permissioned mode
+	FeelessMode bool
+}
+
+/*
+ParsePartitionParams decodes and validates the tokens partition parameters of
+the shard configuration "pdr" (see PartitionDescriptionRecord.PartitionParams).
+*/
+func ParsePartitionParams(pdr *types.PartitionDescriptionRecord) (*PartitionParams, error) {
+	if pdr == nil {
+		return nil, types.ErrSystemDescriptionIsNil
+	}
+	if pdr.PartitionTypeID != PartitionTypeID {
+		return nil, fmt.Errorf("expected tokens partition type %d, got %d", PartitionTypeID, pdr.PartitionTypeID)
+	}
+	p := &PartitionParams{}
+	d := types.NewParamsDecoder(pdr.PartitionParams)
+	d.Bytes(ParamAdminOwnerPredicate, &p.AdminOwnerPredicate)
+	d.Bool(ParamFeelessMode, &p.FeelessMode)
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+	if err := p.IsValid(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+/*
+Encode returns the parameters as PartitionDescriptionRecord.PartitionParams map,
+parameters with zero value are omitted.
+*/
+func (p *PartitionParams) Encode() map[string]string {
+	m := map[string]string{}
+	if len(p.AdminOwnerPredicate) != 0 {
+		m[ParamAdminOwnerPredicate] = string(hex.Encode(p.AdminOwnerPredicate))
+	}
+	if p.FeelessMode {
+		m[ParamFeelessMode] = strconv.FormatBool(p.FeelessMode)
+	}
+	return m
+}
+
+// Permissioned returns true when the partition is in permissioned mode.
+func (p *PartitionParams) Permissioned() bool {
+	return len(p.AdminOwnerPredicate) != 0
+}
+
+func (p *PartitionParams) IsValid() error {
+	if p == nil {
+		return errors.New("partition params are nil")
+	}
+	if p.FeelessMode && !p.Permissioned() {
+		return errors.New("feeless mode requires admin owner predicate (permissioned mode)")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_PartitionParams(t *testing.T) {
+	parse := func(params map[string]string) (*PartitionParams, error) {
+		return ParsePartitionParams(&types.PartitionDescriptionRecord{PartitionTypeID: PartitionTypeID, PartitionParams: params})
+	}
+	t.Run("encode and parse", func(t *testing.T) {
+		p := &PartitionParams{AdminOwnerPredicate: []byte{1, 2}, FeelessMode: true}
+		require.True(t, p.Permissioned())
+		m := p.Encode()
+		require.Equal(t, map[string]string{"adminOwnerPredicate": "0x0102", "feelessMode": "true"}, m)
+		p2, err := parse(m)
+		require.NoError(t, err)
+		require.Equal(t, p, p2)
+
+		p2, err = parse(nil)
+		require.NoError(t, err)
+		require.Equal(t, &PartitionParams{}, p2)
+		require.False(t, p2.Permissioned())
+		require.Empty(t, p2.Encode())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		p, err := parse(map[string]string{"feelessMode": "true"})
+		require.EqualError(t, err, "feeless mode requires admin owner predicate (permissioned mode)")
+		require.Nil(t, p)
+
+		p, err = parse(map[string]string{"feelessMode": "on"})
+		require.EqualError(t, err, `invalid value of the partition parameter "feelessMode": strconv.ParseBool: parsing "on": invalid syntax`)
+		require.Nil(t, p)
+
+		p, err = parse(map[string]string{"adminKey": "0x01"})
+		require.EqualError(t, err, `unknown partition parameter "adminKey" (known parameters are ["adminOwnerPredicate" "feelessMode"])`)
+		require.Nil(t, p)
+
+		var nilParams *PartitionParams
+		require.EqualError(t, nilParams.IsValid(), "partition params are nil")
+	})
+
+	t.Run("PDR", func(t *testing.T) {
+		pdr := &types.PartitionDescriptionRecord{
+			Version:         1,
+			NetworkID:       5,
+			PartitionID:     DefaultPartitionID,
+			PartitionTypeID: PartitionTypeID,
+			TypeIDLen:       8,
+			UnitIDLen:       256,
+			T2Timeout:       2500 * time.Millisecond,
+			PartitionParams: map[string]string{"feelessMode": "true"},
+		}
+		// IsValid doesn't check the partition params, IsValidWith does
+		require.NoError(t, pdr.IsValid())
+		require.EqualError(t, pdr.IsValidWith(types.ParamsValidator(ParsePartitionParams)), "invalid partition parameters: feeless mode requires admin owner predicate (permissioned mode)")
+		_, err := ParsePartitionParams(pdr)
+		require.EqualError(t, err, "feeless mode requires admin owner predicate (permissioned mode)")
+
+		pdr.PartitionParams["adminOwnerPredicate"] = "0x01"
+		p, err := ParsePartitionParams(pdr)
+		require.NoError(t, err)
+		require.True(t, p.FeelessMode)
+
+		pdr.PartitionTypeID = PartitionTypeID + 1
+		_, err = ParsePartitionParams(pdr)
+		require.EqualError(t, err, "expected tokens partition type 2, got 3")
+
+		_, err = ParsePartitionParams(nil)
+		require.ErrorIs(t, err, types.ErrSystemDescriptionIsNil)
+	})
+}
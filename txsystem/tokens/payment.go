@@ -0,0 +1,58 @@
+package tokens
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/coinselect"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+/*
+PlanPayment selects fungible tokens of type "typeID" to pay "params.Amount" to the
+"receiver" (owner predicate) and returns the transaction orders (without auth proofs)
+to execute the payment: transfer orders for the tokens which are spent whole and split
+order for the token which leaves change to the owner. Tokens of other types are ignored.
+
+The "txTemplate" must have NetworkID, PartitionID and ClientMetadata fields
+assigned, these are copied into all the transaction orders created.
+*/
+func PlanPayment(tokens []FungibleToken, typeID types.UnitID, receiver []byte, params coinselect.Params, txTemplate types.Payload) ([]*types.TransactionOrder, error) {
+	coins := make([]coinselect.Coin, 0, len(tokens))
+	byID := make(map[string]FungibleToken, len(tokens))
+	for i, t := range tokens {
+		if len(t.ID) == 0 || t.Data == nil {
+			return nil, fmt.Errorf("token %d is not assigned", i)
+		}
+		if !bytes.Equal(t.Data.TypeID, typeID) {
+			continue
+		}
+		coins = append(coins, coinselect.Coin{ID: t.ID, Value: t.Data.Value})
+		byID[string(t.ID)] = t
+	}
+	sel, err := coinselect.Select(coins, params, txTemplate.ClientMetadata.GetMaxFee())
+	if err != nil {
+		return nil, fmt.Errorf("selecting tokens: %w", err)
+	}
+
+	txs := make([]*types.TransactionOrder, 0, sel.TxCount())
+	for _, c := range sel.Transfer {
+		t := byID[string(c.ID)]
+		attr := &TransferFungibleTokenAttributes{TypeID: typeID, Value: t.Data.Value, NewOwnerPredicate: receiver, Counter: t.Data.Counter}
+		txo, err := types.NewTransactionOrder(txTemplate, t.ID, TransactionTypeTransferFT, attr)
+		if err != nil {
+			return nil, fmt.Errorf("creating transfer order for token %s: %w", t.ID, err)
+		}
+		txs = append(txs, txo)
+	}
+	if sel.Split != nil {
+		t := byID[string(sel.Split.ID)]
+		attr := &SplitFungibleTokenAttributes{TypeID: typeID, TargetValue: t.Data.Value - sel.Change, NewOwnerPredicate: receiver, Counter: t.Data.Counter}
+		txo, err := types.NewTransactionOrder(txTemplate, t.ID, TransactionTypeSplitFT, attr)
+		if err != nil {
+			return nil, fmt.Errorf("creating split order for token %s: %w", t.ID, err)
+		}
+		txs = append(txs, txo)
+	}
+	return txs, nil
+}
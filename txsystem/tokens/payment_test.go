@@ -0,0 +1,58 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/txsystem/coinselect"
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_PlanPayment(t *testing.T) {
+	txTemplate := types.Payload{
+		NetworkID:      3,
+		PartitionID:    DefaultPartitionID,
+		ClientMetadata: &types.ClientMetadata{Timeout: 10, MaxTransactionFee: 2, FeeCreditRecordID: []byte{0xf}},
+	}
+	tokens := []FungibleToken{newTestToken(1, 1, 10, 1), newTestToken(2, 2, 500, 2), newTestToken(1, 3, 50, 3), newTestToken(1, 4, 5, 4)}
+	typeID := tokens[0].Data.TypeID
+	receiver := []byte{0x53, 0x51}
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := PlanPayment([]FungibleToken{tokens[0], {}}, typeID, receiver, coinselect.Params{Amount: 1}, txTemplate)
+		require.EqualError(t, err, "token 1 is not assigned")
+
+		// tokens of other type are not used
+		_, err = PlanPayment(tokens, typeID, receiver, coinselect.Params{Amount: 100}, txTemplate)
+		require.ErrorIs(t, err, coinselect.ErrInsufficientFunds)
+		require.ErrorContains(t, err, "available 65")
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		txs, err := PlanPayment(tokens, typeID, receiver, coinselect.Params{Amount: 50}, txTemplate)
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		require.Equal(t, tokens[2].ID, txs[0].UnitID)
+		require.Equal(t, TransactionTypeTransferFT, txs[0].Type)
+		require.Equal(t, txTemplate.ClientMetadata, txs[0].ClientMetadata)
+		attr := &TransferFungibleTokenAttributes{}
+		require.NoError(t, txs[0].UnmarshalAttributes(attr))
+		require.Equal(t, &TransferFungibleTokenAttributes{TypeID: typeID, Value: 50, NewOwnerPredicate: receiver, Counter: 3}, attr)
+	})
+
+	t.Run("transfer and split", func(t *testing.T) {
+		txs, err := PlanPayment(tokens, typeID, receiver, coinselect.Params{Amount: 58}, txTemplate)
+		require.NoError(t, err)
+		require.Len(t, txs, 2)
+
+		require.Equal(t, tokens[2].ID, txs[0].UnitID)
+		require.Equal(t, TransactionTypeTransferFT, txs[0].Type)
+
+		require.Equal(t, tokens[0].ID, txs[1].UnitID)
+		require.Equal(t, TransactionTypeSplitFT, txs[1].Type)
+		split := &SplitFungibleTokenAttributes{}
+		require.NoError(t, txs[1].UnmarshalAttributes(split))
+		require.Equal(t, &SplitFungibleTokenAttributes{TypeID: typeID, TargetValue: 8, NewOwnerPredicate: receiver, Counter: 1}, split)
+	})
+}
@@ -0,0 +1,226 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+// DefaultMaxTypeHierarchyDepth is the max number of types in the type chain
+// TypeHierarchy walks when not configured otherwise.
+const DefaultMaxTypeHierarchyDepth = 100
+
+var ErrTypeHierarchyCycle = errors.New("token type hierarchy contains a cycle")
+
+type (
+	// TokenTypeLookupFunc returns the unit data of the token type "typeID", the
+	// data must be either *FungibleTokenTypeData or *NonFungibleTokenTypeData.
+	TokenTypeLookupFunc func(typeID types.UnitID) (types.UnitData, error)
+
+	// ProofFunc returns the input to satisfy the "predicate".
+	ProofFunc func(predicate []byte) ([]byte, error)
+
+	// TokenType is a token type unit, ie unit ID and its data.
+	TokenType struct {
+		ID   types.UnitID
+		Data types.UnitData // *FungibleTokenTypeData or *NonFungibleTokenTypeData
+	}
+
+	// TypeHierarchy resolves the token type chains (type and its ancestors linked
+	// by ParentTypeID) and the predicates inherited from the types which transactions
+	// must satisfy, in the order the proofs must be given in the auth proof of the
+	// transaction.
+	TypeHierarchy struct {
+		lookup   TokenTypeLookupFunc
+		maxDepth int
+	}
+)
+
+/*
+NewTypeHierarchy returns type hierarchy resolver which uses "lookup" to load the
+types. When "maxDepth" is not greater than zero DefaultMaxTypeHierarchyDepth is used.
+*/
+func NewTypeHierarchy(lookup TokenTypeLookupFunc, maxDepth int) *TypeHierarchy {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxTypeHierarchyDepth
+	}
+	return &TypeHierarchy{lookup: lookup, maxDepth: maxDepth}
+}
+
+/*
+Chain returns the type "typeID" followed by its ancestors, ie the first item is the
+type itself and the last item is the root type of the hierarchy. When "typeID" is
+empty the chain is empty. All the types in the chain must be of the same kind
+(either fungible or non-fungible).
+*/
+func (h *TypeHierarchy) Chain(typeID types.UnitID) ([]TokenType, error) {
+	var chain []TokenType
+	seen := map[string]struct{}{}
+	for id := typeID; len(id) != 0; {
+		if _, ok := seen[string(id)]; ok {
+			return nil, fmt.Errorf("%w: type %s is its own ancestor", ErrTypeHierarchyCycle, id)
+		}
+		if len(chain) == h.maxDepth {
+			return nil, fmt.Errorf("type hierarchy of %s is deeper than %d types", typeID, h.maxDepth)
+		}
+		seen[string(id)] = struct{}{}
+
+		data, err := h.lookup(id)
+		if err != nil {
+			return nil, fmt.Errorf("loading token type %s: %w", id, err)
+		}
+		var parentID types.UnitID
+		switch d := data.(type) {
+		case *FungibleTokenTypeData:
+			if d == nil {
+				return nil, fmt.Errorf("token type %s not found", id)
+			}
+			parentID = d.ParentTypeID
+		case *NonFungibleTokenTypeData:
+			if d == nil {
+				return nil, fmt.Errorf("token type %s not found", id)
+			}
+			parentID = d.ParentTypeID
+		default:
+			return nil, fmt.Errorf("unit %s is not a token type: %T", id, data)
+		}
+		if len(chain) > 0 && !sameTypeKind(chain[0].Data, data) {
+			return nil, fmt.Errorf("token type %s has ancestor %s of different kind", typeID, id)
+		}
+		chain = append(chain, TokenType{ID: id, Data: data})
+		id = parentID
+	}
+	return chain, nil
+}
+
+/*
+RequiredPredicates returns the predicates inherited from the types which transaction
+of type "txType" must satisfy, in the order the proofs must be given in the auth proof
+of the transaction. The "typeID" is
+  - for the define type transactions the parent type of the new type (the result
+    are the SubTypeCreationPredicate-s of all the ancestors of the new type);
+  - for the mint transactions the type of the new token (the result is the
+    TokenMintingPredicate of the type);
+  - for all the other transactions the type of the token (the result are the
+    TokenTypeOwnerPredicate-s or, for the update transaction, the DataUpdatePredicate-s
+    of the type and all its ancestors).
+
+The predicates of the token itself (ie OwnerPredicate, DataUpdatePredicate) are not
+part of the result.
+*/
+func (h *TypeHierarchy) RequiredPredicates(txType uint16, typeID types.UnitID) ([][]byte, error) {
+	var nft bool
+	switch txType {
+	case TransactionTypeDefineFT, TransactionTypeMintFT, TransactionTypeTransferFT, TransactionTypeSplitFT, TransactionTypeBurnFT, TransactionTypeJoinFT:
+	case TransactionTypeDefineNFT, TransactionTypeMintNFT, TransactionTypeTransferNFT, TransactionTypeUpdateNFT:
+		nft = true
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", txType)
+	}
+	if len(typeID) == 0 && txType != TransactionTypeDefineFT && txType != TransactionTypeDefineNFT {
+		return nil, errors.New("token type ID is required")
+	}
+
+	chain, err := h.Chain(typeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) > 0 {
+		if _, ok := chain[0].Data.(*NonFungibleTokenTypeData); ok != nft {
+			return nil, fmt.Errorf("token type %s is of wrong kind for transaction type %d", typeID, txType)
+		}
+	}
+
+	var predicate func(t types.UnitData) []byte
+	switch txType {
+	case TransactionTypeDefineFT, TransactionTypeDefineNFT:
+		predicate = subTypeCreationPredicate
+	case TransactionTypeMintFT, TransactionTypeMintNFT:
+		// only the type of the token controls minting
+		return [][]byte{tokenMintingPredicate(chain[0].Data)}, nil
+	case TransactionTypeUpdateNFT:
+		predicate = func(t types.UnitData) []byte { return t.(*NonFungibleTokenTypeData).DataUpdatePredicate }
+	default:
+		predicate = tokenTypeOwnerPredicate
+	}
+	predicates := make([][]byte, len(chain))
+	for i, t := range chain {
+		predicates[i] = predicate(t.Data)
+	}
+	return predicates, nil
+}
+
+/*
+AuthProof creates the auth proof of the transaction of type "txType" (see RequiredPredicates
+for the meaning of the "typeID"). The "prove" callback is called for every predicate
+inherited from the types, in the order of the proofs in the auth proof. The "tokenProof"
+is the input to satisfy the predicate of the token itself (OwnerProof or TokenDataUpdateProof),
+it is ignored for the define and mint transactions.
+
+Returned value is pointer to the auth proof struct of the transaction type, ie
+*TransferFungibleTokenAuthProof for TransactionTypeTransferFT, which can be assigned
+to the transaction order using types.TransactionOrder.SetAuthProof.
+*/
+func (h *TypeHierarchy) AuthProof(txType uint16, typeID types.UnitID, tokenProof []byte, prove ProofFunc) (any, error) {
+	predicates, err := h.RequiredPredicates(txType, typeID)
+	if err != nil {
+		return nil, err
+	}
+	proofs := make([][]byte, len(predicates))
+	for i, p := range predicates {
+		if proofs[i], err = prove(p); err != nil {
+			return nil, fmt.Errorf("creating proof %d: %w", i, err)
+		}
+	}
+
+	switch txType {
+	case TransactionTypeDefineFT:
+		return &DefineFungibleTokenAuthProof{SubTypeCreationProofs: proofs}, nil
+	case TransactionTypeDefineNFT:
+		return &DefineNonFungibleTokenAuthProof{SubTypeCreationProofs: proofs}, nil
+	case TransactionTypeMintFT:
+		return &MintFungibleTokenAuthProof{TokenMintingProof: proofs[0]}, nil
+	case TransactionTypeMintNFT:
+		return &MintNonFungibleTokenAuthProof{TokenMintingProof: proofs[0]}, nil
+	case TransactionTypeTransferFT:
+		return &TransferFungibleTokenAuthProof{OwnerProof: tokenProof, TokenTypeOwnerProofs: proofs}, nil
+	case TransactionTypeSplitFT:
+		return &SplitFungibleTokenAuthProof{OwnerProof: tokenProof, TokenTypeOwnerProofs: proofs}, nil
+	case TransactionTypeBurnFT:
+		return &BurnFungibleTokenAuthProof{OwnerProof: tokenProof, TokenTypeOwnerProofs: proofs}, nil
+	case TransactionTypeJoinFT:
+		return &JoinFungibleTokenAuthProof{OwnerProof: tokenProof, TokenTypeOwnerProofs: proofs}, nil
+	case TransactionTypeTransferNFT:
+		return &TransferNonFungibleTokenAuthProof{OwnerProof: tokenProof, TokenTypeOwnerProofs: proofs}, nil
+	default: // TransactionTypeUpdateNFT, other types are rejected by RequiredPredicates
+		return &UpdateNonFungibleTokenAuthProof{TokenDataUpdateProof: tokenProof, TokenTypeDataUpdateProofs: proofs}, nil
+	}
+}
+
+func sameTypeKind(a, b types.UnitData) bool {
+	_, aNFT := a.(*NonFungibleTokenTypeData)
+	_, bNFT := b.(*NonFungibleTokenTypeData)
+	return aNFT == bNFT
+}
+
+func subTypeCreationPredicate(t types.UnitData) []byte {
+	if d, ok := t.(*FungibleTokenTypeData); ok {
+		return d.SubTypeCreationPredicate
+	}
+	return t.(*NonFungibleTokenTypeData).SubTypeCreationPredicate
+}
+
+func tokenMintingPredicate(t types.UnitData) []byte {
+	if d, ok := t.(*FungibleTokenTypeData); ok {
+		return d.TokenMintingPredicate
+	}
+	return t.(*NonFungibleTokenTypeData).TokenMintingPredicate
+}
+
+func tokenTypeOwnerPredicate(t types.UnitData) []byte {
+	if d, ok := t.(*FungibleTokenTypeData); ok {
+		return d.TokenTypeOwnerPredicate
+	}
+	return t.(*NonFungibleTokenTypeData).TokenTypeOwnerPredicate
+}
@@ -0,0 +1,184 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types"
+)
+
+func Test_TypeHierarchy(t *testing.T) {
+	ftID := func(id byte) types.UnitID { return types.UnitID{0, id, FungibleTokenTypeUnitType} }
+	nftID := func(id byte) types.UnitID { return types.UnitID{0, id, NonFungibleTokenTypeUnitType} }
+	ft := func(id, parent byte) *FungibleTokenTypeData {
+		d := &FungibleTokenTypeData{
+			SubTypeCreationPredicate: []byte{'s', id},
+			TokenMintingPredicate:    []byte{'m', id},
+			TokenTypeOwnerPredicate:  []byte{'o', id},
+		}
+		if parent != 0 {
+			d.ParentTypeID = ftID(parent)
+		}
+		return d
+	}
+	nft := func(id, parent byte) *NonFungibleTokenTypeData {
+		d := &NonFungibleTokenTypeData{
+			SubTypeCreationPredicate: []byte{'s', id},
+			TokenMintingPredicate:    []byte{'m', id},
+			TokenTypeOwnerPredicate:  []byte{'o', id},
+			DataUpdatePredicate:      []byte{'u', id},
+		}
+		if parent != 0 {
+			d.ParentTypeID = nftID(parent)
+		}
+		return d
+	}
+	// fungible: 3 -> 2 -> 1; non-fungible: 5 -> 4
+	units := map[string]types.UnitData{
+		string(ftID(1)):  ft(1, 0),
+		string(ftID(2)):  ft(2, 1),
+		string(ftID(3)):  ft(3, 2),
+		string(nftID(4)): nft(4, 0),
+		string(nftID(5)): nft(5, 4),
+	}
+	lookup := func(id types.UnitID) (types.UnitData, error) {
+		if d, ok := units[string(id)]; ok {
+			return d, nil
+		}
+		return nil, fmt.Errorf("unit %s not found", id)
+	}
+	h := NewTypeHierarchy(lookup, 0)
+
+	t.Run("chain", func(t *testing.T) {
+		chain, err := h.Chain(nil)
+		require.NoError(t, err)
+		require.Empty(t, chain)
+
+		chain, err = h.Chain(ftID(3))
+		require.NoError(t, err)
+		require.Equal(t, []TokenType{{ID: ftID(3), Data: units[string(ftID(3))]}, {ID: ftID(2), Data: units[string(ftID(2))]}, {ID: ftID(1), Data: units[string(ftID(1))]}}, chain)
+
+		_, err = h.Chain(ftID(9))
+		require.EqualError(t, err, "loading token type 000901: unit 000901 not found")
+	})
+
+	t.Run("invalid hierarchy", func(t *testing.T) {
+		units := map[string]types.UnitData{
+			string(ftID(1)):  ft(1, 2),
+			string(ftID(2)):  ft(2, 1),
+			string(ftID(3)):  &FungibleTokenTypeData{ParentTypeID: nftID(4)},
+			string(ftID(4)):  (*FungibleTokenTypeData)(nil),
+			string(nftID(4)): nft(4, 0),
+			string(ftID(5)):  &FungibleTokenData{},
+		}
+		h := NewTypeHierarchy(func(id types.UnitID) (types.UnitData, error) { return units[string(id)], nil }, 0)
+
+		_, err := h.Chain(ftID(1))
+		require.ErrorIs(t, err, ErrTypeHierarchyCycle)
+		require.EqualError(t, err, "token type hierarchy contains a cycle: type 000101 is its own ancestor")
+
+		_, err = h.Chain(ftID(3))
+		require.EqualError(t, err, "token type 000301 has ancestor 000402 of different kind")
+
+		_, err = h.Chain(ftID(4))
+		require.EqualError(t, err, "token type 000401 not found")
+
+		_, err = h.Chain(ftID(5))
+		require.EqualError(t, err, "unit 000501 is not a token type: *tokens.FungibleTokenData")
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		h := NewTypeHierarchy(lookup, 2)
+		_, err := h.Chain(ftID(3))
+		require.EqualError(t, err, "type hierarchy of 000301 is deeper than 2 types")
+		chain, err := h.Chain(ftID(2))
+		require.NoError(t, err)
+		require.Len(t, chain, 2)
+	})
+
+	t.Run("required predicates", func(t *testing.T) {
+		var testCases = []struct {
+			txType uint16
+			typeID types.UnitID
+			result [][]byte
+		}{
+			{TransactionTypeDefineFT, nil, [][]byte{}},
+			{TransactionTypeDefineFT, ftID(2), [][]byte{{'s', 2}, {'s', 1}}},
+			{TransactionTypeDefineNFT, nftID(5), [][]byte{{'s', 5}, {'s', 4}}},
+			{TransactionTypeMintFT, ftID(3), [][]byte{{'m', 3}}},
+			{TransactionTypeMintNFT, nftID(5), [][]byte{{'m', 5}}},
+			{TransactionTypeTransferFT, ftID(3), [][]byte{{'o', 3}, {'o', 2}, {'o', 1}}},
+			{TransactionTypeSplitFT, ftID(2), [][]byte{{'o', 2}, {'o', 1}}},
+			{TransactionTypeBurnFT, ftID(1), [][]byte{{'o', 1}}},
+			{TransactionTypeJoinFT, ftID(3), [][]byte{{'o', 3}, {'o', 2}, {'o', 1}}},
+			{TransactionTypeTransferNFT, nftID(5), [][]byte{{'o', 5}, {'o', 4}}},
+			{TransactionTypeUpdateNFT, nftID(5), [][]byte{{'u', 5}, {'u', 4}}},
+		}
+		for _, tc := range testCases {
+			predicates, err := h.RequiredPredicates(tc.txType, tc.typeID)
+			require.NoError(t, err, "tx type %d", tc.txType)
+			require.Equal(t, tc.result, predicates, "tx type %d", tc.txType)
+		}
+
+		_, err := h.RequiredPredicates(100, ftID(1))
+		require.EqualError(t, err, "unsupported transaction type 100")
+
+		_, err = h.RequiredPredicates(TransactionTypeMintFT, nil)
+		require.EqualError(t, err, "token type ID is required")
+
+		_, err = h.RequiredPredicates(TransactionTypeTransferNFT, ftID(1))
+		require.EqualError(t, err, "token type 000101 is of wrong kind for transaction type 6")
+
+		_, err = h.RequiredPredicates(TransactionTypeDefineFT, nftID(4))
+		require.EqualError(t, err, "token type 000402 is of wrong kind for transaction type 1")
+	})
+
+	t.Run("auth proof", func(t *testing.T) {
+		prove := func(predicate []byte) ([]byte, error) { return append([]byte{'p'}, predicate...), nil }
+		tokenProof := []byte{'t'}
+
+		proof, err := h.AuthProof(TransactionTypeTransferFT, ftID(2), tokenProof, prove)
+		require.NoError(t, err)
+		require.Equal(t, &TransferFungibleTokenAuthProof{OwnerProof: tokenProof, TokenTypeOwnerProofs: [][]byte{{'p', 'o', 2}, {'p', 'o', 1}}}, proof)
+
+		proof, err = h.AuthProof(TransactionTypeDefineNFT, nftID(5), tokenProof, prove)
+		require.NoError(t, err)
+		require.Equal(t, &DefineNonFungibleTokenAuthProof{SubTypeCreationProofs: [][]byte{{'p', 's', 5}, {'p', 's', 4}}}, proof)
+
+		proof, err = h.AuthProof(TransactionTypeMintFT, ftID(3), tokenProof, prove)
+		require.NoError(t, err)
+		require.Equal(t, &MintFungibleTokenAuthProof{TokenMintingProof: []byte{'p', 'm', 3}}, proof)
+
+		proof, err = h.AuthProof(TransactionTypeUpdateNFT, nftID(4), tokenProof, prove)
+		require.NoError(t, err)
+		require.Equal(t, &UpdateNonFungibleTokenAuthProof{TokenDataUpdateProof: tokenProof, TokenTypeDataUpdateProofs: [][]byte{{'p', 'u', 4}}}, proof)
+
+		for _, txType := range []uint16{TransactionTypeDefineFT, TransactionTypeMintNFT, TransactionTypeSplitFT, TransactionTypeBurnFT, TransactionTypeJoinFT, TransactionTypeTransferNFT} {
+			typeID := ftID(1)
+			if txType == TransactionTypeMintNFT || txType == TransactionTypeTransferNFT {
+				typeID = nftID(4)
+			}
+			proof, err := h.AuthProof(txType, typeID, tokenProof, prove)
+			require.NoError(t, err)
+			// auth proof struct must match the tx type
+			txo := &types.TransactionOrder{Version: 1, Payload: types.Payload{Type: txType}}
+			require.NoError(t, txo.SetAuthProof(proof))
+		}
+
+		expErr := errors.New("no key")
+		_, err = h.AuthProof(TransactionTypeJoinFT, ftID(3), tokenProof, func(predicate []byte) ([]byte, error) {
+			if predicate[1] == 2 {
+				return nil, expErr
+			}
+			return nil, nil
+		})
+		require.ErrorIs(t, err, expErr)
+		require.EqualError(t, err, "creating proof 1: no key")
+
+		_, err = h.AuthProof(100, ftID(3), tokenProof, prove)
+		require.EqualError(t, err, "unsupported transaction type 100")
+	})
+}
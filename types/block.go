@@ -53,6 +53,20 @@ func (b *Block) getUCv1() (*UnicityCertificate, error) {
 	return uc, nil
 }
 
+/*
+NewBlockCanonical decodes block from "data" and verifies that "data" is the
+deterministic encoding of the block. The check includes the transaction records
+(and orders) and the unicity certificate which are kept in encoded form,
+see cbor.UnmarshalCanonical.
+*/
+func NewBlockCanonical(data []byte) (*Block, error) {
+	b := &Block{}
+	if err := cbor.UnmarshalCanonical(data, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
 // CalculateBlockHash calculates the block hash, updates UC and returns the updated input record with the block hash.
 func (b *Block) CalculateBlockHash(algorithm crypto.Hash) (*InputRecord, error) {
 	uc, err := b.getUCv1()
@@ -83,10 +97,6 @@ func BlockHash(algorithm crypto.Hash, h *Header, txs []*TransactionRecord, state
 		return nil, fmt.Errorf("invalid block: %w", err)
 	}
 
-	// ⊥ - if there are no transactions and state does not change
-	if len(txs) == 0 && bytes.Equal(prevStateHash, stateHash) {
-		return nil, nil
-	}
 	// init transactions merkle root to ⊥
 	var merkleRoot []byte
 	// calculate Merkle tree of transactions if any
@@ -98,6 +108,16 @@ func BlockHash(algorithm crypto.Hash, h *Header, txs []*TransactionRecord, state
 		}
 		merkleRoot = tree.GetRootHash()
 	}
+	return blockHash(algorithm, h, merkleRoot, stateHash, prevStateHash)
+}
+
+// blockHash calculates block hash from the header and root hash of the transactions
+// Merkle tree (nil when there are no transactions in the block).
+func blockHash(algorithm crypto.Hash, h *Header, merkleRoot []byte, stateHash []byte, prevStateHash []byte) ([]byte, error) {
+	// ⊥ - if there are no transactions and state does not change
+	if merkleRoot == nil && bytes.Equal(prevStateHash, stateHash) {
+		return nil, nil
+	}
 	// header hash || UC.IR.h′ || UC.IR.h || tree hash of transactions
 	hasher := abhash.New(algorithm.New())
 	headerHash, err := h.Hash(algorithm)
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+	"github.com/alphabill-org/alphabill-go-base/util"
+)
+
+type (
+	// BlockFeeAudit is the fee accounting report of a block, see Block.AuditFees.
+	BlockFeeAudit struct {
+		TotalFee     uint64           `json:"totalFee"`     // sum of the actual fees of all the transactions in the block
+		CertifiedFee uint64           `json:"certifiedFee"` // InputRecord.SumOfEarnedFees of the block's UC
+		FeeCredits   []*FeeCreditFees `json:"feeCredits"`   // totals per fee credit record, sorted by record ID
+	}
+
+	// FeeCreditFees is the sum of fees charged from a fee credit record in a block.
+	FeeCreditFees struct {
+		// ID of the fee credit record, nil for transactions which do not
+		// have fee credit record ID in their client metadata (ie fee credit
+		// transactions which pay the fee from the amount transferred)
+		FeeCreditRecordID hex.Bytes `json:"feeCreditRecordId"`
+		TxCount           uint64    `json:"txCount"`
+		Fee               uint64    `json:"fee"`
+	}
+)
+
+/*
+AuditFees sums the actual fees of the block transactions (overall and per fee
+credit record) and verifies that:
+  - the sum of the actual fees is equal to the sum of fees in the UC input record;
+  - actual fee of each transaction does not exceed the MaxTransactionFee of the
+    transaction order.
+
+When the block can be audited but some of the checks fail both the report and
+error (joined error of all the failed checks) are returned. When the report can't
+be built (ie the block is invalid, fee sum overflows) nil report is returned.
+*/
+func (b *Block) AuditFees() (*BlockFeeAudit, error) {
+	uc, err := b.getUCv1()
+	if err != nil {
+		return nil, fmt.Errorf("unicity certificate error: %w", err)
+	}
+
+	audit := &BlockFeeAudit{CertifiedFee: uc.GetFeeSum()}
+	fcrFees := map[string]*FeeCreditFees{}
+	var errs []error
+	for idx, txr := range b.Transactions {
+		txo, err := txr.GetTransactionOrderV1()
+		if err != nil {
+			return nil, fmt.Errorf("decoding transaction order %d: %w", idx, err)
+		}
+		fee := txr.GetActualFee()
+		if maxFee := txo.MaxFee(); fee > maxFee {
+			errs = append(errs, fmt.Errorf("transaction %d: actual fee %d exceeds max transaction fee %d", idx, fee, maxFee))
+		}
+		var ok bool
+		if audit.TotalFee, ok = util.AddUint64(audit.TotalFee, fee); !ok {
+			return nil, fmt.Errorf("transaction %d: sum of fees overflows", idx)
+		}
+
+		fcrID := txo.FeeCreditRecordID()
+		fcr, found := fcrFees[string(fcrID)]
+		if !found {
+			fcr = &FeeCreditFees{FeeCreditRecordID: fcrID}
+			fcrFees[string(fcrID)] = fcr
+			audit.FeeCredits = append(audit.FeeCredits, fcr)
+		}
+		if fcr.Fee, ok = util.AddUint64(fcr.Fee, fee); !ok {
+			return nil, fmt.Errorf("transaction %d: sum of fees of fee credit record %X overflows", idx, fcrID)
+		}
+		fcr.TxCount++
+	}
+	slices.SortFunc(audit.FeeCredits, func(a, b *FeeCreditFees) int {
+		return bytes.Compare(a.FeeCreditRecordID, b.FeeCreditRecordID)
+	})
+
+	if audit.TotalFee != audit.CertifiedFee {
+		errs = append(errs, fmt.Errorf("sum of actual fees %d does not match the sum of fees in the input record %d", audit.TotalFee, audit.CertifiedFee))
+	}
+	return audit, errors.Join(errs...)
+}
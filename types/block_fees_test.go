@@ -0,0 +1,85 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlock_AuditFees(t *testing.T) {
+	createBlock := func(t *testing.T, feeSum uint64, txs ...*TransactionRecord) *Block {
+		uc, err := (&UnicityCertificate{Version: 1, InputRecord: &InputRecord{Version: 1, SumOfEarnedFees: feeSum}}).MarshalCBOR()
+		require.NoError(t, err)
+		return &Block{Header: &Header{Version: 1}, Transactions: txs, UnicityCertificate: uc}
+	}
+	createTx := func(t *testing.T, fcrID []byte, maxFee, actualFee uint64) *TransactionRecord {
+		txo := createTransactionOrder(t)
+		txo.ClientMetadata.FeeCreditRecordID = fcrID
+		txo.ClientMetadata.MaxTransactionFee = maxFee
+		return createTransactionRecord(t, txo, actualFee)
+	}
+
+	t.Run("block is nil", func(t *testing.T) {
+		var b *Block
+		audit, err := b.AuditFees()
+		require.EqualError(t, err, "unicity certificate error: block is nil")
+		require.Nil(t, audit)
+	})
+
+	t.Run("empty block", func(t *testing.T) {
+		audit, err := createBlock(t, 0).AuditFees()
+		require.NoError(t, err)
+		require.Equal(t, &BlockFeeAudit{}, audit)
+	})
+
+	t.Run("fees per fee credit record", func(t *testing.T) {
+		b := createBlock(t, 16,
+			createTx(t, []byte{2}, 10, 1),
+			createTx(t, []byte{1}, 10, 2),
+			createTx(t, nil, 10, 3),
+			createTx(t, []byte{2}, 10, 10),
+		)
+		audit, err := b.AuditFees()
+		require.NoError(t, err)
+		require.Equal(t, &BlockFeeAudit{
+			TotalFee:     16,
+			CertifiedFee: 16,
+			FeeCredits: []*FeeCreditFees{
+				{FeeCreditRecordID: nil, TxCount: 1, Fee: 3},
+				{FeeCreditRecordID: []byte{1}, TxCount: 1, Fee: 2},
+				{FeeCreditRecordID: []byte{2}, TxCount: 2, Fee: 11},
+			},
+		}, audit)
+	})
+
+	t.Run("fee sum mismatch and max fee exceeded", func(t *testing.T) {
+		b := createBlock(t, 5,
+			createTx(t, []byte{1}, 10, 2),
+			createTx(t, []byte{1}, 1, 2),
+		)
+		audit, err := b.AuditFees()
+		require.EqualError(t, err, "transaction 1: actual fee 2 exceeds max transaction fee 1\n"+
+			"sum of actual fees 4 does not match the sum of fees in the input record 5")
+		require.NotNil(t, audit)
+		require.EqualValues(t, 4, audit.TotalFee)
+		require.EqualValues(t, 5, audit.CertifiedFee)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		b := createBlock(t, 5,
+			createTx(t, []byte{1}, math.MaxUint64, math.MaxUint64),
+			createTx(t, []byte{2}, 10, 2),
+		)
+		audit, err := b.AuditFees()
+		require.EqualError(t, err, "transaction 1: sum of fees overflows")
+		require.Nil(t, audit)
+	})
+
+	t.Run("invalid transaction", func(t *testing.T) {
+		b := createBlock(t, 0, &TransactionRecord{Version: 1})
+		audit, err := b.AuditFees()
+		require.EqualError(t, err, "decoding transaction order 0: transaction order is nil")
+		require.Nil(t, audit)
+	})
+}
@@ -0,0 +1,210 @@
+package types
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/tree/mt"
+)
+
+/*
+BlockReader decodes CBOR encoded Block from a stream without loading the
+whole block into memory. The header is decoded when the reader is created,
+transaction records are returned one at a time by Next and the unicity
+certificate can be read after all the transactions have been consumed.
+Root hash of the transactions Merkle tree is calculated incrementally so
+memory use is bounded by the size of the largest transaction record rather
+than the size of the block.
+
+	br, err := NewBlockReader(r, crypto.SHA256)
+	for {
+		tx, err := br.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		...
+	}
+	uc, err := br.UnicityCertificate()
+*/
+type BlockReader struct {
+	algorithm crypto.Hash
+	dec       *cbor.Decoder
+	header    *Header
+	txCount   uint64
+	txRead    uint64
+	txTree    *mt.Accumulator
+	size      uint64
+	uc        *UnicityCertificate
+}
+
+// BlockTx is a transaction record returned by the BlockReader.
+type BlockTx struct {
+	Index  uint64             // index of the transaction in the block
+	Record *TransactionRecord // decoded transaction record
+	Raw    cbor.RawCBOR       // transaction record as it was encoded in the block
+	Hash   []byte             // hash of the transaction record (leaf of the transactions Merkle tree)
+}
+
+/*
+NewBlockReader reads the block header from r and returns reader for the rest of the block.
+The algorithm is used to hash the transaction records.
+*/
+func NewBlockReader(r io.Reader, algorithm crypto.Hash) (*BlockReader, error) {
+	n, err := cbor.ReadArrayHead(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading block array head: %w", err)
+	}
+	if n != 3 {
+		return nil, fmt.Errorf("expected block to be array of 3 items, got %d", n)
+	}
+
+	br := &BlockReader{algorithm: algorithm, txTree: mt.NewAccumulator(algorithm)}
+	dec := cbor.GetDecoder(r)
+	if err := dec.Decode(&br.header); err != nil {
+		return nil, fmt.Errorf("decoding block header: %w", err)
+	}
+	// decoder buffers data so the rest of the stream is what it has buffered
+	// and whatever is still in the source reader
+	r = io.MultiReader(dec.Buffered(), r)
+	if br.txCount, err = cbor.ReadArrayHead(r); err != nil {
+		return nil, fmt.Errorf("reading transactions array head: %w", err)
+	}
+	br.dec = cbor.GetDecoder(r)
+	return br, nil
+}
+
+// Header returns the block header, it might be nil when the block doesn't have header.
+func (br *BlockReader) Header() *Header {
+	return br.header
+}
+
+// TxCount returns number of transactions in the block.
+func (br *BlockReader) TxCount() uint64 {
+	return br.txCount
+}
+
+/*
+Next returns the next transaction record of the block. When all the transactions
+have been read io.EOF is returned.
+*/
+func (br *BlockReader) Next() (*BlockTx, error) {
+	if br.txRead == br.txCount {
+		return nil, io.EOF
+	}
+	tx := &BlockTx{Index: br.txRead, Record: &TransactionRecord{}}
+	if err := br.dec.Decode(&tx.Raw); err != nil {
+		return nil, fmt.Errorf("reading transaction %d: %w", tx.Index, err)
+	}
+	if len(tx.Raw) == 0 {
+		return nil, fmt.Errorf("transaction %d: %w", tx.Index, ErrTransactionRecordIsNil)
+	}
+	if err := tx.Record.UnmarshalCBOR(tx.Raw); err != nil {
+		return nil, fmt.Errorf("decoding transaction %d: %w", tx.Index, err)
+	}
+	var err error
+	if tx.Hash, err = tx.Record.Hash(br.algorithm); err != nil {
+		return nil, fmt.Errorf("hashing transaction %d: %w", tx.Index, err)
+	}
+	if err := br.txTree.AddHash(tx.Hash); err != nil {
+		return nil, fmt.Errorf("adding transaction %d to the Merkle tree: %w", tx.Index, err)
+	}
+	br.txRead++
+	br.size += uint64(len(tx.Raw))
+	return tx, nil
+}
+
+/*
+UnicityCertificate returns the unicity certificate of the block. It can only be
+called after all the transactions have been read with Next.
+*/
+func (br *BlockReader) UnicityCertificate() (*UnicityCertificate, error) {
+	if br.uc != nil {
+		return br.uc, nil
+	}
+	if br.txRead != br.txCount {
+		return nil, fmt.Errorf("%d transactions of the block have not been read", br.txCount-br.txRead)
+	}
+	var raw cbor.TaggedCBOR
+	if err := br.dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("reading unicity certificate: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, ErrUnicityCertificateIsNil
+	}
+	uc := &UnicityCertificate{}
+	if err := cbor.Unmarshal(raw, uc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal unicity certificate: %w", err)
+	}
+	br.uc = uc
+	return uc, nil
+}
+
+/*
+Size returns the sum of the sizes of the transaction records read so far. For
+canonically encoded block it is equal to the Block.Size once all the transactions
+have been read.
+*/
+func (br *BlockReader) Size() uint64 {
+	return br.size
+}
+
+/*
+TxMerkleRoot returns the root hash of the transactions Merkle tree (nil when there
+are no transactions in the block). It can only be called after all the transactions
+have been read with Next.
+*/
+func (br *BlockReader) TxMerkleRoot() ([]byte, error) {
+	if br.txRead != br.txCount {
+		return nil, fmt.Errorf("%d transactions of the block have not been read", br.txCount-br.txRead)
+	}
+	return br.txTree.GetRootHash()
+}
+
+/*
+BlockHash returns the hash of the block, the result is the same as BlockHash
+function would return for the fully decoded block. The unicity certificate
+is read if it hasn't been read yet.
+*/
+func (br *BlockReader) BlockHash() ([]byte, error) {
+	if err := br.header.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid block: %w", err)
+	}
+	uc, err := br.UnicityCertificate()
+	if err != nil {
+		return nil, err
+	}
+	root, err := br.TxMerkleRoot()
+	if err != nil {
+		return nil, err
+	}
+	return blockHash(br.algorithm, br.header, root, uc.GetStateHash(), uc.GetPreviousStateHash())
+}
+
+/*
+IsValid performs the same checks as Block.IsValid on the streamed block. Must be
+called after all the transactions have been read with Next.
+*/
+func (br *BlockReader) IsValid(shardConfHash []byte) error {
+	if err := br.header.IsValid(); err != nil {
+		return fmt.Errorf("block error: %w", err)
+	}
+	uc, err := br.UnicityCertificate()
+	if err != nil {
+		return fmt.Errorf("unicity certificate error: %w", err)
+	}
+	if err := uc.IsValid(br.header.PartitionID, shardConfHash); err != nil {
+		return fmt.Errorf("unicity certificate validation failed: %w", err)
+	}
+	hash, err := br.BlockHash()
+	if err != nil {
+		return fmt.Errorf("block hash calculation failed: %w", err)
+	}
+	if !bytes.Equal(hash, uc.InputRecord.BlockHash) {
+		return errors.New("block hash does not match to the block hash in the unicity certificate input record")
+	}
+	return nil
+}
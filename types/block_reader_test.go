@@ -0,0 +1,135 @@
+package types
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+)
+
+func TestBlockReader(t *testing.T) {
+	createBlock := func(t *testing.T, txCount int) *Block {
+		b := &Block{
+			Header: &Header{
+				Version:           1,
+				PartitionID:       1,
+				ProposerID:        "test",
+				PreviousBlockHash: []byte{1, 2, 3},
+			},
+			Transactions: make([]*TransactionRecord, txCount),
+		}
+		for i := range b.Transactions {
+			txo := createTransactionOrder(t)
+			txo.ClientMetadata.ReferenceNumber = []byte{byte(i)}
+			b.Transactions[i] = createTransactionRecord(t, txo, uint64(i))
+		}
+		uc, err := (&UnicityCertificate{Version: 1, InputRecord: &InputRecord{
+			Version:      1,
+			Hash:         []byte{1, 1, 1},
+			PreviousHash: []byte{2, 2, 2},
+		}}).MarshalCBOR()
+		require.NoError(t, err)
+		b.UnicityCertificate = uc
+		_, err = b.CalculateBlockHash(crypto.SHA256)
+		require.NoError(t, err)
+		return b
+	}
+
+	readAll := func(t *testing.T, br *BlockReader) []*BlockTx {
+		var txs []*BlockTx
+		for {
+			tx, err := br.Next()
+			if errors.Is(err, io.EOF) {
+				return txs
+			}
+			require.NoError(t, err)
+			txs = append(txs, tx)
+		}
+	}
+
+	for _, txCount := range []int{0, 1, 5, 33} {
+		b := createBlock(t, txCount)
+		data, err := cbor.Marshal(b)
+		require.NoError(t, err)
+
+		br, err := NewBlockReader(iotest.OneByteReader(bytes.NewReader(data)), crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, b.Header, br.Header())
+		require.EqualValues(t, txCount, br.TxCount())
+
+		_, err = br.TxMerkleRoot()
+		if txCount > 0 {
+			require.EqualError(t, err, fmt.Sprintf("%d transactions of the block have not been read", txCount))
+			_, err = br.UnicityCertificate()
+			require.EqualError(t, err, fmt.Sprintf("%d transactions of the block have not been read", txCount))
+		}
+
+		txs := readAll(t, br)
+		require.Len(t, txs, txCount)
+		for i, tx := range txs {
+			require.EqualValues(t, i, tx.Index)
+			require.Equal(t, b.Transactions[i], tx.Record)
+			require.Equal(t, doHash(t, b.Transactions[i]), tx.Hash)
+			buf, err := b.Transactions[i].Bytes()
+			require.NoError(t, err)
+			require.EqualValues(t, buf, tx.Raw)
+		}
+		size, err := b.Size()
+		require.NoError(t, err)
+		require.Equal(t, size, br.Size())
+
+		uc, err := br.UnicityCertificate()
+		require.NoError(t, err)
+		ir, err := b.InputRecord()
+		require.NoError(t, err)
+		require.Equal(t, ir, uc.InputRecord)
+
+		hash, err := br.BlockHash()
+		require.NoError(t, err)
+		require.EqualValues(t, ir.BlockHash, hash)
+		require.ErrorContains(t, br.IsValid(nil), "unicity certificate validation failed")
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := NewBlockReader(bytes.NewReader(nil), crypto.SHA256)
+		require.EqualError(t, err, "reading block array head: EOF")
+
+		_, err = NewBlockReader(bytes.NewReader([]byte{0x82, 0xf6, 0x80}), crypto.SHA256)
+		require.EqualError(t, err, "expected block to be array of 3 items, got 2")
+
+		_, err = NewBlockReader(bytes.NewReader([]byte{0x83, 0xf6, 0x01}), crypto.SHA256)
+		require.EqualError(t, err, "reading transactions array head: expected array (major type 4), got major type 0")
+
+		// block claims to have 2 transactions but data ends after the first one
+		b := createBlock(t, 2)
+		data, err := cbor.Marshal(b)
+		require.NoError(t, err)
+		txr, err := b.Transactions[1].Bytes()
+		require.NoError(t, err)
+		data = data[:bytes.Index(data, txr)]
+		br, err := NewBlockReader(bytes.NewReader(data), crypto.SHA256)
+		require.NoError(t, err)
+		_, err = br.Next()
+		require.NoError(t, err)
+		_, err = br.Next()
+		require.ErrorIs(t, err, io.EOF)
+		require.ErrorContains(t, err, "reading transaction 1")
+	})
+
+	t.Run("block without unicity certificate", func(t *testing.T) {
+		data, err := cbor.Marshal(Block{Header: &Header{Version: 1}, Transactions: []*TransactionRecord{}})
+		require.NoError(t, err)
+		br, err := NewBlockReader(bytes.NewReader(data), crypto.SHA256)
+		require.NoError(t, err)
+		require.Empty(t, readAll(t, br))
+		_, err = br.UnicityCertificate()
+		require.ErrorIs(t, err, ErrUnicityCertificateIsNil)
+	})
+}
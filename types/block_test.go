@@ -1,7 +1,9 @@
 package types
 
 import (
+	"bytes"
 	"crypto"
+	"strconv"
 	"testing"
 	"time"
 
@@ -558,3 +560,34 @@ func TestBlock_CBOR(t *testing.T) {
 		require.ErrorContains(t, err, "invalid version (type *types.Header), expected 1, got 2")
 	})
 }
+
+func Test_NewBlockCanonical(t *testing.T) {
+	uc, err := (&UnicityCertificate{Version: 1, InputRecord: &InputRecord{Version: 1, RoundNumber: 1}}).MarshalCBOR()
+	require.NoError(t, err)
+	b := &Block{
+		Header:             &Header{Version: 1, PartitionID: 2, ProposerID: "test"},
+		Transactions:       []*TransactionRecord{createTransactionRecord(t, createTransactionOrder(t), 1)},
+		UnicityCertificate: uc,
+	}
+	data, err := cbor.Marshal(b)
+	require.NoError(t, err)
+
+	b2, err := NewBlockCanonical(data)
+	require.NoError(t, err)
+	require.Equal(t, b, b2)
+
+	// the UC is kept in encoded form, encode the round number of the input
+	// record using non-minimal length
+	ir := []byte{0xD9, 0x03, 0xF0, 0x8A, 0x01, 0x01}
+	idx := bytes.Index(uc, ir)
+	require.Positive(t, idx)
+	b.UnicityCertificate = append(append(bytes.Clone(uc[:idx+len(ir)-1]), 0x18, 0x01), uc[idx+len(ir):]...)
+	data, err = cbor.Marshal(b)
+	require.NoError(t, err)
+	require.NoError(t, cbor.Unmarshal(data, &Block{}))
+
+	b2, err = NewBlockCanonical(data)
+	require.ErrorIs(t, err, cbor.ErrNonCanonical)
+	require.EqualError(t, err, `non-canonical CBOR encoding of *types.Block: nested data item differs at offset `+strconv.Itoa(len(data)-len(b.UnicityCertificate)+idx+len(ir)-1))
+	require.Nil(t, b2)
+}
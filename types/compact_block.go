@@ -0,0 +1,248 @@
+package types
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+// ShortTxIDLen is the length of the short transaction identifier used by the CompactBlock.
+const ShortTxIDLen = 8
+
+var ErrMissingTransactions = errors.New("transactions missing from the compact block")
+
+type (
+	// CompactBlock is the Block where the transaction orders (which recipient is
+	// expected to have already received via gossip) are replaced by short
+	// identifiers. Block can be reconstructed from the compact block and the
+	// recipient's mempool, see CompactBlock.Reconstruct.
+	CompactBlock struct {
+		_                  struct{} `cbor:",toarray"`
+		Version            ABVersion
+		Header             *Header
+		Transactions       []*CompactTx
+		UnicityCertificate cbor.TaggedCBOR
+	}
+
+	CompactTx struct {
+		_              struct{}  `cbor:",toarray"`
+		ShortID        hex.Bytes // first ShortTxIDLen bytes of the transaction order hash
+		TxHash         hex.Bytes // hash of the transaction record
+		TxVersion      ABVersion // version of the transaction record
+		ServerMetadata *ServerMetadata
+		// optional, transaction order included into the compact block, ie
+		// when the sender expects the recipient not to have it
+		TransactionOrder TransactionOrderCBOR
+	}
+
+	// TxLookupFunc returns transaction orders (ie from the mempool) whose hash starts
+	// with the shortID. As short IDs may collide it might return several candidates.
+	TxLookupFunc func(shortID []byte) []*TransactionOrder
+)
+
+/*
+NewCompactBlock returns compact version of the block "b". When "prefill" is not
+nil it is called for each transaction of the block and when it returns true the
+transaction order is included into the compact block.
+*/
+func NewCompactBlock(algorithm crypto.Hash, b *Block, prefill func(idx int, txr *TransactionRecord) bool) (*CompactBlock, error) {
+	if b == nil {
+		return nil, errBlockIsNil
+	}
+	cb := &CompactBlock{
+		Version:            1,
+		Header:             b.Header,
+		Transactions:       make([]*CompactTx, len(b.Transactions)),
+		UnicityCertificate: b.UnicityCertificate,
+	}
+	for idx, txr := range b.Transactions {
+		if err := txr.IsValid(); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", idx, err)
+		}
+		txo, err := txr.GetTransactionOrderV1()
+		if err != nil {
+			return nil, fmt.Errorf("decoding transaction order %d: %w", idx, err)
+		}
+		shortID, err := ShortTxID(algorithm, txo)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d short ID: %w", idx, err)
+		}
+		txHash, err := txr.Hash(algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("hashing transaction %d: %w", idx, err)
+		}
+		ctx := &CompactTx{ShortID: shortID, TxHash: txHash, TxVersion: txr.Version, ServerMetadata: txr.ServerMetadata}
+		if prefill != nil && prefill(idx, txr) {
+			ctx.TransactionOrder = txr.TransactionOrder
+		}
+		cb.Transactions[idx] = ctx
+	}
+	return cb, nil
+}
+
+// ShortTxID returns short identifier of the transaction order used by the CompactBlock.
+func ShortTxID(algorithm crypto.Hash, txo *TransactionOrder) ([]byte, error) {
+	if txo == nil {
+		return nil, ErrTransactionOrderIsNil
+	}
+	h, err := txo.Hash(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("hashing transaction order: %w", err)
+	}
+	return h[:ShortTxIDLen], nil
+}
+
+/*
+Reconstruct rebuilds the full block from the compact block using "lookup" to find
+the transaction orders which are not included into the compact block. Each
+reconstructed transaction record is verified against the hash in the compact block
+and the block as a whole is verified by comparing its hash to the block hash in
+the input record of the unicity certificate.
+
+When some transaction orders can't be found the returned error wraps ErrMissingTransactions
+and the list of missing transaction indexes can be obtained with MissingTransactions.
+*/
+func (cb *CompactBlock) Reconstruct(algorithm crypto.Hash, lookup TxLookupFunc) (*Block, error) {
+	if cb == nil {
+		return nil, errBlockIsNil
+	}
+	if cb.Version != 1 {
+		return nil, ErrInvalidVersion(cb)
+	}
+	if err := cb.Header.IsValid(); err != nil {
+		return nil, fmt.Errorf("block error: %w", err)
+	}
+	b := &Block{
+		Header:             cb.Header,
+		Transactions:       make([]*TransactionRecord, len(cb.Transactions)),
+		UnicityCertificate: cb.UnicityCertificate,
+	}
+	var missing []int
+	for idx, ctx := range cb.Transactions {
+		txr, err := ctx.resolve(algorithm, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", idx, err)
+		}
+		if txr == nil {
+			missing = append(missing, idx)
+			continue
+		}
+		b.Transactions[idx] = txr
+	}
+	if len(missing) != 0 {
+		return nil, fmt.Errorf("%w: %v", ErrMissingTransactions, missing)
+	}
+
+	uc, err := b.getUCv1()
+	if err != nil {
+		return nil, fmt.Errorf("unicity certificate error: %w", err)
+	}
+	if uc.InputRecord == nil {
+		return nil, ErrInputRecordIsNil
+	}
+	hash, err := BlockHash(algorithm, b.Header, b.Transactions, uc.GetStateHash(), uc.GetPreviousStateHash())
+	if err != nil {
+		return nil, fmt.Errorf("block hash calculation failed: %w", err)
+	}
+	if !bytes.Equal(hash, uc.InputRecord.BlockHash) {
+		return nil, errors.New("block hash does not match to the block hash in the unicity certificate input record")
+	}
+	return b, nil
+}
+
+/*
+MissingTransactions returns indexes of the transactions which are not included
+into the compact block and can't be found with "lookup".
+*/
+func (cb *CompactBlock) MissingTransactions(algorithm crypto.Hash, lookup TxLookupFunc) ([]int, error) {
+	if cb == nil {
+		return nil, errBlockIsNil
+	}
+	var missing []int
+	for idx, ctx := range cb.Transactions {
+		txr, err := ctx.resolve(algorithm, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", idx, err)
+		}
+		if txr == nil {
+			missing = append(missing, idx)
+		}
+	}
+	return missing, nil
+}
+
+/*
+resolve returns transaction record matching the compact tx, nil when the
+transaction order is not included and lookup doesn't return matching tx.
+*/
+func (ctx *CompactTx) resolve(algorithm crypto.Hash, lookup TxLookupFunc) (*TransactionRecord, error) {
+	if ctx == nil {
+		return nil, errors.New("compact transaction is nil")
+	}
+	if ctx.ServerMetadata == nil {
+		return nil, ErrServerMetadataIsNil
+	}
+	if len(ctx.TransactionOrder) != 0 {
+		txr := &TransactionRecord{Version: ctx.TxVersion, TransactionOrder: ctx.TransactionOrder, ServerMetadata: ctx.ServerMetadata}
+		if err := ctx.verify(algorithm, txr); err != nil {
+			return nil, fmt.Errorf("included transaction order: %w", err)
+		}
+		return txr, nil
+	}
+	if lookup == nil {
+		return nil, nil
+	}
+	for _, txo := range lookup(ctx.ShortID) {
+		if txo == nil {
+			continue
+		}
+		txoBytes, err := txo.MarshalCBOR()
+		if err != nil {
+			return nil, fmt.Errorf("encoding transaction order: %w", err)
+		}
+		txr := &TransactionRecord{Version: ctx.TxVersion, TransactionOrder: txoBytes, ServerMetadata: ctx.ServerMetadata}
+		// short IDs may collide, keep looking when hash doesn't match
+		if ctx.verify(algorithm, txr) == nil {
+			return txr, nil
+		}
+	}
+	return nil, nil
+}
+
+func (cb *CompactBlock) GetVersion() ABVersion {
+	if cb != nil && cb.Version > 0 {
+		return cb.Version
+	}
+	return 1
+}
+
+func (cb *CompactBlock) MarshalCBOR() ([]byte, error) {
+	type alias CompactBlock
+	if cb.Version == 0 {
+		cb.Version = cb.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(CompactBlockTag, (*alias)(cb))
+}
+
+func (cb *CompactBlock) UnmarshalCBOR(data []byte) error {
+	type alias CompactBlock
+	if err := cbor.UnmarshalTaggedValue(CompactBlockTag, data, (*alias)(cb)); err != nil {
+		return fmt.Errorf("failed to unmarshal compact block: %w", err)
+	}
+	return EnsureVersion(cb, cb.Version, 1)
+}
+
+func (ctx *CompactTx) verify(algorithm crypto.Hash, txr *TransactionRecord) error {
+	h, err := txr.Hash(algorithm)
+	if err != nil {
+		return fmt.Errorf("hashing transaction record: %w", err)
+	}
+	if !bytes.Equal(h, ctx.TxHash) {
+		return fmt.Errorf("transaction record hash mismatch: expected %X, got %X", ctx.TxHash, h)
+	}
+	return nil
+}
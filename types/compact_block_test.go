@@ -0,0 +1,161 @@
+package types
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+)
+
+func TestCompactBlock(t *testing.T) {
+	uc, err := (&UnicityCertificate{Version: 1, InputRecord: &InputRecord{
+		Version:      1,
+		Hash:         []byte{1, 1, 1},
+		PreviousHash: []byte{2, 2, 2},
+	}}).MarshalCBOR()
+	require.NoError(t, err)
+	b := &Block{
+		Header: &Header{
+			Version:           1,
+			PartitionID:       1,
+			ProposerID:        "test",
+			PreviousBlockHash: []byte{1, 2, 3},
+		},
+		UnicityCertificate: uc,
+	}
+	mempool := map[string]*TransactionOrder{}
+	for i := 0; i < 3; i++ {
+		txo := createTransactionOrder(t)
+		txo.ClientMetadata.ReferenceNumber = []byte{byte(i)}
+		b.Transactions = append(b.Transactions, createTransactionRecord(t, txo, uint64(i)))
+		id, err := ShortTxID(crypto.SHA256, txo)
+		require.NoError(t, err)
+		mempool[string(id)] = txo
+	}
+	_, err = b.CalculateBlockHash(crypto.SHA256)
+	require.NoError(t, err)
+
+	lookup := func(shortID []byte) []*TransactionOrder {
+		if txo, ok := mempool[string(shortID)]; ok {
+			return []*TransactionOrder{txo}
+		}
+		return nil
+	}
+
+	t.Run("reconstruct from mempool", func(t *testing.T) {
+		cb, err := NewCompactBlock(crypto.SHA256, b, nil)
+		require.NoError(t, err)
+		require.Len(t, cb.Transactions, 3)
+		require.EqualValues(t, 1, cb.Version)
+		for i, ctx := range cb.Transactions {
+			require.Len(t, ctx.ShortID, ShortTxIDLen)
+			require.Empty(t, ctx.TransactionOrder)
+			require.Equal(t, b.Transactions[i].Version, ctx.TxVersion)
+		}
+		// CBOR roundtrip
+		data, err := cbor.Marshal(cb)
+		require.NoError(t, err)
+		cb2 := &CompactBlock{}
+		require.NoError(t, cbor.Unmarshal(data, cb2))
+
+		b2, err := cb2.Reconstruct(crypto.SHA256, lookup)
+		require.NoError(t, err)
+		require.Equal(t, b, b2)
+	})
+
+	t.Run("prefilled transactions", func(t *testing.T) {
+		cb, err := NewCompactBlock(crypto.SHA256, b, func(idx int, txr *TransactionRecord) bool { return idx == 1 })
+		require.NoError(t, err)
+		require.NotEmpty(t, cb.Transactions[1].TransactionOrder)
+
+		b2, err := cb.Reconstruct(crypto.SHA256, func(shortID []byte) []*TransactionOrder {
+			if txo := mempool[string(shortID)]; txo.ClientMetadata.ReferenceNumber[0] != 1 {
+				return []*TransactionOrder{txo}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, b, b2)
+
+		// tampered prefilled transaction is detected
+		cb.Transactions[1].TransactionOrder = b.Transactions[0].TransactionOrder
+		_, err = cb.Reconstruct(crypto.SHA256, lookup)
+		require.ErrorContains(t, err, "transaction 1: included transaction order: transaction record hash mismatch")
+	})
+
+	t.Run("missing transactions", func(t *testing.T) {
+		cb, err := NewCompactBlock(crypto.SHA256, b, nil)
+		require.NoError(t, err)
+
+		partial := func(shortID []byte) []*TransactionOrder {
+			if txo := mempool[string(shortID)]; txo.ClientMetadata.ReferenceNumber[0] == 1 {
+				return []*TransactionOrder{txo}
+			}
+			return nil
+		}
+		_, err = cb.Reconstruct(crypto.SHA256, partial)
+		require.ErrorIs(t, err, ErrMissingTransactions)
+		require.EqualError(t, err, "transactions missing from the compact block: [0 2]")
+
+		missing, err := cb.MissingTransactions(crypto.SHA256, partial)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 2}, missing)
+
+		missing, err = cb.MissingTransactions(crypto.SHA256, nil)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2}, missing)
+	})
+
+	t.Run("short ID collision", func(t *testing.T) {
+		cb, err := NewCompactBlock(crypto.SHA256, b, nil)
+		require.NoError(t, err)
+		// lookup returns all the transactions as candidates
+		b2, err := cb.Reconstruct(crypto.SHA256, func(shortID []byte) []*TransactionOrder {
+			return []*TransactionOrder{nil, mempool[string(cb.Transactions[2].ShortID)], mempool[string(cb.Transactions[1].ShortID)], mempool[string(cb.Transactions[0].ShortID)]}
+		})
+		require.NoError(t, err)
+		require.Equal(t, b, b2)
+	})
+
+	t.Run("block hash mismatch", func(t *testing.T) {
+		cb, err := NewCompactBlock(crypto.SHA256, b, nil)
+		require.NoError(t, err)
+		cb.Transactions = cb.Transactions[:2]
+		_, err = cb.Reconstruct(crypto.SHA256, lookup)
+		require.EqualError(t, err, "block hash does not match to the block hash in the unicity certificate input record")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := NewCompactBlock(crypto.SHA256, nil, nil)
+		require.ErrorIs(t, err, errBlockIsNil)
+
+		_, err = NewCompactBlock(crypto.SHA256, &Block{Transactions: []*TransactionRecord{nil}}, nil)
+		require.EqualError(t, err, "invalid transaction 0: transaction record is nil")
+
+		var cb *CompactBlock
+		_, err = cb.Reconstruct(crypto.SHA256, lookup)
+		require.ErrorIs(t, err, errBlockIsNil)
+
+		cb, err = NewCompactBlock(crypto.SHA256, b, nil)
+		require.NoError(t, err)
+		cb.Transactions[0].ServerMetadata = nil
+		_, err = cb.Reconstruct(crypto.SHA256, lookup)
+		require.EqualError(t, err, "transaction 0: server metadata is nil")
+
+		// transaction record version is part of the record hash
+		cb, err = NewCompactBlock(crypto.SHA256, b, nil)
+		require.NoError(t, err)
+		cb.Transactions[0].TxVersion = 2
+		_, err = cb.Reconstruct(crypto.SHA256, lookup)
+		require.ErrorIs(t, err, ErrMissingTransactions)
+
+		cb.Version = 2
+		_, err = cb.Reconstruct(crypto.SHA256, lookup)
+		require.EqualError(t, err, "invalid version (type *types.CompactBlock)")
+		data, err := cbor.Marshal(cb)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &CompactBlock{}), "invalid version (type *types.CompactBlock), expected 1, got 2")
+	})
+}
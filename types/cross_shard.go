@@ -0,0 +1,162 @@
+package types
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type (
+	// CrossShardReceipt is the message of the source shard to the target shard: the
+	// transaction executed in the source shard affects units which belong into the
+	// target shard (ie bill was split and the new bill ID is in another shard).
+	CrossShardReceipt struct {
+		_           struct{}       `cbor:",toarray"`
+		SourceShard ShardID        `json:"sourceShard"`
+		TargetShard ShardID        `json:"targetShard"`
+		TargetUnits []UnitID       `json:"targetUnits"` // units of the target shard affected by the transaction
+		Proof       *TxRecordProof `json:"proof"`       // proof of the transaction execution in the source shard
+	}
+)
+
+/*
+NewCrossShardReceipts returns receipts for all the shards (other than the source
+shard) which contain units affected by the transaction in the "proof". The source
+shard is the shard which certified the transaction (see UnicityCertificate.GetShardID)
+and the target units (see TransactionRecord.TargetUnits) are assigned to the shards
+using the sharding scheme "ss". Returns empty list when the transaction is not a
+cross-shard transaction.
+
+The receipts are sorted by the target shard using CompareShardIDs.
+*/
+func (ss ShardingScheme) NewCrossShardReceipts(proof *TxRecordProof) ([]*CrossShardReceipt, error) {
+	if err := proof.IsValid(); err != nil {
+		return nil, fmt.Errorf("invalid transaction proof: %w", err)
+	}
+	if !proof.TxRecord.IsSuccessful() {
+		return nil, errors.New("transaction failed")
+	}
+	uc, err := proof.TxProof.GetUC()
+	if err != nil {
+		return nil, fmt.Errorf("reading UC of the tx proof: %w", err)
+	}
+	source := uc.GetShardID()
+
+	var receipts []*CrossShardReceipt
+	for _, id := range proof.TxRecord.TargetUnits() {
+		shard := ss.Shard(id)
+		if shard.Equal(source) {
+			continue
+		}
+		idx := slices.IndexFunc(receipts, func(r *CrossShardReceipt) bool { return r.TargetShard.Equal(shard) })
+		if idx == -1 {
+			receipts = append(receipts, &CrossShardReceipt{SourceShard: source, TargetShard: shard, Proof: proof})
+			idx = len(receipts) - 1
+		}
+		receipts[idx].TargetUnits = append(receipts[idx].TargetUnits, id)
+	}
+	slices.SortFunc(receipts, func(a, b *CrossShardReceipt) int { return CompareShardIDs(a.TargetShard, b.TargetShard) })
+	return receipts, nil
+}
+
+/*
+ComposeUnitID generates unit ID which belongs into the "shard" of the scheme, see
+PartitionDescriptionRecord.ComposeUnitID for description of the arguments. Meant
+to be used to generate ID for the unit which is to be created in another shard.
+*/
+func (ss ShardingScheme) ComposeUnitID(pdr *PartitionDescriptionRecord, shard ShardID, unitType uint32, prndSh func([]byte) error) (UnitID, error) {
+	if pdr == nil {
+		return nil, ErrSystemDescriptionIsNil
+	}
+	node, err := ss.findNode(shard)
+	if err != nil {
+		return nil, err
+	}
+	if !node.isLeaf() {
+		return nil, fmt.Errorf("shard ID %s is not a leaf of the sharding scheme", shard)
+	}
+	if shard.Length() > uint(pdr.UnitIDLen) {
+		return nil, fmt.Errorf("shard ID %q is longer than the unit ID length %d", shard, pdr.UnitIDLen)
+	}
+	return pdr.ComposeUnitID(shard, unitType, prndSh)
+}
+
+func (r *CrossShardReceipt) IsValid() error {
+	if r == nil {
+		return errors.New("cross-shard receipt is nil")
+	}
+	if r.SourceShard.Equal(r.TargetShard) {
+		return fmt.Errorf("source and target shard are the same (%q)", r.SourceShard)
+	}
+	if len(r.TargetUnits) == 0 {
+		return errors.New("target units list is empty")
+	}
+	if err := r.Proof.IsValid(); err != nil {
+		return fmt.Errorf("invalid transaction proof: %w", err)
+	}
+	return nil
+}
+
+/*
+Verify checks that the receipt was certified by the source shard:
+  - the transaction proof is valid and the transaction was executed successfully;
+  - the UC of the proof is issued to the source shard of the "sourceConf" partition
+    and the shard configuration hash in the UC matches with the "sourceConf";
+  - the transaction target unit belongs into the source shard;
+  - all the target units of the receipt were affected by the transaction and
+    belong into the target shard according to the sharding scheme "ss" (the
+    scheme of the partition at the time the transaction was executed).
+*/
+func (r *CrossShardReceipt) Verify(tb RootTrustBase, algo crypto.Hash, sourceConf *PartitionDescriptionRecord, ss ShardingScheme) error {
+	if err := r.IsValid(); err != nil {
+		return err
+	}
+	if sourceConf == nil {
+		return ErrSystemDescriptionIsNil
+	}
+	if !sourceConf.ShardID.Equal(r.SourceShard) {
+		return fmt.Errorf("shard configuration is for shard %q, receipt source shard is %q", sourceConf.ShardID, r.SourceShard)
+	}
+	if err := VerifyTxProof(r.Proof, tb, algo); err != nil {
+		return fmt.Errorf("verifying transaction proof: %w", err)
+	}
+
+	uc, err := r.Proof.TxProof.GetUC()
+	if err != nil {
+		return fmt.Errorf("reading UC of the tx proof: %w", err)
+	}
+	if uc.GetPartitionID() != sourceConf.PartitionID {
+		return fmt.Errorf("transaction was certified for partition %s, expected %s", uc.GetPartitionID(), sourceConf.PartitionID)
+	}
+	if !uc.GetShardID().Equal(r.SourceShard) {
+		return fmt.Errorf("transaction was certified by shard %q, receipt source shard is %q", uc.GetShardID(), r.SourceShard)
+	}
+	confHash, err := sourceConf.Hash(algo)
+	if err != nil {
+		return fmt.Errorf("calculating shard configuration hash: %w", err)
+	}
+	if !bytes.Equal(confHash, uc.ShardConfHash) {
+		return fmt.Errorf("shard configuration hash mismatch: UC has %X, expected %X", []byte(uc.ShardConfHash), confHash)
+	}
+
+	txo, err := r.Proof.GetTransactionOrderV1()
+	if err != nil {
+		return fmt.Errorf("decoding transaction order: %w", err)
+	}
+	if err := sourceConf.UnitIDValidator(r.SourceShard)(txo.UnitID); err != nil {
+		return fmt.Errorf("invalid transaction unit ID: %w", err)
+	}
+
+	targetUnits := r.Proof.TxRecord.TargetUnits()
+	for _, id := range r.TargetUnits {
+		if !slices.ContainsFunc(targetUnits, id.Eq) {
+			return fmt.Errorf("unit %s is not a target unit of the transaction", id)
+		}
+		if shard := ss.Shard(id); !shard.Equal(r.TargetShard) {
+			return fmt.Errorf("unit %s belongs into shard %q, not into the target shard %q", id, shard, r.TargetShard)
+		}
+	}
+	return nil
+}
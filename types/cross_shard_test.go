@@ -0,0 +1,218 @@
+package types
+
+import (
+	"crypto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	testsig "github.com/alphabill-org/alphabill-go-base/testutils/sig"
+)
+
+func Test_CrossShardReceipt(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	tb := NewTrustBase(t, verifier)
+
+	id0, id1 := ShardID{}.Split()
+	scheme := buildShardingScheme([]ShardID{id0, id1})
+	conf := &PartitionDescriptionRecord{
+		Version:     1,
+		NetworkID:   networkID,
+		PartitionID: partitionID,
+		ShardID:     id0,
+		TypeIDLen:   8,
+		UnitIDLen:   248,
+		T2Timeout:   2500 * time.Millisecond,
+	}
+	// unit "unitID" is in the shard "0", the new unit in shard "1"
+	newUnit := make(UnitID, 32)
+	newUnit[0] = 0x80
+
+	newProof := func(t *testing.T, targetUnits ...UnitID) *TxRecordProof {
+		txr := createTx(t)
+		txr.ServerMetadata.TargetUnits = targetUnits
+		block := createShardBlock(t, signer, scheme, conf, txr)
+		proof, err := NewTxRecordProof(block, 0, crypto.SHA256)
+		require.NoError(t, err)
+		return proof
+	}
+
+	t.Run("no receipts", func(t *testing.T) {
+		receipts, err := scheme.NewCrossShardReceipts(newProof(t, unitID))
+		require.NoError(t, err)
+		require.Empty(t, receipts)
+	})
+
+	t.Run("invalid proof", func(t *testing.T) {
+		receipts, err := scheme.NewCrossShardReceipts(nil)
+		require.EqualError(t, err, "invalid transaction proof: transaction record proof is nil")
+		require.Empty(t, receipts)
+
+		proof := newProof(t, unitID, newUnit)
+		proof.TxRecord.ServerMetadata.SuccessIndicator = TxStatusFailed
+		receipts, err = scheme.NewCrossShardReceipts(proof)
+		require.EqualError(t, err, "transaction failed")
+		require.Empty(t, receipts)
+	})
+
+	t.Run("receipt is valid", func(t *testing.T) {
+		proof := newProof(t, unitID, newUnit)
+		receipts, err := scheme.NewCrossShardReceipts(proof)
+		require.NoError(t, err)
+		require.Len(t, receipts, 1)
+		r := receipts[0]
+		require.Equal(t, id0, r.SourceShard)
+		require.Equal(t, id1, r.TargetShard)
+		require.Equal(t, []UnitID{newUnit}, r.TargetUnits)
+		require.Same(t, proof, r.Proof)
+		require.NoError(t, r.Verify(tb, crypto.SHA256, conf, scheme))
+	})
+
+	t.Run("verification fails", func(t *testing.T) {
+		proof := newProof(t, unitID, newUnit)
+		receipts, err := scheme.NewCrossShardReceipts(proof)
+		require.NoError(t, err)
+		r := receipts[0]
+
+		otherConf := *conf
+		otherConf.Epoch = 1
+		require.ErrorContains(t, r.Verify(tb, crypto.SHA256, &otherConf, scheme), "shard configuration hash mismatch: UC has ")
+
+		otherConf = *conf
+		otherConf.ShardID = id1
+		require.EqualError(t, r.Verify(tb, crypto.SHA256, &otherConf, scheme), `shard configuration is for shard "1", receipt source shard is "0"`)
+
+		require.ErrorIs(t, r.Verify(tb, crypto.SHA256, nil, scheme), ErrSystemDescriptionIsNil)
+
+		// receipt claims unit which is not affected by the tx
+		r2 := *r
+		otherUnit := make(UnitID, 32)
+		otherUnit[0], otherUnit[31] = 0x80, 1
+		r2.TargetUnits = []UnitID{otherUnit}
+		require.EqualError(t, r2.Verify(tb, crypto.SHA256, conf, scheme), `unit `+otherUnit.String()+` is not a target unit of the transaction`)
+
+		// unit is not in the target shard
+		r2.TargetUnits = []UnitID{unitID}
+		require.EqualError(t, r2.Verify(tb, crypto.SHA256, conf, scheme), `unit `+UnitID(unitID).String()+` belongs into shard "0", not into the target shard "1"`)
+
+		// wrong trust base
+		_, verifier2 := testsig.CreateSignerAndVerifier(t)
+		require.ErrorContains(t, r.Verify(NewTrustBase(t, verifier2), crypto.SHA256, conf, scheme), "verifying transaction proof: verify tx inclusion: invalid unicity certificate")
+	})
+
+	t.Run("IsValid", func(t *testing.T) {
+		var r *CrossShardReceipt
+		require.EqualError(t, r.IsValid(), "cross-shard receipt is nil")
+
+		r = &CrossShardReceipt{SourceShard: id1, TargetShard: id1}
+		require.EqualError(t, r.IsValid(), `source and target shard are the same ("1")`)
+
+		r.SourceShard = id0
+		require.EqualError(t, r.IsValid(), "target units list is empty")
+
+		r.TargetUnits = []UnitID{newUnit}
+		require.EqualError(t, r.IsValid(), "invalid transaction proof: transaction record proof is nil")
+	})
+}
+
+func Test_ShardingScheme_ComposeUnitID(t *testing.T) {
+	pdr := &PartitionDescriptionRecord{TypeIDLen: 8, UnitIDLen: 64}
+	prndSh := func(buf []byte) error {
+		for i := range buf {
+			buf[i] = 0xFF
+		}
+		return nil
+	}
+
+	id0, id1 := ShardID{}.Split()
+	id00, id01 := id0.Split()
+	scheme := buildShardingScheme([]ShardID{id00, id01, id1})
+
+	for shard := range scheme.All() {
+		id, err := scheme.ComposeUnitID(pdr, shard, 1, prndSh)
+		require.NoError(t, err)
+		require.Equal(t, shard, scheme.Shard(id))
+		require.EqualValues(t, 1, id[8])
+	}
+
+	_, err := scheme.ComposeUnitID(pdr, id0, 1, prndSh)
+	require.EqualError(t, err, "shard ID 0 is not a leaf of the sharding scheme")
+
+	_, err = scheme.ComposeUnitID(pdr, ShardID{bits: []byte{0x80}, length: 2}, 1, prndSh)
+	require.EqualError(t, err, "shard ID 10 is not in the scheme")
+
+	_, err = scheme.ComposeUnitID(nil, id1, 1, prndSh)
+	require.ErrorIs(t, err, ErrSystemDescriptionIsNil)
+}
+
+/*
+createShardBlock creates block of the shard "shardConf.ShardID" with the UC of the
+sharded partition (shards other than the block's shard get random input).
+*/
+func createShardBlock(t *testing.T, signer abcrypto.Signer, scheme ShardingScheme, shardConf *PartitionDescriptionRecord, txs ...*TransactionRecord) *Block {
+	t.Helper()
+	inputRecord := &InputRecord{
+		Version:         1,
+		PreviousHash:    []byte{0, 0, 1},
+		Hash:            []byte{0, 0, 2},
+		SummaryValue:    []byte{0, 0, 4},
+		RoundNumber:     1,
+		SumOfEarnedFees: 2,
+		Timestamp:       NewTimestamp(),
+	}
+	uc, err := (&UnicityCertificate{Version: 1, InputRecord: inputRecord}).MarshalCBOR()
+	require.NoError(t, err)
+	block := &Block{
+		Header: &Header{
+			Version:           1,
+			PartitionID:       shardConf.PartitionID,
+			ShardID:           shardConf.ShardID,
+			ProposerID:        "proposer123",
+			PreviousBlockHash: []byte{1, 2, 3},
+		},
+		Transactions:       txs,
+		UnicityCertificate: uc,
+	}
+	inputRecord, err = block.CalculateBlockHash(crypto.SHA256)
+	require.NoError(t, err)
+
+	trHash := make([]byte, 32)
+	shardConfHash := doHash(t, shardConf)
+	in := generateSTInput(scheme)
+	for i := range in {
+		if in[i].Shard.Equal(shardConf.ShardID) {
+			in[i] = ShardTreeInput{Shard: shardConf.ShardID, IR: inputRecord, TRHash: trHash, ShardConfHash: shardConfHash}
+		}
+	}
+	sTree, err := CreateShardTree(scheme, in, crypto.SHA256)
+	require.NoError(t, err)
+	stCert, err := sTree.Certificate(shardConf.ShardID)
+	require.NoError(t, err)
+
+	ut, err := NewUnicityTree(crypto.SHA256, []*UnicityTreeData{{Partition: shardConf.PartitionID, ShardTreeRoot: sTree.RootHash()}})
+	require.NoError(t, err)
+	utCert, err := ut.Certificate(shardConf.PartitionID)
+	require.NoError(t, err)
+	seal := &UnicitySeal{
+		Version:              1,
+		RootChainRoundNumber: 1,
+		Timestamp:            NewTimestamp(),
+		PreviousHash:         make([]byte, 32),
+		Hash:                 ut.RootHash(),
+	}
+	require.NoError(t, seal.Sign("test", signer))
+
+	block.UnicityCertificate, err = (&UnicityCertificate{
+		Version:                1,
+		InputRecord:            inputRecord,
+		TRHash:                 trHash,
+		ShardConfHash:          shardConfHash,
+		ShardTreeCertificate:   stCert,
+		UnicityTreeCertificate: utCert,
+		UnicitySeal:            seal,
+	}).MarshalCBOR()
+	require.NoError(t, err)
+	return block
+}
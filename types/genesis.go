@@ -0,0 +1,535 @@
+package types
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+type (
+	// RootGenesis is the genesis of the network: the root chain and the initial
+	// shards of the partitions.
+	RootGenesis struct {
+		_          struct{}                  `cbor:",toarray"`
+		Version    ABVersion                 `json:"version"`
+		Root       *GenesisRootRecord        `json:"root"`
+		Partitions []*GenesisPartitionRecord `json:"partitions"`
+	}
+
+	// GenesisRootRecord describes the root chain: the root validators and the
+	// consensus parameters signed by all of them.
+	GenesisRootRecord struct {
+		_              struct{}         `cbor:",toarray"`
+		Version        ABVersion        `json:"version"`
+		RootValidators []*NodeInfo      `json:"rootValidators"`
+		Consensus      *ConsensusParams `json:"consensus"`
+	}
+
+	// ConsensusParams are the parameters of the root chain consensus.
+	ConsensusParams struct {
+		_                   struct{}     `cbor:",toarray"`
+		Version             ABVersion    `json:"version"`
+		TotalRootValidators uint32       `json:"totalRootValidators"` // number of root validators
+		BlockRateMs         uint32       `json:"blockRateMs"`         // minimal duration of the root round
+		ConsensusTimeoutMs  uint32       `json:"consensusTimeoutMs"`  // root round timeout, must be greater than BlockRateMs
+		HashAlgorithm       uint32       `json:"hashAlgorithm"`       // crypto.Hash used by the root chain
+		Signatures          SignatureMap `json:"signatures"`          // signatures of the root validators
+	}
+
+	// GenesisPartitionRecord is the genesis of a shard: the shard configuration,
+	// the validators and the genesis UC of the shard.
+	GenesisPartitionRecord struct {
+		_                    struct{}                    `cbor:",toarray"`
+		Version              ABVersion                   `json:"version"`
+		Validators           []*PartitionNode            `json:"validators"`
+		Certificate          *UnicityCertificate         `json:"certificate"`
+		PartitionDescription *PartitionDescriptionRecord `json:"partitionDescriptionRecord"`
+	}
+
+	// PartitionNode is the (signed) request of the validator to join the shard.
+	PartitionNode struct {
+		_             struct{}  `cbor:",toarray"`
+		Version       ABVersion `json:"version"`
+		NodeID        string    `json:"nodeId"`
+		SigKey        hex.Bytes `json:"sigKey"`
+		ShardConfHash hex.Bytes `json:"shardConfHash"` // hash of the PartitionDescriptionRecord of the shard
+		Signature     hex.Bytes `json:"signature"`     // signature of the node over all the other fields
+	}
+)
+
+/*
+Verify checks the genesis against the root trust base "tb": the root validators of
+the genesis must be the root nodes of the trust base and genesis records of all the
+shards must be valid and certified by the root chain. The partition parameters of
+the shards are checked using "validate" (see PartitionDescriptionRecord.IsValidWith).
+*/
+func (x *RootGenesis) Verify(tb *RootTrustBaseV1, validate PartitionParamsValidator) error {
+	if x == nil {
+		return errors.New("root genesis is nil")
+	}
+	if x.Version != 1 {
+		return ErrInvalidVersion(x)
+	}
+	if tb == nil {
+		return ErrRootValidatorInfoMissing
+	}
+	if err := x.Root.IsValid(); err != nil {
+		return fmt.Errorf("invalid root genesis record: %w", err)
+	}
+	if err := sameNodes(x.Root.RootValidators, tb.RootNodes); err != nil {
+		return fmt.Errorf("root validators do not match the trust base: %w", err)
+	}
+	if len(x.Partitions) == 0 {
+		return errors.New("partitions list is empty")
+	}
+
+	algo := crypto.Hash(x.Root.Consensus.HashAlgorithm)
+	shards := make(map[PartitionID]map[string]struct{})
+	for i, p := range x.Partitions {
+		if err := p.Verify(tb, algo, validate); err != nil {
+			return fmt.Errorf("invalid genesis partition record at idx %d: %w", i, err)
+		}
+		pdr := p.PartitionDescription
+		if pdr.NetworkID != tb.NetworkID {
+			return fmt.Errorf("partition %s is of network %d, trust base is of network %d", pdr.PartitionID, pdr.NetworkID, tb.NetworkID)
+		}
+		if shards[pdr.PartitionID] == nil {
+			shards[pdr.PartitionID] = make(map[string]struct{})
+		}
+		if _, ok := shards[pdr.PartitionID][pdr.ShardID.Key()]; ok {
+			return fmt.Errorf("duplicate genesis record for partition %s shard %q", pdr.PartitionID, pdr.ShardID)
+		}
+		shards[pdr.PartitionID][pdr.ShardID.Key()] = struct{}{}
+	}
+	return nil
+}
+
+/*
+PartitionRecord returns the genesis record of the shard, nil when the genesis
+doesn't contain the shard.
+*/
+func (x *RootGenesis) PartitionRecord(partitionID PartitionID, shardID ShardID) *GenesisPartitionRecord {
+	for _, p := range x.Partitions {
+		if pdr := p.PartitionDescription; pdr != nil && pdr.PartitionID == partitionID && pdr.ShardID.Equal(shardID) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (x *RootGenesis) GetVersion() ABVersion {
+	if x != nil && x.Version > 0 {
+		return x.Version
+	}
+	return 1
+}
+
+func (x *RootGenesis) MarshalCBOR() ([]byte, error) {
+	type alias RootGenesis
+	if x.Version == 0 {
+		x.Version = x.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(RootGenesisTag, (*alias)(x))
+}
+
+func (x *RootGenesis) UnmarshalCBOR(data []byte) error {
+	type alias RootGenesis
+	if err := cbor.UnmarshalTaggedValue(RootGenesisTag, data, (*alias)(x)); err != nil {
+		return fmt.Errorf("failed to unmarshal root genesis: %w", err)
+	}
+	return EnsureVersion(x, x.Version, 1)
+}
+
+/*
+IsValid checks the root genesis record: the root validators are valid and all of
+them have signed the consensus parameters.
+*/
+func (x *GenesisRootRecord) IsValid() error {
+	if x == nil {
+		return errors.New("root genesis record is nil")
+	}
+	if x.Version != 1 {
+		return ErrInvalidVersion(x)
+	}
+	if len(x.RootValidators) == 0 {
+		return errors.New("root validators list is empty")
+	}
+	ids := make(map[string]struct{}, len(x.RootValidators))
+	for i, n := range x.RootValidators {
+		if err := n.IsValid(); err != nil {
+			return fmt.Errorf("invalid root validator at idx %d: %w", i, err)
+		}
+		if _, ok := ids[n.NodeID]; ok {
+			return fmt.Errorf("duplicate root validator %q", n.NodeID)
+		}
+		ids[n.NodeID] = struct{}{}
+	}
+	if err := x.Consensus.IsValid(); err != nil {
+		return fmt.Errorf("invalid consensus parameters: %w", err)
+	}
+	if cnt := len(x.RootValidators); uint32(cnt) != x.Consensus.TotalRootValidators {
+		return fmt.Errorf("consensus parameters require %d root validators, got %d", x.Consensus.TotalRootValidators, cnt)
+	}
+	if err := x.Consensus.Verify(x.RootValidators); err != nil {
+		return fmt.Errorf("consensus parameters: %w", err)
+	}
+	return nil
+}
+
+func (x *GenesisRootRecord) GetVersion() ABVersion {
+	if x != nil && x.Version > 0 {
+		return x.Version
+	}
+	return 1
+}
+
+func (x *GenesisRootRecord) MarshalCBOR() ([]byte, error) {
+	type alias GenesisRootRecord
+	if x.Version == 0 {
+		x.Version = x.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(GenesisRootRecordTag, (*alias)(x))
+}
+
+func (x *GenesisRootRecord) UnmarshalCBOR(data []byte) error {
+	type alias GenesisRootRecord
+	if err := cbor.UnmarshalTaggedValue(GenesisRootRecordTag, data, (*alias)(x)); err != nil {
+		return fmt.Errorf("failed to unmarshal genesis root record: %w", err)
+	}
+	return EnsureVersion(x, x.Version, 1)
+}
+
+func (x *ConsensusParams) IsValid() error {
+	if x == nil {
+		return errors.New("consensus parameters are nil")
+	}
+	if x.Version != 1 {
+		return ErrInvalidVersion(x)
+	}
+	if x.TotalRootValidators == 0 {
+		return errors.New("total root validators must be greater than zero")
+	}
+	if x.BlockRateMs == 0 {
+		return errors.New("block rate must be greater than zero")
+	}
+	if x.ConsensusTimeoutMs <= x.BlockRateMs {
+		return fmt.Errorf("consensus timeout %d ms must be greater than block rate %d ms", x.ConsensusTimeoutMs, x.BlockRateMs)
+	}
+	if crypto.Hash(x.HashAlgorithm) != crypto.SHA256 {
+		return fmt.Errorf("unsupported hash algorithm %d", x.HashAlgorithm)
+	}
+	return nil
+}
+
+// SigBytes serializes all fields except signatures (used for sign and verify).
+func (x ConsensusParams) SigBytes() ([]byte, error) {
+	x.Signatures = nil
+	return x.MarshalCBOR()
+}
+
+// Sign signs the consensus parameters, storing the signature to Signatures map.
+func (x *ConsensusParams) Sign(nodeID string, signer abcrypto.Signer) error {
+	if nodeID == "" {
+		return errors.New("node identifier is empty")
+	}
+	if signer == nil {
+		return ErrSignerIsNil
+	}
+	bs, err := x.SigBytes()
+	if err != nil {
+		return fmt.Errorf("failed to marshal consensus parameters: %w", err)
+	}
+	sig, err := signer.SignBytes(bs)
+	if err != nil {
+		return fmt.Errorf("signing failed: %w", err)
+	}
+	if x.Signatures == nil {
+		x.Signatures = make(SignatureMap)
+	}
+	x.Signatures[nodeID] = sig
+	return nil
+}
+
+/*
+Verify checks that all the "rootValidators" (and only them) have signed the
+consensus parameters.
+*/
+func (x *ConsensusParams) Verify(rootValidators []*NodeInfo) error {
+	bs, err := x.SigBytes()
+	if err != nil {
+		return fmt.Errorf("failed to marshal consensus parameters: %w", err)
+	}
+	if len(x.Signatures) != len(rootValidators) {
+		return fmt.Errorf("expected %d signatures, got %d", len(rootValidators), len(x.Signatures))
+	}
+	for _, n := range rootValidators {
+		sig, ok := x.Signatures[n.NodeID]
+		if !ok {
+			return fmt.Errorf("missing signature of the root validator %q", n.NodeID)
+		}
+		verifier, err := n.SigVerifier()
+		if err != nil {
+			return fmt.Errorf("root validator %q: %w", n.NodeID, err)
+		}
+		if err := verifier.VerifyBytes(sig, bs); err != nil {
+			return fmt.Errorf("invalid signature of the root validator %q: %w", n.NodeID, err)
+		}
+	}
+	return nil
+}
+
+func (x *ConsensusParams) GetVersion() ABVersion {
+	if x != nil && x.Version > 0 {
+		return x.Version
+	}
+	return 1
+}
+
+func (x *ConsensusParams) MarshalCBOR() ([]byte, error) {
+	type alias ConsensusParams
+	if x.Version == 0 {
+		x.Version = x.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(ConsensusParamsTag, (*alias)(x))
+}
+
+func (x *ConsensusParams) UnmarshalCBOR(data []byte) error {
+	type alias ConsensusParams
+	if err := cbor.UnmarshalTaggedValue(ConsensusParamsTag, data, (*alias)(x)); err != nil {
+		return fmt.Errorf("failed to unmarshal consensus parameters: %w", err)
+	}
+	return EnsureVersion(x, x.Version, 1)
+}
+
+/*
+Verify checks the genesis record of the shard:
+  - the shard configuration is valid, including the partition parameters which
+    are checked using "validate" (see PartitionDescriptionRecord.IsValidWith);
+  - the validators are the validators of the shard configuration and each of
+    them has signed the request to join the shard with given configuration;
+  - the genesis UC is valid, certified by the root chain "tb" and issued to the
+    shard with given configuration.
+*/
+func (x *GenesisPartitionRecord) Verify(tb RootTrustBase, algo crypto.Hash, validate PartitionParamsValidator) error {
+	if x == nil {
+		return errors.New("genesis partition record is nil")
+	}
+	if x.Version != 1 {
+		return ErrInvalidVersion(x)
+	}
+	pdr := x.PartitionDescription
+	if pdr == nil {
+		return ErrSystemDescriptionIsNil
+	}
+	if err := pdr.IsValidWith(validate); err != nil {
+		return fmt.Errorf("invalid partition description record: %w", err)
+	}
+	confHash, err := pdr.Hash(algo)
+	if err != nil {
+		return fmt.Errorf("calculating shard configuration hash: %w", err)
+	}
+
+	if len(x.Validators) == 0 {
+		return errors.New("validators list is empty")
+	}
+	nodes := make([]*NodeInfo, len(x.Validators))
+	for i, v := range x.Validators {
+		if err := v.Verify(); err != nil {
+			return fmt.Errorf("invalid validator at idx %d: %w", i, err)
+		}
+		if !bytes.Equal(v.ShardConfHash, confHash) {
+			return fmt.Errorf("validator %q has signed shard configuration %X, expected %X", v.NodeID, []byte(v.ShardConfHash), confHash)
+		}
+		nodes[i] = &NodeInfo{NodeID: v.NodeID, SigKey: v.SigKey}
+	}
+	if err := sameNodes(nodes, pdr.Validators); err != nil {
+		return fmt.Errorf("validators do not match the partition description record: %w", err)
+	}
+
+	if err := x.Certificate.Verify(tb, algo, pdr.PartitionID, confHash); err != nil {
+		return fmt.Errorf("invalid genesis certificate: %w", err)
+	}
+	if shardID := x.Certificate.GetShardID(); !shardID.Equal(pdr.ShardID) {
+		return fmt.Errorf("genesis certificate is for shard %q, expected %q", shardID, pdr.ShardID)
+	}
+	if x.Certificate.UnicitySeal.NetworkID != pdr.NetworkID {
+		return fmt.Errorf("genesis certificate is for network %d, expected %d", x.Certificate.UnicitySeal.NetworkID, pdr.NetworkID)
+	}
+	return nil
+}
+
+func (x *GenesisPartitionRecord) GetVersion() ABVersion {
+	if x != nil && x.Version > 0 {
+		return x.Version
+	}
+	return 1
+}
+
+func (x *GenesisPartitionRecord) MarshalCBOR() ([]byte, error) {
+	type alias GenesisPartitionRecord
+	if x.Version == 0 {
+		x.Version = x.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(GenesisPartitionRecordTag, (*alias)(x))
+}
+
+func (x *GenesisPartitionRecord) UnmarshalCBOR(data []byte) error {
+	type alias GenesisPartitionRecord
+	if err := cbor.UnmarshalTaggedValue(GenesisPartitionRecordTag, data, (*alias)(x)); err != nil {
+		return fmt.Errorf("failed to unmarshal genesis partition record: %w", err)
+	}
+	return EnsureVersion(x, x.Version, 1)
+}
+
+/*
+NewPartitionNode creates request of the node "nodeID" to join the shard described
+by "pdr", the request is signed by the "signer" (signing key of the node).
+*/
+func NewPartitionNode(nodeID string, signer abcrypto.Signer, pdr *PartitionDescriptionRecord, algo crypto.Hash) (*PartitionNode, error) {
+	if signer == nil {
+		return nil, ErrSignerIsNil
+	}
+	if pdr == nil {
+		return nil, ErrSystemDescriptionIsNil
+	}
+	verifier, err := signer.Verifier()
+	if err != nil {
+		return nil, fmt.Errorf("acquiring verifier: %w", err)
+	}
+	sigKey, err := verifier.MarshalPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling public key: %w", err)
+	}
+	confHash, err := pdr.Hash(algo)
+	if err != nil {
+		return nil, fmt.Errorf("calculating shard configuration hash: %w", err)
+	}
+	n := &PartitionNode{Version: 1, NodeID: nodeID, SigKey: sigKey, ShardConfHash: confHash}
+	if err := n.Sign(signer); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (x *PartitionNode) IsValid() error {
+	if x == nil {
+		return errors.New("partition node is nil")
+	}
+	if x.Version != 1 {
+		return ErrInvalidVersion(x)
+	}
+	if x.NodeID == "" {
+		return errors.New("node identifier is empty")
+	}
+	if len(x.SigKey) == 0 {
+		return errors.New("signing key is empty")
+	}
+	if len(x.ShardConfHash) == 0 {
+		return errors.New("shard configuration hash is empty")
+	}
+	if len(x.Signature) == 0 {
+		return errors.New("signature is empty")
+	}
+	return nil
+}
+
+// SigBytes serializes all fields except signature (used for sign and verify).
+func (x PartitionNode) SigBytes() ([]byte, error) {
+	x.Signature = nil
+	return x.MarshalCBOR()
+}
+
+// Sign signs the request with the signing key of the node.
+func (x *PartitionNode) Sign(signer abcrypto.Signer) error {
+	if signer == nil {
+		return ErrSignerIsNil
+	}
+	bs, err := x.SigBytes()
+	if err != nil {
+		return fmt.Errorf("failed to marshal partition node: %w", err)
+	}
+	if x.Signature, err = signer.SignBytes(bs); err != nil {
+		return fmt.Errorf("signing failed: %w", err)
+	}
+	return nil
+}
+
+// Verify checks that the request is valid and signed with the signing key of the node.
+func (x *PartitionNode) Verify() error {
+	if err := x.IsValid(); err != nil {
+		return err
+	}
+	verifier, err := abcrypto.NewVerifierSecp256k1(x.SigKey)
+	if err != nil {
+		return fmt.Errorf("invalid signing key: %w", err)
+	}
+	bs, err := x.SigBytes()
+	if err != nil {
+		return fmt.Errorf("failed to marshal partition node: %w", err)
+	}
+	if err := verifier.VerifyBytes(x.Signature, bs); err != nil {
+		return fmt.Errorf("invalid signature of the node %q: %w", x.NodeID, err)
+	}
+	return nil
+}
+
+func (x *PartitionNode) GetVersion() ABVersion {
+	if x != nil && x.Version > 0 {
+		return x.Version
+	}
+	return 1
+}
+
+func (x *PartitionNode) MarshalCBOR() ([]byte, error) {
+	type alias PartitionNode
+	if x.Version == 0 {
+		x.Version = x.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(PartitionNodeTag, (*alias)(x))
+}
+
+func (x *PartitionNode) UnmarshalCBOR(data []byte) error {
+	type alias PartitionNode
+	if err := cbor.UnmarshalTaggedValue(PartitionNodeTag, data, (*alias)(x)); err != nil {
+		return fmt.Errorf("failed to unmarshal partition node: %w", err)
+	}
+	return EnsureVersion(x, x.Version, 1)
+}
+
+/*
+sameNodes checks that both lists contain the same nodes (node ID and signing key),
+the order of the nodes is not important but neither of the lists may contain the
+same node more than once.
+*/
+func sameNodes(a, b []*NodeInfo) error {
+	if len(a) != len(b) {
+		return fmt.Errorf("expected %d nodes, got %d", len(b), len(a))
+	}
+	keys := make(map[string][]byte, len(b))
+	for _, n := range b {
+		if _, ok := keys[n.NodeID]; ok {
+			return fmt.Errorf("duplicate node %q in the expected list", n.NodeID)
+		}
+		keys[n.NodeID] = n.SigKey
+	}
+	seen := make(map[string]struct{}, len(a))
+	for _, n := range a {
+		if _, ok := seen[n.NodeID]; ok {
+			return fmt.Errorf("duplicate node %q", n.NodeID)
+		}
+		seen[n.NodeID] = struct{}{}
+		key, ok := keys[n.NodeID]
+		if !ok {
+			return fmt.Errorf("node %q is not in the list", n.NodeID)
+		}
+		if !bytes.Equal(key, n.SigKey) {
+			return fmt.Errorf("node %q signing key mismatch", n.NodeID)
+		}
+	}
+	return nil
+}
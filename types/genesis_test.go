@@ -0,0 +1,502 @@
+package types
+
+import (
+	"crypto"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	abcrypto "github.com/alphabill-org/alphabill-go-base/crypto"
+	testsig "github.com/alphabill-org/alphabill-go-base/testutils/sig"
+)
+
+// validator of the partition params for the shard configurations without params
+var noPartitionParams = PartitionParamsValidators{}.Validate
+
+func Test_RootGenesis(t *testing.T) {
+	rootSigner, rootVerifier := testsig.CreateSignerAndVerifier(t)
+	nodeSigner, nodeVerifier := testsig.CreateSignerAndVerifier(t)
+	rootNode := newNodeInfo(t, "root1", rootVerifier)
+	tb, err := NewTrustBaseGenesis(NetworkMainNet, []*NodeInfo{rootNode})
+	require.NoError(t, err)
+
+	newGenesis := func(t *testing.T) *RootGenesis {
+		consensus := &ConsensusParams{
+			Version:             1,
+			TotalRootValidators: 1,
+			BlockRateMs:         900,
+			ConsensusTimeoutMs:  10000,
+			HashAlgorithm:       uint32(crypto.SHA256),
+		}
+		require.NoError(t, consensus.Sign(rootNode.NodeID, rootSigner))
+		return &RootGenesis{
+			Version: 1,
+			Root: &GenesisRootRecord{
+				Version:        1,
+				RootValidators: []*NodeInfo{rootNode},
+				Consensus:      consensus,
+			},
+			Partitions: []*GenesisPartitionRecord{
+				newGenesisPartitionRecord(t, rootNode.NodeID, rootSigner, "node1", nodeSigner, nodeVerifier),
+			},
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		rg := newGenesis(t)
+		require.NoError(t, rg.Verify(tb, noPartitionParams))
+		require.Same(t, rg.Partitions[0], rg.PartitionRecord(partitionID, ShardID{}))
+		require.Nil(t, rg.PartitionRecord(partitionID+1, ShardID{}))
+	})
+
+	t.Run("invalid root record", func(t *testing.T) {
+		var rg *RootGenesis
+		require.EqualError(t, rg.Verify(tb, noPartitionParams), "root genesis is nil")
+
+		rg = newGenesis(t)
+		require.ErrorIs(t, rg.Verify(nil, noPartitionParams), ErrRootValidatorInfoMissing)
+
+		rg.Version = 2
+		require.EqualError(t, rg.Verify(tb, noPartitionParams), "invalid version (type *types.RootGenesis)")
+
+		rg = newGenesis(t)
+		rg.Root = nil
+		require.EqualError(t, rg.Verify(tb, noPartitionParams), "invalid root genesis record: root genesis record is nil")
+
+		// trust base with different root validator
+		_, verifier := testsig.CreateSignerAndVerifier(t)
+		tb2, err := NewTrustBaseGenesis(NetworkMainNet, []*NodeInfo{newNodeInfo(t, "root1", verifier)})
+		require.NoError(t, err)
+		rg = newGenesis(t)
+		require.EqualError(t, rg.Verify(tb2, noPartitionParams), `root validators do not match the trust base: node "root1" signing key mismatch`)
+	})
+
+	t.Run("invalid partitions", func(t *testing.T) {
+		rg := newGenesis(t)
+		rg.Partitions = nil
+		require.EqualError(t, rg.Verify(tb, noPartitionParams), "partitions list is empty")
+
+		rg = newGenesis(t)
+		rg.Partitions = append(rg.Partitions, rg.Partitions[0])
+		require.EqualError(t, rg.Verify(tb, noPartitionParams), `duplicate genesis record for partition 01000001 shard ""`)
+
+		rg = newGenesis(t)
+		rg.Partitions[0].Version = 0
+		require.EqualError(t, rg.Verify(tb, noPartitionParams), "invalid genesis partition record at idx 0: invalid version (type *types.GenesisPartitionRecord)")
+
+		// partition is certified by the root chain of other network
+		tb2, err := NewTrustBaseGenesis(NetworkLocal, []*NodeInfo{rootNode})
+		require.NoError(t, err)
+		rg = newGenesis(t)
+		require.EqualError(t, rg.Verify(tb2, noPartitionParams), "partition 01000001 is of network 1, trust base is of network 3")
+
+		// partition params are checked by the validator of the partition type
+		rg = newGenesis(t)
+		rg.Partitions[0].PartitionDescription.PartitionParams = map[string]string{"unknown": "1"}
+		require.EqualError(t, rg.Verify(tb, noPartitionParams), "invalid genesis partition record at idx 0: invalid partition description record: invalid partition parameters: no partition parameters validator for partition type 1")
+		// without validator the partition params are not checked
+		rg = newGenesis(t)
+		require.NoError(t, rg.Verify(tb, nil))
+	})
+
+	t.Run("CBOR encoding", func(t *testing.T) {
+		rg := newGenesis(t)
+		data, err := cbor.Marshal(rg)
+		require.NoError(t, err)
+		var rg2 RootGenesis
+		require.NoError(t, cbor.Unmarshal(data, &rg2))
+		require.NoError(t, rg2.Verify(tb, noPartitionParams))
+
+		rg2.Version = 2
+		data, err = cbor.Marshal(&rg2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &rg2), "invalid version (type *types.RootGenesis), expected 1, got 2")
+	})
+
+	t.Run("JSON encoding", func(t *testing.T) {
+		rg := newGenesis(t)
+		data, err := json.Marshal(rg)
+		require.NoError(t, err)
+		var rg2 RootGenesis
+		require.NoError(t, json.Unmarshal(data, &rg2))
+		require.NoError(t, rg2.Verify(tb, noPartitionParams))
+	})
+}
+
+func Test_GenesisRootRecord_IsValid(t *testing.T) {
+	signer1, verifier1 := testsig.CreateSignerAndVerifier(t)
+	signer2, verifier2 := testsig.CreateSignerAndVerifier(t)
+	node1 := newNodeInfo(t, "root1", verifier1)
+	node2 := newNodeInfo(t, "root2", verifier2)
+
+	newRecord := func(t *testing.T) *GenesisRootRecord {
+		consensus := &ConsensusParams{
+			Version:             1,
+			TotalRootValidators: 2,
+			BlockRateMs:         900,
+			ConsensusTimeoutMs:  10000,
+			HashAlgorithm:       uint32(crypto.SHA256),
+		}
+		require.NoError(t, consensus.Sign(node1.NodeID, signer1))
+		require.NoError(t, consensus.Sign(node2.NodeID, signer2))
+		return &GenesisRootRecord{Version: 1, RootValidators: []*NodeInfo{node1, node2}, Consensus: consensus}
+	}
+	require.NoError(t, newRecord(t).IsValid())
+
+	var rec *GenesisRootRecord
+	require.EqualError(t, rec.IsValid(), "root genesis record is nil")
+
+	rec = newRecord(t)
+	rec.Version = 0
+	require.EqualError(t, rec.IsValid(), "invalid version (type *types.GenesisRootRecord)")
+
+	rec = newRecord(t)
+	rec.RootValidators = nil
+	require.EqualError(t, rec.IsValid(), "root validators list is empty")
+
+	rec = newRecord(t)
+	rec.RootValidators = []*NodeInfo{node1, node1}
+	require.EqualError(t, rec.IsValid(), `duplicate root validator "root1"`)
+
+	rec = newRecord(t)
+	rec.RootValidators = []*NodeInfo{node1, {NodeID: "root3", SigKey: node2.SigKey}}
+	require.EqualError(t, rec.IsValid(), "invalid root validator at idx 1: node must have stake == 1")
+
+	rec = newRecord(t)
+	rec.Consensus = nil
+	require.EqualError(t, rec.IsValid(), "invalid consensus parameters: consensus parameters are nil")
+
+	rec = newRecord(t)
+	rec.RootValidators = []*NodeInfo{node1}
+	require.EqualError(t, rec.IsValid(), "consensus parameters require 2 root validators, got 1")
+
+	// parameters changed after signing
+	rec = newRecord(t)
+	rec.Consensus.BlockRateMs = 1000
+	require.ErrorContains(t, rec.IsValid(), `consensus parameters: invalid signature of the root validator "root1"`)
+
+	// one of the validators hasn't signed
+	rec = newRecord(t)
+	delete(rec.Consensus.Signatures, node2.NodeID)
+	require.EqualError(t, rec.IsValid(), "consensus parameters: expected 2 signatures, got 1")
+	rec.Consensus.Signatures["root3"] = rec.Consensus.Signatures[node1.NodeID]
+	require.EqualError(t, rec.IsValid(), `consensus parameters: missing signature of the root validator "root2"`)
+}
+
+func Test_ConsensusParams(t *testing.T) {
+	newParams := func() *ConsensusParams {
+		return &ConsensusParams{
+			Version:             1,
+			TotalRootValidators: 1,
+			BlockRateMs:         900,
+			ConsensusTimeoutMs:  10000,
+			HashAlgorithm:       uint32(crypto.SHA256),
+		}
+	}
+
+	t.Run("IsValid", func(t *testing.T) {
+		require.NoError(t, newParams().IsValid())
+
+		var cp *ConsensusParams
+		require.EqualError(t, cp.IsValid(), "consensus parameters are nil")
+
+		cp = newParams()
+		cp.Version = 2
+		require.EqualError(t, cp.IsValid(), "invalid version (type *types.ConsensusParams)")
+
+		cp = newParams()
+		cp.TotalRootValidators = 0
+		require.EqualError(t, cp.IsValid(), "total root validators must be greater than zero")
+
+		cp = newParams()
+		cp.BlockRateMs = 0
+		require.EqualError(t, cp.IsValid(), "block rate must be greater than zero")
+
+		cp = newParams()
+		cp.ConsensusTimeoutMs = cp.BlockRateMs
+		require.EqualError(t, cp.IsValid(), "consensus timeout 900 ms must be greater than block rate 900 ms")
+
+		cp = newParams()
+		cp.HashAlgorithm = uint32(crypto.SHA512)
+		require.EqualError(t, cp.IsValid(), "unsupported hash algorithm 7")
+	})
+
+	t.Run("Sign", func(t *testing.T) {
+		signer, _ := testsig.CreateSignerAndVerifier(t)
+		cp := newParams()
+		require.ErrorIs(t, cp.Sign("root1", nil), ErrSignerIsNil)
+		require.EqualError(t, cp.Sign("", signer), "node identifier is empty")
+		require.NoError(t, cp.Sign("root1", signer))
+		require.Len(t, cp.Signatures, 1)
+
+		// signatures are not part of the signed data
+		sb1, err := cp.SigBytes()
+		require.NoError(t, err)
+		cp.Signatures = nil
+		sb2, err := cp.SigBytes()
+		require.NoError(t, err)
+		require.Equal(t, sb1, sb2)
+	})
+
+	t.Run("CBOR encoding", func(t *testing.T) {
+		signer, _ := testsig.CreateSignerAndVerifier(t)
+		cp := newParams()
+		require.NoError(t, cp.Sign("root1", signer))
+		data, err := cbor.Marshal(cp)
+		require.NoError(t, err)
+		var cp2 ConsensusParams
+		require.NoError(t, cbor.Unmarshal(data, &cp2))
+		require.Equal(t, cp, &cp2)
+
+		cp2.Version = 2
+		data, err = cbor.Marshal(&cp2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &cp2), "invalid version (type *types.ConsensusParams), expected 1, got 2")
+	})
+}
+
+func Test_GenesisPartitionRecord_Verify(t *testing.T) {
+	rootSigner, rootVerifier := testsig.CreateSignerAndVerifier(t)
+	nodeSigner, nodeVerifier := testsig.CreateSignerAndVerifier(t)
+	tb := NewTrustBase(t, rootVerifier)
+
+	newRecord := func(t *testing.T) *GenesisPartitionRecord {
+		return newGenesisPartitionRecord(t, "test", rootSigner, "node1", nodeSigner, nodeVerifier)
+	}
+	require.NoError(t, newRecord(t).Verify(tb, crypto.SHA256, noPartitionParams))
+
+	var rec *GenesisPartitionRecord
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), "genesis partition record is nil")
+
+	rec = newRecord(t)
+	rec.PartitionDescription = nil
+	require.ErrorIs(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), ErrSystemDescriptionIsNil)
+
+	rec = newRecord(t)
+	rec.PartitionDescription.T2Timeout = 0
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), "invalid partition description record: t2 timeout value out of allowed range: 0s")
+
+	rec = newRecord(t)
+	rec.Validators = nil
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), "validators list is empty")
+
+	rec = newRecord(t)
+	rec.Validators[0].Signature = nil
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), "invalid validator at idx 0: signature is empty")
+
+	// node has signed different shard configuration
+	rec = newRecord(t)
+	rec.Validators[0].ShardConfHash = make([]byte, 32)
+	require.NoError(t, rec.Validators[0].Sign(nodeSigner))
+	require.ErrorContains(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), `validator "node1" has signed shard configuration 0000`)
+
+	// validator is not in the shard configuration
+	signer2, verifier2 := testsig.CreateSignerAndVerifier(t)
+	rec = newRecord(t)
+	node2, err := NewPartitionNode("node2", signer2, rec.PartitionDescription, crypto.SHA256)
+	require.NoError(t, err)
+	rec.Validators = append(rec.Validators, node2)
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), "validators do not match the partition description record: expected 1 nodes, got 2")
+	rec.PartitionDescription.Validators = append(rec.PartitionDescription.Validators, newNodeInfo(t, "node3", verifier2))
+	// UC has been issued for the original configuration so update node signatures
+	for _, v := range rec.Validators {
+		v.ShardConfHash = doHash(t, rec.PartitionDescription)
+	}
+	require.NoError(t, rec.Validators[0].Sign(nodeSigner))
+	require.NoError(t, rec.Validators[1].Sign(signer2))
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), `validators do not match the partition description record: node "node2" is not in the list`)
+
+	// the same validator twice, the other validator of the shard configuration hasn't signed
+	rec = newRecord(t)
+	rec.PartitionDescription.Validators = append(rec.PartitionDescription.Validators, newNodeInfo(t, "node2", verifier2))
+	rec.Validators[0].ShardConfHash = doHash(t, rec.PartitionDescription)
+	require.NoError(t, rec.Validators[0].Sign(nodeSigner))
+	rec.Validators = append(rec.Validators, rec.Validators[0])
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), `validators do not match the partition description record: duplicate node "node1"`)
+
+	// UC is for different shard configuration
+	rec = newRecord(t)
+	rec.PartitionDescription.Epoch = 1
+	rec.PartitionDescription.EpochStart = 10
+	rec.Validators[0].ShardConfHash = doHash(t, rec.PartitionDescription)
+	require.NoError(t, rec.Validators[0].Sign(nodeSigner))
+	require.ErrorContains(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), "invalid genesis certificate: ")
+
+	// UC is signed by other root chain
+	rec = newRecord(t)
+	_, verifier3 := testsig.CreateSignerAndVerifier(t)
+	require.ErrorContains(t, rec.Verify(NewTrustBase(t, verifier3), crypto.SHA256, noPartitionParams), "invalid genesis certificate: ")
+
+	// UC is issued by the root chain of different network
+	rec = newRecord(t)
+	rec.Certificate.UnicitySeal.NetworkID = NetworkLocal
+	require.NoError(t, rec.Certificate.UnicitySeal.Sign("test", rootSigner))
+	require.EqualError(t, rec.Verify(tb, crypto.SHA256, noPartitionParams), "genesis certificate is for network 3, expected 1")
+
+	t.Run("CBOR encoding", func(t *testing.T) {
+		rec := newRecord(t)
+		data, err := cbor.Marshal(rec)
+		require.NoError(t, err)
+		var rec2 GenesisPartitionRecord
+		require.NoError(t, cbor.Unmarshal(data, &rec2))
+		require.NoError(t, rec2.Verify(tb, crypto.SHA256, noPartitionParams))
+
+		rec2.Version = 2
+		data, err = cbor.Marshal(&rec2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &rec2), "invalid version (type *types.GenesisPartitionRecord), expected 1, got 2")
+	})
+}
+
+func Test_PartitionNode(t *testing.T) {
+	signer, _ := testsig.CreateSignerAndVerifier(t)
+	pdr := &PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       NetworkMainNet,
+		PartitionID:     partitionID,
+		PartitionTypeID: 1,
+		TypeIDLen:       8,
+		UnitIDLen:       256,
+		T2Timeout:       2500 * time.Millisecond,
+	}
+
+	t.Run("NewPartitionNode", func(t *testing.T) {
+		n, err := NewPartitionNode("node1", nil, pdr, crypto.SHA256)
+		require.ErrorIs(t, err, ErrSignerIsNil)
+		require.Nil(t, n)
+
+		n, err = NewPartitionNode("node1", signer, nil, crypto.SHA256)
+		require.ErrorIs(t, err, ErrSystemDescriptionIsNil)
+		require.Nil(t, n)
+
+		n, err = NewPartitionNode("node1", signer, pdr, crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, "node1", n.NodeID)
+		require.EqualValues(t, doHash(t, pdr), n.ShardConfHash)
+		require.NoError(t, n.Verify())
+	})
+
+	t.Run("IsValid", func(t *testing.T) {
+		var n *PartitionNode
+		require.EqualError(t, n.IsValid(), "partition node is nil")
+
+		newNode := func() *PartitionNode {
+			n, err := NewPartitionNode("node1", signer, pdr, crypto.SHA256)
+			require.NoError(t, err)
+			return n
+		}
+		n = newNode()
+		n.Version = 0
+		require.EqualError(t, n.IsValid(), "invalid version (type *types.PartitionNode)")
+
+		n = newNode()
+		n.NodeID = ""
+		require.EqualError(t, n.IsValid(), "node identifier is empty")
+
+		n = newNode()
+		n.SigKey = nil
+		require.EqualError(t, n.IsValid(), "signing key is empty")
+
+		n = newNode()
+		n.ShardConfHash = nil
+		require.EqualError(t, n.IsValid(), "shard configuration hash is empty")
+
+		n = newNode()
+		n.Signature = nil
+		require.EqualError(t, n.IsValid(), "signature is empty")
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		n, err := NewPartitionNode("node1", signer, pdr, crypto.SHA256)
+		require.NoError(t, err)
+		n.NodeID = "node2"
+		require.ErrorContains(t, n.Verify(), `invalid signature of the node "node2"`)
+
+		// signed by other key
+		signer2, _ := testsig.CreateSignerAndVerifier(t)
+		n.NodeID = "node1"
+		require.NoError(t, n.Sign(signer2))
+		require.ErrorContains(t, n.Verify(), `invalid signature of the node "node1"`)
+
+		n.SigKey = []byte{1, 2, 3}
+		require.ErrorContains(t, n.Verify(), "invalid signing key")
+	})
+
+	t.Run("CBOR encoding", func(t *testing.T) {
+		n, err := NewPartitionNode("node1", signer, pdr, crypto.SHA256)
+		require.NoError(t, err)
+		data, err := cbor.Marshal(n)
+		require.NoError(t, err)
+		var n2 PartitionNode
+		require.NoError(t, cbor.Unmarshal(data, &n2))
+		require.Equal(t, n, &n2)
+		require.NoError(t, n2.Verify())
+
+		n2.Version = 2
+		data, err = cbor.Marshal(&n2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &n2), "invalid version (type *types.PartitionNode), expected 1, got 2")
+	})
+}
+
+func Test_sameNodes(t *testing.T) {
+	a := &NodeInfo{NodeID: "A", SigKey: []byte{1}}
+	b := &NodeInfo{NodeID: "B", SigKey: []byte{2}}
+
+	require.NoError(t, sameNodes([]*NodeInfo{a, b}, []*NodeInfo{b, a}))
+	require.EqualError(t, sameNodes([]*NodeInfo{a}, []*NodeInfo{a, b}), "expected 2 nodes, got 1")
+	require.EqualError(t, sameNodes([]*NodeInfo{a, a}, []*NodeInfo{a, b}), `duplicate node "A"`)
+	require.EqualError(t, sameNodes([]*NodeInfo{a, b}, []*NodeInfo{a, a}), `duplicate node "A" in the expected list`)
+	require.EqualError(t, sameNodes([]*NodeInfo{a, {NodeID: "B", SigKey: []byte{3}}}, []*NodeInfo{a, b}), `node "B" signing key mismatch`)
+	require.EqualError(t, sameNodes([]*NodeInfo{a, {NodeID: "C"}}, []*NodeInfo{a, b}), `node "C" is not in the list`)
+}
+
+func newNodeInfo(t *testing.T, nodeID string, verifier abcrypto.Verifier) *NodeInfo {
+	t.Helper()
+	sigKey, err := verifier.MarshalPublicKey()
+	require.NoError(t, err)
+	return &NodeInfo{NodeID: nodeID, SigKey: sigKey, Stake: 1}
+}
+
+/*
+newGenesisPartitionRecord creates genesis record of the single shard partition
+with single validator "nodeID", the genesis UC is signed by the root node "rootID"
+using "rootSigner".
+*/
+func newGenesisPartitionRecord(t *testing.T, rootID string, rootSigner abcrypto.Signer, nodeID string, nodeSigner abcrypto.Signer, nodeVerifier abcrypto.Verifier) *GenesisPartitionRecord {
+	t.Helper()
+	pdr := &PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       NetworkMainNet,
+		PartitionID:     partitionID,
+		PartitionTypeID: 1,
+		TypeIDLen:       8,
+		UnitIDLen:       256,
+		T2Timeout:       2500 * time.Millisecond,
+		Validators:      []*NodeInfo{newNodeInfo(t, nodeID, nodeVerifier)},
+	}
+	node, err := NewPartitionNode(nodeID, nodeSigner, pdr, crypto.SHA256)
+	require.NoError(t, err)
+
+	ir := &InputRecord{
+		Version:      1,
+		PreviousHash: make([]byte, 32),
+		Hash:         make([]byte, 32),
+		SummaryValue: []byte{0, 0, 0, 0},
+		RoundNumber:  1,
+		Timestamp:    NewTimestamp(),
+	}
+	uc := createUnicityCertificate(t, rootID, rootSigner, ir, make([]byte, 32), pdr)
+	uc.UnicitySeal.NetworkID = pdr.NetworkID
+	require.NoError(t, uc.UnicitySeal.Sign(rootID, rootSigner))
+
+	return &GenesisPartitionRecord{
+		Version:              1,
+		Validators:           []*PartitionNode{node},
+		Certificate:          uc,
+		PartitionDescription: pdr,
+	}
+}
@@ -27,7 +27,7 @@ type InputRecord struct {
 	Timestamp       uint64    `json:"timestamp"`                // reference time for transaction validation
 	BlockHash       hex.Bytes `json:"blockHash"`                // hash of the block
 	SumOfEarnedFees uint64    `json:"sumOfEarnedFees"`          // sum of the actual fees over all transaction records in the block
-	ETHash          hex.Bytes `json:"executedTransactionsHash"` // hash of executed transactions
+	ETHash          hex.Bytes `json:"executedTransactionsHash"` // hash of executed transactions, calculated by the node
 }
 
 func EqualIR(a, b *InputRecord) (bool, error) {
@@ -0,0 +1,43 @@
+package types
+
+import "fmt"
+
+/*
+LockReason is the value of the "LockStatus" field of the lock transactions (lock
+fee credit, lock token). It tells why the unit was locked, ie which multi-step
+operation the lock belongs to. Zero value means "not locked" and is not a valid
+reason for the lock transaction.
+*/
+type LockReason uint64
+
+const (
+	LockReasonAddFees     LockReason = 1 // unit is used in the "add fee credit" flow
+	LockReasonReclaimFees LockReason = 2 // unit is used in the "reclaim fee credit" flow
+	LockReasonCollectDust LockReason = 3 // unit is the target of the dust collection (swap, burn-join)
+	LockReasonManual      LockReason = 4 // unit was locked by the user
+)
+
+func (r LockReason) String() string {
+	switch r {
+	case 0:
+		return "unlocked"
+	case LockReasonAddFees:
+		return "add fees"
+	case LockReasonReclaimFees:
+		return "reclaim fees"
+	case LockReasonCollectDust:
+		return "collect dust"
+	case LockReasonManual:
+		return "manual"
+	default:
+		return fmt.Sprintf("LockReason(%d)", uint64(r))
+	}
+}
+
+// IsValid returns error when "r" is not one of the known lock reasons.
+func (r LockReason) IsValid() error {
+	if r < LockReasonAddFees || r > LockReasonManual {
+		return fmt.Errorf("invalid lock reason %d", uint64(r))
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockReason(t *testing.T) {
+	require.Equal(t, "unlocked", LockReason(0).String())
+	require.Equal(t, "add fees", LockReasonAddFees.String())
+	require.Equal(t, "reclaim fees", LockReasonReclaimFees.String())
+	require.Equal(t, "collect dust", LockReasonCollectDust.String())
+	require.Equal(t, "manual", LockReasonManual.String())
+	require.Equal(t, "LockReason(42)", LockReason(42).String())
+
+	require.EqualError(t, LockReason(0).IsValid(), "invalid lock reason 0")
+	require.EqualError(t, LockReason(5).IsValid(), "invalid lock reason 5")
+	for r := LockReasonAddFees; r <= LockReasonManual; r++ {
+		require.NoError(t, r.IsValid())
+	}
+}
@@ -1,9 +1,12 @@
 package types
 
 import (
+	"bytes"
 	"crypto"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/alphabill-org/alphabill-go-base/cbor"
@@ -15,13 +18,6 @@ var (
 	ErrSystemDescriptionIsNil = errors.New("system description record is nil")
 )
 
-type PartitionType struct {
-	// This is just a placeholder right now so that we can add
-	// the field into the PartitionDescriptionRecord - for the fast track
-	// solution we do not expect to have non nil PartitionType
-	// so the actual field list is not needed...
-}
-
 type PartitionDescriptionRecord struct {
 	_           struct{}    `cbor:",toarray"`
 	Version     ABVersion   `json:"version"`
@@ -34,15 +30,43 @@ type PartitionDescriptionRecord struct {
 	TypeIDLen       uint32          `json:"typeIdLength"`
 	UnitIDLen       uint32          `json:"unitIdLength"`
 
-	SummaryTrustBase hex.Bytes      `json:"summaryTrustBase"`
-	T2Timeout        time.Duration  `json:"t2timeout"`
-	FeeCreditBill    *FeeCreditBill `json:"feeCreditBill"`
-	//todo: Transaction cost function
-	PartitionParams map[string]string `json:"partitionParams,omitempty"`
+	SummaryTrustBase hex.Bytes         `json:"summaryTrustBase"`
+	T2Timeout        time.Duration     `json:"t2timeout"`
+	FeeCreditBill    *FeeCreditBill    `json:"feeCreditBill"`
+	PartitionParams  map[string]string `json:"partitionParams,omitempty"`
 
 	Epoch      uint64      `json:"epoch"`
 	EpochStart uint64      `json:"epochStart"` // Root round when this epoch is activated
 	Validators []*NodeInfo `json:"validators"`
+
+	// TxCostFunction is supported since version 2, nil when the partition
+	// doesn't charge for the transactions.
+	TxCostFunction *TxCostFunction `json:"txCostFunction,omitempty"`
+}
+
+/*
+pdrV1 is the version 1 encoding of the PartitionDescriptionRecord, ie without the
+fields added in the later versions. Must have the same fields as the PDR so that
+the types are convertible.
+*/
+type pdrV1 struct {
+	_                struct{}          `cbor:",toarray"`
+	Version          ABVersion         `json:"version"`
+	NetworkID        NetworkID         `json:"networkId"`
+	PartitionID      PartitionID       `json:"partitionId"`
+	ShardID          ShardID           `json:"shardId"`
+	PartitionTypeID  PartitionTypeID   `json:"partitionTypeId"`
+	PartitionType    *PartitionType    `json:"partitionType,omitempty"`
+	TypeIDLen        uint32            `json:"typeIdLength"`
+	UnitIDLen        uint32            `json:"unitIdLength"`
+	SummaryTrustBase hex.Bytes         `json:"summaryTrustBase"`
+	T2Timeout        time.Duration     `json:"t2timeout"`
+	FeeCreditBill    *FeeCreditBill    `json:"feeCreditBill"`
+	PartitionParams  map[string]string `json:"partitionParams,omitempty"`
+	Epoch            uint64            `json:"epoch"`
+	EpochStart       uint64            `json:"epochStart"`
+	Validators       []*NodeInfo       `json:"validators"`
+	TxCostFunction   *TxCostFunction   `cbor:"-"`
 }
 
 type FeeCreditBill struct {
@@ -51,11 +75,78 @@ type FeeCreditBill struct {
 	OwnerPredicate PredicateBytes `json:"ownerPredicate"`
 }
 
+/*
+Clone returns deep copy of the PDR, ie the copy doesn't share any memory with
+the original so either of them can be modified without affecting the other.
+*/
+func (pdr *PartitionDescriptionRecord) Clone() *PartitionDescriptionRecord {
+	if pdr == nil {
+		return nil
+	}
+	c := *pdr
+	c.ShardID = pdr.ShardID.Clone()
+	c.SummaryTrustBase = bytes.Clone(pdr.SummaryTrustBase)
+	c.PartitionParams = maps.Clone(pdr.PartitionParams)
+	if pdr.PartitionType != nil {
+		pt := *pdr.PartitionType
+		pt.UnitTypes = cloneEach(pt.UnitTypes, func(ut UnitTypeDescription) UnitTypeDescription { return ut })
+		pt.TxTypes = cloneEach(pt.TxTypes, func(tt TxTypeDescription) TxTypeDescription {
+			tt.UnitTypes = slices.Clone(tt.UnitTypes)
+			return tt
+		})
+		pt.Modules = cloneEach(pt.Modules, func(m WasmModule) WasmModule {
+			m.Hash = bytes.Clone(m.Hash)
+			return m
+		})
+		c.PartitionType = &pt
+	}
+	if pdr.FeeCreditBill != nil {
+		c.FeeCreditBill = &FeeCreditBill{
+			UnitID:         bytes.Clone(pdr.FeeCreditBill.UnitID),
+			OwnerPredicate: bytes.Clone(pdr.FeeCreditBill.OwnerPredicate),
+		}
+	}
+	if pdr.TxCostFunction != nil {
+		cf := *pdr.TxCostFunction
+		cf.TxTypeCost = maps.Clone(cf.TxTypeCost)
+		c.TxCostFunction = &cf
+	}
+	if pdr.Validators != nil {
+		// NodeInfo can't be copied by value as it contains the cached verifier
+		c.Validators = make([]*NodeInfo, len(pdr.Validators))
+		for i, v := range pdr.Validators {
+			if v != nil {
+				c.Validators[i] = &NodeInfo{NodeID: v.NodeID, SigKey: bytes.Clone(v.SigKey), Stake: v.Stake}
+			}
+		}
+	}
+	return &c
+}
+
+// cloneEach returns copy of the slice "s" where the items are copied using "cp".
+func cloneEach[T any](s []*T, cp func(T) T) []*T {
+	if s == nil {
+		return nil
+	}
+	res := make([]*T, len(s))
+	for i, v := range s {
+		if v != nil {
+			c := cp(*v)
+			res[i] = &c
+		}
+	}
+	return res
+}
+
+/*
+IsValid checks the shard configuration, except the partition specific parameters
+(PartitionParams) which are opaque to this package, see IsValidWith.
+*/
 func (pdr *PartitionDescriptionRecord) IsValid() error {
 	if pdr == nil {
 		return ErrSystemDescriptionIsNil
 	}
-	if pdr.Version != 1 {
+	if pdr.Version != 1 && pdr.Version != 2 {
 		return ErrInvalidVersion(pdr)
 	}
 	if pdr.NetworkID == 0 {
@@ -64,13 +155,14 @@ func (pdr *PartitionDescriptionRecord) IsValid() error {
 	if pdr.PartitionID == 0 {
 		return fmt.Errorf("invalid partition identifier: %s", pdr.PartitionID)
 	}
-	// we currently do not support custom partition types, so allow
-	// only non-zero PartitionTypeIDs
+	// custom partition type is described by the PartitionType, built in
+	// partition types are identified by non-zero PartitionTypeID
 	if pdr.PartitionTypeID == 0 {
-		return fmt.Errorf("invalid partition type identifier: %d", pdr.PartitionTypeID)
-	}
-	if pdr.PartitionType != nil {
-		return errors.New("custom PartitionType is not supported")
+		if pdr.PartitionType == nil {
+			return errors.New("partition type must be defined when partition type identifier is 0")
+		}
+	} else if pdr.PartitionType != nil {
+		return fmt.Errorf("custom partition type must not be defined for partition type identifier %d", pdr.PartitionTypeID)
 	}
 	if uint(pdr.UnitIDLen) <= pdr.ShardID.Length() {
 		return fmt.Errorf("shard id length %d must be shorter than unit id length %d", pdr.ShardID.Length(), pdr.UnitIDLen)
@@ -88,9 +180,22 @@ func (pdr *PartitionDescriptionRecord) IsValid() error {
 	if pdr.UnitIDLen%8 != 0 {
 		return fmt.Errorf("unit id length must be in full bytes, got %d bytes and %d bits", pdr.UnitIDLen/8, pdr.UnitIDLen%8)
 	}
+	if pdr.PartitionType != nil {
+		if err := pdr.PartitionType.IsValid(pdr.TypeIDLen); err != nil {
+			return fmt.Errorf("invalid partition type: %w", err)
+		}
+	}
 	if pdr.T2Timeout < 800*time.Millisecond || pdr.T2Timeout > 10*time.Second {
 		return fmt.Errorf("t2 timeout value out of allowed range: %s", pdr.T2Timeout)
 	}
+	if pdr.TxCostFunction != nil {
+		if pdr.Version < 2 {
+			return fmt.Errorf("transaction cost function is not supported by version %d", pdr.Version)
+		}
+		if err := pdr.TxCostFunction.IsValid(); err != nil {
+			return fmt.Errorf("invalid transaction cost function: %w", err)
+		}
+	}
 
 	var validatorIDs = make(map[string]struct{})
 	for i, v := range pdr.Validators {
@@ -106,6 +211,29 @@ func (pdr *PartitionDescriptionRecord) IsValid() error {
 	return nil
 }
 
+/*
+IsValidWith checks the shard configuration (see IsValid) and it's partition specific
+parameters using "validate".
+
+It must be used wherever the shard configuration is accepted into the network, ie
+when creating and verifying the genesis (RootGenesis.Verify and
+GenesisPartitionRecord.Verify call it) and when accepting a configuration of a new
+epoch of the shard, so that misconfigured parameters are detected before the nodes
+of the shard are started. When "validate" is nil only the checks of IsValid are done.
+*/
+func (pdr *PartitionDescriptionRecord) IsValidWith(validate PartitionParamsValidator) error {
+	if err := pdr.IsValid(); err != nil {
+		return err
+	}
+	if validate == nil {
+		return nil
+	}
+	if err := validate(pdr); err != nil {
+		return fmt.Errorf("invalid partition parameters: %w", err)
+	}
+	return nil
+}
+
 // Verify verifies validator info and that it extends the previous shard conf if provided.
 func (pdr *PartitionDescriptionRecord) Verify(prev *PartitionDescriptionRecord) error {
 	if prev != nil {
@@ -134,6 +262,17 @@ func (pdr *PartitionDescriptionRecord) Hash(hashAlgorithm crypto.Hash) ([]byte,
 	return hasher.Sum()
 }
 
+/*
+EstimateTxFee returns the estimated fee of the transaction "txo" according to the
+cost function of the partition, see [TxCostFunction.EstimateFee].
+*/
+func (pdr *PartitionDescriptionRecord) EstimateTxFee(txo *TransactionOrder, predicateRuns uint64) (uint64, error) {
+	if pdr.TxCostFunction == nil {
+		return 0, fmt.Errorf("%w for partition %s", ErrTxCostFunctionIsNil, pdr.PartitionID)
+	}
+	return pdr.TxCostFunction.EstimateFee(txo, predicateRuns)
+}
+
 func (pdr *PartitionDescriptionRecord) GetNetworkID() NetworkID {
 	return pdr.NetworkID
 }
@@ -169,6 +308,9 @@ func (pdr *PartitionDescriptionRecord) ComposeUnitID(shard ShardID, unitType uin
 	if unitType > mask {
 		return nil, fmt.Errorf("provided unit type ID %#x uses more than max allowed %d bits", unitType, pdr.TypeIDLen)
 	}
+	if pdr.PartitionType != nil && pdr.PartitionType.UnitType(unitType) == nil {
+		return nil, fmt.Errorf("unit type %d is not defined by the partition type %q", unitType, pdr.PartitionType.Name)
+	}
 
 	buf := make([]byte, (pdr.UnitIDLen+pdr.TypeIDLen)/8)
 
@@ -208,7 +350,11 @@ func (pdr *PartitionDescriptionRecord) ExtractUnitType(id UnitID) (uint32, error
 	idx := len(id) - 1
 	v := uint32(id[idx]) | (uint32(id[idx-1]) << 8) | (uint32(id[idx-2]) << 16) | (uint32(id[idx-3]) << 24)
 	mask := uint32(0xFFFFFFFF) >> (32 - pdr.TypeIDLen)
-	return v & mask, nil
+	v &= mask
+	if pdr.PartitionType != nil && pdr.PartitionType.UnitType(v) == nil {
+		return 0, fmt.Errorf("unit type %d is not defined by the partition type %q", v, pdr.PartitionType.Name)
+	}
+	return v, nil
 }
 
 func (pdr *PartitionDescriptionRecord) GetVersion() ABVersion {
@@ -223,13 +369,33 @@ func (pdr *PartitionDescriptionRecord) MarshalCBOR() ([]byte, error) {
 	if pdr.Version == 0 {
 		pdr.Version = pdr.GetVersion()
 	}
+	if pdr.Version == 1 {
+		// version 1 encoding has no place for the fields added in later versions,
+		// refuse to silently drop them
+		if pdr.TxCostFunction != nil {
+			return nil, fmt.Errorf("transaction cost function is not supported by version %d", pdr.Version)
+		}
+		return cbor.MarshalTaggedValue(PartitionDescriptionRecordTag, (*pdrV1)(pdr))
+	}
 	return cbor.MarshalTaggedValue(PartitionDescriptionRecordTag, (*alias)(pdr))
 }
 
 func (pdr *PartitionDescriptionRecord) UnmarshalCBOR(data []byte) error {
 	type alias PartitionDescriptionRecord
-	if err := cbor.UnmarshalTaggedValue(PartitionDescriptionRecordTag, data, (*alias)(pdr)); err != nil {
+	version, _, err := parseTaggedCBOR(data, PartitionDescriptionRecordTag)
+	if err != nil {
 		return fmt.Errorf("failed to unmarshal partition description record: %w", err)
 	}
-	return EnsureVersion(pdr, pdr.Version, 1)
+	switch version {
+	case 1:
+		err = cbor.UnmarshalTaggedValue(PartitionDescriptionRecordTag, data, (*pdrV1)(pdr))
+	case 2:
+		err = cbor.UnmarshalTaggedValue(PartitionDescriptionRecordTag, data, (*alias)(pdr))
+	default:
+		return fmt.Errorf("invalid version (type %T), expected 1 or 2, got %d", pdr, version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal partition description record: %w", err)
+	}
+	return nil
 }
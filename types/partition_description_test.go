@@ -117,6 +117,18 @@ func Test_PartitionDescriptionRecord_IsValid(t *testing.T) {
 		require.EqualError(t, pdr.IsValid(), "t2 timeout value out of allowed range: 2m0s")
 	})
 
+	t.Run("tx cost function", func(t *testing.T) {
+		pdr := validPDR()
+		pdr.TxCostFunction = &TxCostFunction{}
+		require.EqualError(t, pdr.IsValid(), "transaction cost function is not supported by version 1")
+
+		pdr.Version = 2
+		require.EqualError(t, pdr.IsValid(), "invalid transaction cost function: gas units per fee unit must be greater than zero")
+
+		pdr.TxCostFunction.GasUnitsPerFeeUnit = 1
+		require.NoError(t, pdr.IsValid())
+	})
+
 	t.Run("invalid validator", func(t *testing.T) {
 		pdr := validPDR()
 		pdr.Validators = []*NodeInfo{{
@@ -480,13 +492,99 @@ func Test_PartitionDescriptionRecord_CBOR(t *testing.T) {
 		require.EqualValues(t, pdr, decoded)
 	})
 
+	t.Run("version 1 has no tx cost function", func(t *testing.T) {
+		v1 := *pdr
+		v1.TxCostFunction = &TxCostFunction{GasUnitsPerFeeUnit: 1}
+		// the field can't be encoded in version 1 record and must not be dropped silently
+		encoded, err := v1.MarshalCBOR()
+		require.EqualError(t, err, "transaction cost function is not supported by version 1")
+		require.Nil(t, encoded)
+		_, err = v1.Hash(crypto.SHA256)
+		require.ErrorContains(t, err, "transaction cost function is not supported by version 1")
+
+		v1.TxCostFunction = nil
+		encoded, err = v1.MarshalCBOR()
+		require.NoError(t, err)
+		_, arr, err := parseTaggedCBOR(encoded, PartitionDescriptionRecordTag)
+		require.NoError(t, err)
+		require.Len(t, arr, 15)
+
+		decoded := &PartitionDescriptionRecord{}
+		require.NoError(t, decoded.UnmarshalCBOR(encoded))
+		require.EqualValues(t, &v1, decoded)
+	})
+
+	t.Run("version 2", func(t *testing.T) {
+		v2 := *pdr
+		v2.Version = 2
+		v2.TxCostFunction = &TxCostFunction{GasUnitsPerFeeUnit: 10, BaseCost: 100, PerByteCost: 2, PredicateCost: 50}
+		encoded, err := v2.MarshalCBOR()
+		require.NoError(t, err)
+
+		_, arr, err := parseTaggedCBOR(encoded, PartitionDescriptionRecordTag)
+		require.NoError(t, err)
+		require.Len(t, arr, 16)
+
+		decoded := &PartitionDescriptionRecord{}
+		require.NoError(t, decoded.UnmarshalCBOR(encoded))
+		require.EqualValues(t, &v2, decoded)
+
+		// version 2 record must have the tx cost function field
+		v1 := *pdr
+		encoded, err = v1.MarshalCBOR()
+		require.NoError(t, err)
+		encoded[4] = 0x02 // version number follows the tag and array header
+		require.ErrorContains(t, decoded.UnmarshalCBOR(encoded), "failed to unmarshal partition description record")
+	})
+
 	t.Run("Unmarshal - invalid version", func(t *testing.T) {
-		pdr.Version = 2
-		encoded, err := pdr.MarshalCBOR()
+		v3 := *pdr
+		v3.Version = 3
+		encoded, err := v3.MarshalCBOR()
 		require.NoError(t, err)
 
 		decoded := &PartitionDescriptionRecord{}
 		err = decoded.UnmarshalCBOR(encoded)
-		require.ErrorContains(t, err, "invalid version (type *types.PartitionDescriptionRecord), expected 1, got 2")
+		require.EqualError(t, err, "invalid version (type *types.PartitionDescriptionRecord), expected 1 or 2, got 3")
 	})
 }
+
+func Test_PartitionDescriptionRecord_Clone(t *testing.T) {
+	pdr := &PartitionDescriptionRecord{
+		Version:          2,
+		NetworkID:        5,
+		PartitionID:      1,
+		ShardID:          ShardID{},
+		PartitionType:    &PartitionType{Name: "custom", UnitTypes: []*UnitTypeDescription{{ID: 1, Name: "unit"}}, TxTypes: []*TxTypeDescription{{ID: 1, Name: "tx", UnitTypes: []uint32{1}}}, Modules: []*WasmModule{{Name: "mod", Hash: []byte{1}}}},
+		TypeIDLen:        8,
+		UnitIDLen:        64,
+		SummaryTrustBase: []byte{2},
+		T2Timeout:        time.Second,
+		FeeCreditBill:    &FeeCreditBill{UnitID: []byte{3}, OwnerPredicate: []byte{4}},
+		PartitionParams:  map[string]string{"key": "value"},
+		Validators:       []*NodeInfo{{NodeID: "node1", SigKey: []byte{5}, Stake: 1}},
+		TxCostFunction:   &TxCostFunction{GasUnitsPerFeeUnit: 1, TxTypeCost: map[uint16]uint64{1: 2}},
+	}
+	require.Nil(t, (*PartitionDescriptionRecord)(nil).Clone())
+
+	pdr.ShardID, _ = pdr.ShardID.Split()
+	c := pdr.Clone()
+	require.Equal(t, pdr, c)
+
+	c.PartitionType.TxTypes[0].UnitTypes[0] = 2
+	c.PartitionType.Modules[0].Hash[0] = 2
+	c.SummaryTrustBase[0] = 0
+	c.FeeCreditBill.OwnerPredicate[0] = 0
+	c.PartitionParams["key"] = "modified"
+	c.Validators[0].SigKey[0] = 0
+	c.TxCostFunction.TxTypeCost[1] = 0
+	require.NotEqual(t, pdr, c)
+	require.Equal(t, pdr, pdr.Clone())
+	require.EqualValues(t, []uint32{1}, pdr.PartitionType.TxTypes[0].UnitTypes)
+	require.EqualValues(t, []byte{1}, pdr.PartitionType.Modules[0].Hash)
+	require.EqualValues(t, []byte{2}, pdr.SummaryTrustBase)
+	require.EqualValues(t, []byte{4}, pdr.FeeCreditBill.OwnerPredicate)
+	require.Equal(t, "value", pdr.PartitionParams["key"])
+	require.EqualValues(t, []byte{5}, pdr.Validators[0].SigKey)
+	require.EqualValues(t, 2, pdr.TxCostFunction.TxTypeCost[1])
+}
@@ -0,0 +1,132 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+/*
+PartitionParamsValidator validates the partition specific parameters of the shard
+configuration (PartitionDescriptionRecord.PartitionParams), see
+PartitionDescriptionRecord.IsValidWith.
+*/
+type PartitionParamsValidator func(pdr *PartitionDescriptionRecord) error
+
+/*
+PartitionParamsValidators maps partition type to the validator of it's partition
+parameters, it's Validate method can be used as validator of a network with several
+partition types:
+
+	validate := types.PartitionParamsValidators{
+		money.PartitionTypeID:  types.ParamsValidator(money.ParsePartitionParams),
+		tokens.PartitionTypeID: types.ParamsValidator(tokens.ParsePartitionParams),
+	}.Validate
+*/
+type PartitionParamsValidators map[PartitionTypeID]PartitionParamsValidator
+
+/*
+Validate validates the partition parameters of the "pdr" using the validator of
+the partition type. Shard configuration of the partition type which has no validator
+must not have any partition parameters.
+*/
+func (v PartitionParamsValidators) Validate(pdr *PartitionDescriptionRecord) error {
+	if pdr == nil {
+		return ErrSystemDescriptionIsNil
+	}
+	validate, ok := v[pdr.PartitionTypeID]
+	if !ok {
+		if len(pdr.PartitionParams) != 0 {
+			return fmt.Errorf("no partition parameters validator for partition type %d", pdr.PartitionTypeID)
+		}
+		return nil
+	}
+	return validate(pdr)
+}
+
+/*
+ParamsValidator converts the partition parameters parser (ie ParsePartitionParams
+function of the partition package) into PartitionParamsValidator.
+*/
+func ParamsValidator[T any](parse func(*PartitionDescriptionRecord) (T, error)) PartitionParamsValidator {
+	return func(pdr *PartitionDescriptionRecord) error {
+		_, err := parse(pdr)
+		return err
+	}
+}
+
+/*
+ParamsDecoder decodes the partition parameters map into typed values. Missing
+parameters are not decoded, ie the value keeps it's zero value. The first error
+is returned by Finish which also reports parameters which were not decoded.
+
+	d := NewParamsDecoder(pdr.PartitionParams)
+	d.Uint64("value", &p.Value)
+	d.Bytes("owner", &p.Owner)
+	if err := d.Finish(); err != nil {...}
+*/
+type ParamsDecoder struct {
+	params map[string]string
+	known  []string
+	err    error
+}
+
+func NewParamsDecoder(params map[string]string) *ParamsDecoder {
+	return &ParamsDecoder{params: params}
+}
+
+func (d *ParamsDecoder) Uint64(key string, v *uint64) {
+	d.decode(key, func(s string) (err error) {
+		*v, err = strconv.ParseUint(s, 10, 64)
+		return err
+	})
+}
+
+func (d *ParamsDecoder) Bool(key string, v *bool) {
+	d.decode(key, func(s string) (err error) {
+		*v, err = strconv.ParseBool(s)
+		return err
+	})
+}
+
+// Bytes decodes hex encoded (with 0x prefix) value.
+func (d *ParamsDecoder) Bytes(key string, v *hex.Bytes) {
+	d.decode(key, func(s string) (err error) {
+		*v, err = hex.Decode([]byte(s))
+		return err
+	})
+}
+
+/*
+Finish returns the first decoding error or error when the parameters contain keys
+which were not decoded (ie unknown parameters).
+*/
+func (d *ParamsDecoder) Finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	var unknown []string
+	for k := range d.params {
+		if !slices.Contains(d.known, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) != 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("unknown partition parameter %q (known parameters are %q)", unknown[0], d.known)
+	}
+	return nil
+}
+
+func (d *ParamsDecoder) decode(key string, parse func(string) error) {
+	d.known = append(d.known, key)
+	s, ok := d.params[key]
+	if !ok || d.err != nil {
+		return
+	}
+	if err := parse(s); err != nil {
+		d.err = fmt.Errorf("invalid value of the partition parameter %q: %w", key, err)
+	}
+}
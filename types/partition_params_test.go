@@ -0,0 +1,105 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+func Test_ParamsDecoder(t *testing.T) {
+	decode := func(params map[string]string) (v uint64, b bool, h hex.Bytes, err error) {
+		d := NewParamsDecoder(params)
+		d.Uint64("value", &v)
+		d.Bool("flag", &b)
+		d.Bytes("bytes", &h)
+		return v, b, h, d.Finish()
+	}
+
+	t.Run("success", func(t *testing.T) {
+		v, b, h, err := decode(nil)
+		require.NoError(t, err)
+		require.Zero(t, v)
+		require.False(t, b)
+		require.Nil(t, h)
+
+		v, b, h, err = decode(map[string]string{"value": "42", "flag": "true", "bytes": "0x0102"})
+		require.NoError(t, err)
+		require.EqualValues(t, 42, v)
+		require.True(t, b)
+		require.Equal(t, hex.Bytes{1, 2}, h)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, _, _, err := decode(map[string]string{"value": "-1"})
+		require.EqualError(t, err, `invalid value of the partition parameter "value": strconv.ParseUint: parsing "-1": invalid syntax`)
+
+		_, _, _, err = decode(map[string]string{"flag": "yes"})
+		require.EqualError(t, err, `invalid value of the partition parameter "flag": strconv.ParseBool: parsing "yes": invalid syntax`)
+
+		_, _, _, err = decode(map[string]string{"bytes": "0102"})
+		require.EqualError(t, err, `invalid value of the partition parameter "bytes": hex string without 0x prefix`)
+
+		// first error is returned
+		_, _, _, err = decode(map[string]string{"value": "x", "bytes": "0102", "foo": "bar"})
+		require.ErrorContains(t, err, `invalid value of the partition parameter "value"`)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, _, _, err := decode(map[string]string{"value": "1", "foo": "bar", "bar": ""})
+		require.EqualError(t, err, `unknown partition parameter "bar" (known parameters are ["value" "flag" "bytes"])`)
+	})
+}
+
+func Test_PartitionParamsValidators(t *testing.T) {
+	pdr := &PartitionDescriptionRecord{
+		Version:         1,
+		NetworkID:       5,
+		PartitionID:     1,
+		PartitionTypeID: 1,
+		TypeIDLen:       8,
+		UnitIDLen:       256,
+		T2Timeout:       2500 * time.Millisecond,
+		PartitionParams: map[string]string{"value": "1"},
+	}
+	parse := func(pdr *PartitionDescriptionRecord) (v uint64, err error) {
+		d := NewParamsDecoder(pdr.PartitionParams)
+		d.Uint64("value", &v)
+		if err := d.Finish(); err != nil {
+			return 0, err
+		}
+		if v == 0 {
+			return 0, errors.New("value must be greater than zero")
+		}
+		return v, nil
+	}
+	validate := PartitionParamsValidators{1: ParamsValidator(parse)}.Validate
+
+	require.NoError(t, pdr.IsValidWith(validate))
+
+	// params are not validated by IsValid
+	pdr.PartitionParams["value"] = "0"
+	require.NoError(t, pdr.IsValid())
+	require.EqualError(t, pdr.IsValidWith(validate), "invalid partition parameters: value must be greater than zero")
+	pdr.PartitionParams["other"] = "1"
+	require.ErrorContains(t, pdr.IsValidWith(validate), `invalid partition parameters: unknown partition parameter "other"`)
+	// without validator only the checks of IsValid are done
+	require.NoError(t, pdr.IsValidWith(nil))
+	pdr.T2Timeout = 0
+	require.EqualError(t, pdr.IsValidWith(nil), pdr.IsValid().Error())
+	pdr.T2Timeout = 2500 * time.Millisecond
+
+	// partition type without validator may not have params
+	pdr.PartitionTypeID = 2
+	require.EqualError(t, pdr.IsValidWith(validate), "invalid partition parameters: no partition parameters validator for partition type 2")
+	pdr.PartitionParams = nil
+	require.NoError(t, pdr.IsValidWith(validate))
+
+	// invalid PDR is not passed to the validator
+	pdr.T2Timeout = 0
+	require.EqualError(t, pdr.IsValidWith(validate), "t2 timeout value out of allowed range: 0s")
+	require.ErrorIs(t, validate(nil), ErrSystemDescriptionIsNil)
+}
@@ -0,0 +1,221 @@
+package types
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+// SummaryValueKind describes how the units of the type contribute into the
+// summary value of the partition state.
+type SummaryValueKind uint8
+
+const (
+	SummaryValueNone    SummaryValueKind = 0 // the units do not contribute into the summary value
+	SummaryValueBalance SummaryValueKind = 1 // the balance of the units is added to the summary value
+)
+
+const (
+	maxPartitionTypeNameLength = 64
+	maxAttributesSchemaLength  = 16 * 1024
+)
+
+type (
+	// PartitionType describes custom (ie not built in, PartitionTypeID == 0) partition type:
+	// the catalog of unit and transaction types and the WASM modules implementing the
+	// transactions.
+	PartitionType struct {
+		_         struct{}               `cbor:",toarray"`
+		Name      string                 `json:"name"`
+		UnitTypes []*UnitTypeDescription `json:"unitTypes"`
+		TxTypes   []*TxTypeDescription   `json:"txTypes"`
+		Modules   []*WasmModule          `json:"modules,omitempty"`
+	}
+
+	UnitTypeDescription struct {
+		_            struct{}         `cbor:",toarray"`
+		ID           uint32           `json:"id"`           // the type ID part of the unit IDs
+		Name         string           `json:"name"`         // human readable name of the type, unique within the partition type
+		SummaryValue SummaryValueKind `json:"summaryValue"` // how the units of the type contribute into the summary value
+	}
+
+	TxTypeDescription struct {
+		_                struct{} `cbor:",toarray"`
+		ID               uint16   `json:"id"`                         // transaction type, ie TransactionOrder.Type
+		Name             string   `json:"name"`                       // human readable name of the type, unique within the partition type
+		UnitTypes        []uint32 `json:"unitTypes"`                  // types of the units the transaction may target
+		AttributesSchema string   `json:"attributesSchema,omitempty"` // CDDL (RFC 8610) description of the attributes
+		Module           string   `json:"module,omitempty"`           // name of the WASM module implementing the transaction
+	}
+
+	// WasmModule is a reference to the WASM module, the module binary itself is
+	// distributed out of band and identified by it's hash.
+	WasmModule struct {
+		_    struct{}  `cbor:",toarray"`
+		Name string    `json:"name"`
+		Hash hex.Bytes `json:"hash"`          // SHA-256 hash of the module binary
+		URI  string    `json:"uri,omitempty"` // optional location of the module binary
+	}
+)
+
+/*
+IsValid checks the partition type, "typeIDLen" is the length of the type ID part of
+the unit IDs in bits (see PartitionDescriptionRecord.TypeIDLen).
+*/
+func (pt *PartitionType) IsValid(typeIDLen uint32) error {
+	if pt == nil {
+		return errors.New("partition type is nil")
+	}
+	if pt.Name == "" || len(pt.Name) > maxPartitionTypeNameLength {
+		return fmt.Errorf("partition type name must be 1..%d bytes, got %d bytes", maxPartitionTypeNameLength, len(pt.Name))
+	}
+
+	modules := make(map[string]struct{}, len(pt.Modules))
+	for i, m := range pt.Modules {
+		if err := m.IsValid(); err != nil {
+			return fmt.Errorf("invalid module at idx %d: %w", i, err)
+		}
+		if _, ok := modules[m.Name]; ok {
+			return fmt.Errorf("duplicate module name %q", m.Name)
+		}
+		modules[m.Name] = struct{}{}
+	}
+
+	if len(pt.UnitTypes) == 0 {
+		return errors.New("unit types catalog is empty")
+	}
+	maxTypeID := uint64(1)<<typeIDLen - 1
+	unitIDs := make(map[uint32]struct{}, len(pt.UnitTypes))
+	unitNames := make(map[string]struct{}, len(pt.UnitTypes))
+	for i, ut := range pt.UnitTypes {
+		if ut == nil {
+			return fmt.Errorf("unit type at idx %d is nil", i)
+		}
+		if ut.ID == 0 || uint64(ut.ID) > maxTypeID {
+			return fmt.Errorf("unit type %q ID must be in range 1..%d, got %d", ut.Name, maxTypeID, ut.ID)
+		}
+		if ut.Name == "" {
+			return fmt.Errorf("unit type %d name is empty", ut.ID)
+		}
+		if ut.SummaryValue > SummaryValueBalance {
+			return fmt.Errorf("unit type %d has invalid summary value kind %d", ut.ID, ut.SummaryValue)
+		}
+		if _, ok := unitIDs[ut.ID]; ok {
+			return fmt.Errorf("duplicate unit type ID %d", ut.ID)
+		}
+		if _, ok := unitNames[ut.Name]; ok {
+			return fmt.Errorf("duplicate unit type name %q", ut.Name)
+		}
+		unitIDs[ut.ID] = struct{}{}
+		unitNames[ut.Name] = struct{}{}
+	}
+
+	if len(pt.TxTypes) == 0 {
+		return errors.New("transaction types catalog is empty")
+	}
+	txIDs := make(map[uint16]struct{}, len(pt.TxTypes))
+	txNames := make(map[string]struct{}, len(pt.TxTypes))
+	for i, tt := range pt.TxTypes {
+		if tt == nil {
+			return fmt.Errorf("transaction type at idx %d is nil", i)
+		}
+		if tt.ID == 0 {
+			return fmt.Errorf("transaction type %q ID is unassigned", tt.Name)
+		}
+		if tt.Name == "" {
+			return fmt.Errorf("transaction type %d name is empty", tt.ID)
+		}
+		if _, ok := txIDs[tt.ID]; ok {
+			return fmt.Errorf("duplicate transaction type ID %d", tt.ID)
+		}
+		if _, ok := txNames[tt.Name]; ok {
+			return fmt.Errorf("duplicate transaction type name %q", tt.Name)
+		}
+		txIDs[tt.ID] = struct{}{}
+		txNames[tt.Name] = struct{}{}
+
+		if len(tt.UnitTypes) == 0 {
+			return fmt.Errorf("transaction type %d doesn't define target unit types", tt.ID)
+		}
+		for _, id := range tt.UnitTypes {
+			if _, ok := unitIDs[id]; !ok {
+				return fmt.Errorf("transaction type %d refers to unknown unit type %d", tt.ID, id)
+			}
+		}
+		if len(tt.AttributesSchema) > maxAttributesSchemaLength {
+			return fmt.Errorf("transaction type %d attributes schema exceeds the allowed maximum of %d bytes", tt.ID, maxAttributesSchemaLength)
+		}
+		if tt.Module != "" {
+			if _, ok := modules[tt.Module]; !ok {
+				return fmt.Errorf("transaction type %d refers to unknown module %q", tt.ID, tt.Module)
+			}
+		}
+	}
+	return nil
+}
+
+// UnitType returns description of the unit type "id", nil when the type is not
+// in the catalog.
+func (pt *PartitionType) UnitType(id uint32) *UnitTypeDescription {
+	for _, ut := range pt.UnitTypes {
+		if ut.ID == id {
+			return ut
+		}
+	}
+	return nil
+}
+
+// UnitTypeByName returns description of the unit type "name", nil when the type
+// is not in the catalog.
+func (pt *PartitionType) UnitTypeByName(name string) *UnitTypeDescription {
+	for _, ut := range pt.UnitTypes {
+		if ut.Name == name {
+			return ut
+		}
+	}
+	return nil
+}
+
+// TxType returns description of the transaction type "id", nil when the type is
+// not in the catalog.
+func (pt *PartitionType) TxType(id uint16) *TxTypeDescription {
+	for _, tt := range pt.TxTypes {
+		if tt.ID == id {
+			return tt
+		}
+	}
+	return nil
+}
+
+// Module returns the WASM module "name", nil when there is no such module.
+func (pt *PartitionType) Module(name string) *WasmModule {
+	for _, m := range pt.Modules {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func (m *WasmModule) IsValid() error {
+	if m == nil {
+		return errors.New("module is nil")
+	}
+	if m.Name == "" {
+		return errors.New("module name is empty")
+	}
+	if len(m.Hash) != sha256.Size {
+		return fmt.Errorf("module hash must be %d bytes, got %d bytes", sha256.Size, len(m.Hash))
+	}
+	return nil
+}
+
+// VerifyBinary checks that "binary" is the module referenced by "m".
+func (m *WasmModule) VerifyBinary(binary []byte) error {
+	if h := sha256.Sum256(binary); string(h[:]) != string(m.Hash) {
+		return fmt.Errorf("module %q hash mismatch: expected %X, got %X", m.Name, []byte(m.Hash), h[:])
+	}
+	return nil
+}
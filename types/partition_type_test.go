@@ -0,0 +1,161 @@
+package types
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testPartitionType() *PartitionType {
+	module := sha256.Sum256([]byte("module"))
+	return &PartitionType{
+		Name: "voting",
+		UnitTypes: []*UnitTypeDescription{
+			{ID: 1, Name: "ballot"},
+			{ID: 2, Name: "deposit", SummaryValue: SummaryValueBalance},
+		},
+		TxTypes: []*TxTypeDescription{
+			{ID: 1, Name: "vote", UnitTypes: []uint32{1}, AttributesSchema: "vote = [choice: uint]", Module: "voting"},
+			{ID: 2, Name: "withdraw", UnitTypes: []uint32{1, 2}},
+		},
+		Modules: []*WasmModule{{Name: "voting", Hash: module[:]}},
+	}
+}
+
+func Test_PartitionType_IsValid(t *testing.T) {
+	require.NoError(t, testPartitionType().IsValid(8))
+	require.EqualError(t, (*PartitionType)(nil).IsValid(8), "partition type is nil")
+
+	var testCases = []struct {
+		name   string
+		modify func(pt *PartitionType)
+		errMsg string
+	}{
+		{"name", func(pt *PartitionType) { pt.Name = "" }, "partition type name must be 1..64 bytes, got 0 bytes"},
+		{"module nil", func(pt *PartitionType) { pt.Modules[0] = nil }, "invalid module at idx 0: module is nil"},
+		{"module name", func(pt *PartitionType) { pt.Modules[0].Name = "" }, "invalid module at idx 0: module name is empty"},
+		{"module hash", func(pt *PartitionType) { pt.Modules[0].Hash = []byte{1} }, "invalid module at idx 0: module hash must be 32 bytes, got 1 bytes"},
+		{"duplicate module", func(pt *PartitionType) { pt.Modules = append(pt.Modules, pt.Modules[0]) }, `duplicate module name "voting"`},
+		{"no unit types", func(pt *PartitionType) { pt.UnitTypes = nil }, "unit types catalog is empty"},
+		{"unit type nil", func(pt *PartitionType) { pt.UnitTypes[1] = nil }, "unit type at idx 1 is nil"},
+		{"unit type zero", func(pt *PartitionType) { pt.UnitTypes[0].ID = 0 }, `unit type "ballot" ID must be in range 1..255, got 0`},
+		{"unit type too big", func(pt *PartitionType) { pt.UnitTypes[0].ID = 256 }, `unit type "ballot" ID must be in range 1..255, got 256`},
+		{"unit type name", func(pt *PartitionType) { pt.UnitTypes[0].Name = "" }, "unit type 1 name is empty"},
+		{"summary value", func(pt *PartitionType) { pt.UnitTypes[0].SummaryValue = 2 }, "unit type 1 has invalid summary value kind 2"},
+		{"duplicate unit type ID", func(pt *PartitionType) { pt.UnitTypes[1].ID = 1 }, "duplicate unit type ID 1"},
+		{"duplicate unit type name", func(pt *PartitionType) { pt.UnitTypes[1].Name = "ballot" }, `duplicate unit type name "ballot"`},
+		{"no tx types", func(pt *PartitionType) { pt.TxTypes = nil }, "transaction types catalog is empty"},
+		{"tx type nil", func(pt *PartitionType) { pt.TxTypes[0] = nil }, "transaction type at idx 0 is nil"},
+		{"tx type zero", func(pt *PartitionType) { pt.TxTypes[0].ID = 0 }, `transaction type "vote" ID is unassigned`},
+		{"tx type name", func(pt *PartitionType) { pt.TxTypes[0].Name = "" }, "transaction type 1 name is empty"},
+		{"duplicate tx type ID", func(pt *PartitionType) { pt.TxTypes[1].ID = 1 }, "duplicate transaction type ID 1"},
+		{"duplicate tx type name", func(pt *PartitionType) { pt.TxTypes[1].Name = "vote" }, `duplicate transaction type name "vote"`},
+		{"tx target types", func(pt *PartitionType) { pt.TxTypes[0].UnitTypes = nil }, "transaction type 1 doesn't define target unit types"},
+		{"tx unknown unit type", func(pt *PartitionType) { pt.TxTypes[1].UnitTypes = []uint32{3} }, "transaction type 2 refers to unknown unit type 3"},
+		{"tx unknown module", func(pt *PartitionType) { pt.TxTypes[1].Module = "foo" }, `transaction type 2 refers to unknown module "foo"`},
+		{"tx schema", func(pt *PartitionType) { pt.TxTypes[1].AttributesSchema = string(make([]byte, 16*1024+1)) }, "transaction type 2 attributes schema exceeds the allowed maximum of 16384 bytes"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pt := testPartitionType()
+			tc.modify(pt)
+			require.EqualError(t, pt.IsValid(8), tc.errMsg)
+		})
+	}
+}
+
+func Test_PartitionType_lookups(t *testing.T) {
+	pt := testPartitionType()
+
+	require.Equal(t, pt.UnitTypes[1], pt.UnitType(2))
+	require.Nil(t, pt.UnitType(3))
+	require.Equal(t, pt.UnitTypes[0], pt.UnitTypeByName("ballot"))
+	require.Nil(t, pt.UnitTypeByName("foo"))
+	require.Equal(t, pt.TxTypes[1], pt.TxType(2))
+	require.Nil(t, pt.TxType(3))
+	require.Equal(t, pt.Modules[0], pt.Module("voting"))
+	require.Nil(t, pt.Module("foo"))
+
+	m := pt.Module("voting")
+	require.NoError(t, m.VerifyBinary([]byte("module")))
+	require.ErrorContains(t, m.VerifyBinary([]byte("other")), `module "voting" hash mismatch`)
+}
+
+func Test_PartitionType_PDR(t *testing.T) {
+	pdr := &PartitionDescriptionRecord{
+		Version:       1,
+		NetworkID:     5,
+		PartitionID:   10,
+		PartitionType: testPartitionType(),
+		TypeIDLen:     8,
+		UnitIDLen:     64,
+		T2Timeout:     2500 * time.Millisecond,
+	}
+	require.NoError(t, pdr.IsValid())
+
+	t.Run("validation", func(t *testing.T) {
+		pdr := *pdr
+		pdr.PartitionTypeID = 1
+		require.EqualError(t, pdr.IsValid(), "custom partition type must not be defined for partition type identifier 1")
+
+		pdr.PartitionTypeID = 0
+		pdr.PartitionType = nil
+		require.EqualError(t, pdr.IsValid(), "partition type must be defined when partition type identifier is 0")
+
+		pdr.PartitionType = testPartitionType()
+		pdr.PartitionType.UnitTypes[0].ID = 0x100
+		require.EqualError(t, pdr.IsValid(), `invalid partition type: unit type "ballot" ID must be in range 1..255, got 256`)
+	})
+
+	t.Run("unit types", func(t *testing.T) {
+		id, err := pdr.ComposeUnitID(ShardID{}, 2, func(b []byte) error { return nil })
+		require.NoError(t, err)
+		typ, err := pdr.ExtractUnitType(id)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, typ)
+		require.NoError(t, id.TypeMustBe(2, pdr))
+		require.EqualError(t, id.TypeMustBe(1, pdr), "expected type 0X1, got 0X2")
+
+		_, err = pdr.ComposeUnitID(ShardID{}, 3, func(b []byte) error { return nil })
+		require.EqualError(t, err, `unit type 3 is not defined by the partition type "voting"`)
+
+		id[len(id)-1] = 3
+		_, err = pdr.ExtractUnitType(id)
+		require.EqualError(t, err, `unit type 3 is not defined by the partition type "voting"`)
+		require.EqualError(t, id.TypeMustBe(3, pdr), `extracting unit type from unit ID: unit type 3 is not defined by the partition type "voting"`)
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		h1, err := pdr.Hash(crypto.SHA256)
+		require.NoError(t, err)
+
+		pdr2 := *pdr
+		pdr2.PartitionType = testPartitionType()
+		h2, err := pdr2.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, h1, h2)
+
+		pdr2.PartitionType.TxTypes[0].AttributesSchema = "vote = [choice: text]"
+		h2, err = pdr2.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		require.NotEqual(t, h1, h2)
+	})
+
+	t.Run("encoding", func(t *testing.T) {
+		buf, err := pdr.MarshalCBOR()
+		require.NoError(t, err)
+		var decoded PartitionDescriptionRecord
+		require.NoError(t, decoded.UnmarshalCBOR(buf))
+		require.Equal(t, pdr, &decoded)
+
+		buf, err = json.Marshal(pdr)
+		require.NoError(t, err)
+		decoded = PartitionDescriptionRecord{}
+		require.NoError(t, json.Unmarshal(buf, &decoded))
+		require.Equal(t, pdr, &decoded)
+	})
+}
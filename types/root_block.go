@@ -0,0 +1,333 @@
+package types
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	abhash "github.com/alphabill-org/alphabill-go-base/hash"
+	"github.com/alphabill-org/alphabill-go-base/types/hex"
+)
+
+type (
+	// RootBlockData is the block of the root chain.
+	RootBlockData struct {
+		_            struct{}                `cbor:",toarray"`
+		Version      ABVersion               `json:"version"`
+		Author       string                  `json:"author"`       // ID of the root validator who proposed the block
+		Round        uint64                  `json:"round"`        // root round number
+		Epoch        uint64                  `json:"epoch"`        // root chain epoch number
+		Timestamp    uint64                  `json:"timestamp"`    // block creation time (in seconds since Unix epoch)
+		ParentQCHash hex.Bytes               `json:"parentQcHash"` // hash of the quorum certificate of the parent block
+		Payload      []*CertifiedInputRecord `json:"payload"`      // shard input records certified in the round
+	}
+
+	// CertifiedInputRecord is the input record of the shard certified by the root chain,
+	// ie the input of the shard tree (see ShardTreeInput).
+	CertifiedInputRecord struct {
+		_             struct{}     `cbor:",toarray"`
+		Partition     PartitionID  `json:"partitionId"`
+		Shard         ShardID      `json:"shardId"`
+		IR            *InputRecord `json:"inputRecord"`
+		TRHash        hex.Bytes    `json:"trHash"`        // hash of TechnicalRecord
+		ShardConfHash hex.Bytes    `json:"shardConfHash"` // hash of the PartitionDescriptionRecord of the shard
+	}
+
+	// RootRoundInfo is the summary of the root round, voted on by the root validators.
+	RootRoundInfo struct {
+		_                 struct{}  `cbor:",toarray"`
+		Version           ABVersion `json:"version"`
+		RoundNumber       uint64    `json:"rootRoundNumber"`
+		Epoch             uint64    `json:"rootEpoch"`
+		Timestamp         uint64    `json:"timestamp"`         // round creation time (in seconds since Unix epoch)
+		ParentRoundNumber uint64    `json:"parentRoundNumber"` // round number of the parent block
+		CurrentRootHash   hex.Bytes `json:"currentRootHash"`   // root hash of the Unicity Tree after executing the block
+		CurrentBlockHash  hex.Bytes `json:"currentBlockHash"`  // hash of the RootBlockData of the round
+	}
+)
+
+/*
+IsValid checks the block: mandatory fields are set and the payload doesn't contain
+invalid or duplicate (same partition and shard) input records.
+*/
+func (x *RootBlockData) IsValid() error {
+	if x == nil {
+		return errors.New("root block is nil")
+	}
+	if x.Version != 1 {
+		return ErrInvalidVersion(x)
+	}
+	if x.Author == "" {
+		return errors.New("block author is missing")
+	}
+	if x.Round < 1 {
+		return ErrInvalidRootRound
+	}
+	if x.Timestamp < GenesisTime {
+		return ErrInvalidTimestamp
+	}
+	if len(x.ParentQCHash) == 0 {
+		return errors.New("parent QC hash is missing")
+	}
+	shards := make(map[PartitionID]map[string]struct{})
+	for i, in := range x.Payload {
+		if err := in.IsValid(); err != nil {
+			return fmt.Errorf("invalid input record at idx %d: %w", i, err)
+		}
+		if shards[in.Partition] == nil {
+			shards[in.Partition] = make(map[string]struct{})
+		}
+		if _, ok := shards[in.Partition][in.Shard.Key()]; ok {
+			return fmt.Errorf("duplicate input record for partition %s shard %q", in.Partition, in.Shard)
+		}
+		shards[in.Partition][in.Shard.Key()] = struct{}{}
+	}
+	return nil
+}
+
+// Hash returns the hash of the block (hash of the CBOR encoding of the block).
+func (x *RootBlockData) Hash(algo crypto.Hash) ([]byte, error) {
+	hasher := abhash.New(algo.New())
+	hasher.Write(x)
+	return hasher.Sum()
+}
+
+/*
+InputRecord returns the input record of the shard certified by the block, nil when
+the block doesn't contain input for the shard.
+*/
+func (x *RootBlockData) InputRecord(partition PartitionID, shard ShardID) *CertifiedInputRecord {
+	for _, in := range x.Payload {
+		if in.Partition == partition && in.Shard.Equal(shard) {
+			return in
+		}
+	}
+	return nil
+}
+
+/*
+VerifyCertificate checks that the UC "uc" certifies the input record of the shard
+included in the block.
+*/
+func (x *RootBlockData) VerifyCertificate(uc *UnicityCertificate) error {
+	if uc == nil {
+		return ErrUnicityCertificateIsNil
+	}
+	in := x.InputRecord(uc.GetPartitionID(), uc.GetShardID())
+	if in == nil {
+		return fmt.Errorf("block of round %d has no input record for partition %s shard %q", x.Round, uc.GetPartitionID(), uc.GetShardID())
+	}
+	return in.VerifyCertificate(uc)
+}
+
+func (x *RootBlockData) GetVersion() ABVersion {
+	if x != nil && x.Version > 0 {
+		return x.Version
+	}
+	return 1
+}
+
+func (x *RootBlockData) MarshalCBOR() ([]byte, error) {
+	type alias RootBlockData
+	if x.Version == 0 {
+		x.Version = x.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(RootPartitionBlockDataTag, (*alias)(x))
+}
+
+func (x *RootBlockData) UnmarshalCBOR(data []byte) error {
+	type alias RootBlockData
+	if err := cbor.UnmarshalTaggedValue(RootPartitionBlockDataTag, data, (*alias)(x)); err != nil {
+		return fmt.Errorf("failed to unmarshal root block: %w", err)
+	}
+	return EnsureVersion(x, x.Version, 1)
+}
+
+func (x *CertifiedInputRecord) IsValid() error {
+	if x == nil {
+		return errors.New("certified input record is nil")
+	}
+	if err := x.IR.IsValid(); err != nil {
+		return fmt.Errorf("invalid input record: %w", err)
+	}
+	if len(x.TRHash) == 0 {
+		return errors.New("technical record hash is missing")
+	}
+	if len(x.ShardConfHash) == 0 {
+		return errors.New("shard configuration hash is missing")
+	}
+	return nil
+}
+
+// VerifyCertificate checks that the UC "uc" certifies the input record.
+func (x *CertifiedInputRecord) VerifyCertificate(uc *UnicityCertificate) error {
+	if uc == nil {
+		return ErrUnicityCertificateIsNil
+	}
+	if id := uc.GetPartitionID(); id != x.Partition {
+		return fmt.Errorf("certificate is for partition %s, expected %s", id, x.Partition)
+	}
+	if id := uc.GetShardID(); !id.Equal(x.Shard) {
+		return fmt.Errorf("certificate is for shard %q, expected %q", id, x.Shard)
+	}
+	eq, err := EqualIR(x.IR, uc.InputRecord)
+	if err != nil {
+		return fmt.Errorf("comparing input records: %w", err)
+	}
+	if !eq {
+		return errors.New("certificate input record differs from the input record of the block")
+	}
+	if !bytes.Equal(x.TRHash, uc.TRHash) {
+		return fmt.Errorf("technical record hash mismatch: UC has %X, block has %X", []byte(uc.TRHash), []byte(x.TRHash))
+	}
+	if !bytes.Equal(x.ShardConfHash, uc.ShardConfHash) {
+		return fmt.Errorf("shard configuration hash mismatch: UC has %X, block has %X", []byte(uc.ShardConfHash), []byte(x.ShardConfHash))
+	}
+	return nil
+}
+
+func (x *RootRoundInfo) IsValid() error {
+	if x == nil {
+		return errors.New("round info is nil")
+	}
+	if x.Version != 1 {
+		return ErrInvalidVersion(x)
+	}
+	if x.RoundNumber < 1 {
+		return ErrInvalidRootRound
+	}
+	if x.ParentRoundNumber >= x.RoundNumber {
+		return fmt.Errorf("parent round %d must be less than round %d", x.ParentRoundNumber, x.RoundNumber)
+	}
+	if x.Timestamp < GenesisTime {
+		return ErrInvalidTimestamp
+	}
+	if len(x.CurrentRootHash) == 0 {
+		return errors.New("current root hash is missing")
+	}
+	if len(x.CurrentBlockHash) == 0 {
+		return errors.New("current block hash is missing")
+	}
+	return nil
+}
+
+// Hash returns the hash of the round info (hash of the CBOR encoding of the round info).
+func (x *RootRoundInfo) Hash(algo crypto.Hash) ([]byte, error) {
+	hasher := abhash.New(algo.New())
+	hasher.Write(x)
+	return hasher.Sum()
+}
+
+/*
+Verify checks that the round info extends the round info of the parent round
+"parent", ie the parent round number, epoch and timestamp are consistent.
+*/
+func (x *RootRoundInfo) Verify(parent *RootRoundInfo) error {
+	if parent == nil {
+		return errors.New("parent round info is nil")
+	}
+	if x.ParentRoundNumber != parent.RoundNumber {
+		return fmt.Errorf("parent round number is %d, got round info of the round %d", x.ParentRoundNumber, parent.RoundNumber)
+	}
+	if x.Epoch != parent.Epoch && x.Epoch != parent.Epoch+1 {
+		return fmt.Errorf("epoch %d doesn't follow the parent epoch %d", x.Epoch, parent.Epoch)
+	}
+	if x.Timestamp < parent.Timestamp {
+		return fmt.Errorf("timestamp %d is before the parent timestamp %d", x.Timestamp, parent.Timestamp)
+	}
+	return nil
+}
+
+/*
+VerifyBlock checks that the round info describes the block "block": the round
+number, epoch and timestamp are the same as in the block and the hash of the
+block is the current block hash of the round info.
+*/
+func (x *RootRoundInfo) VerifyBlock(block *RootBlockData, algo crypto.Hash) error {
+	if block == nil {
+		return errors.New("root block is nil")
+	}
+	if block.Round != x.RoundNumber {
+		return fmt.Errorf("block is of round %d, expected %d", block.Round, x.RoundNumber)
+	}
+	if block.Epoch != x.Epoch {
+		return fmt.Errorf("block is of epoch %d, expected %d", block.Epoch, x.Epoch)
+	}
+	if block.Timestamp != x.Timestamp {
+		return fmt.Errorf("block timestamp %d, expected %d", block.Timestamp, x.Timestamp)
+	}
+	h, err := block.Hash(algo)
+	if err != nil {
+		return fmt.Errorf("calculating block hash: %w", err)
+	}
+	if !bytes.Equal(h, x.CurrentBlockHash) {
+		return fmt.Errorf("block hash %X, expected %X", h, []byte(x.CurrentBlockHash))
+	}
+	return nil
+}
+
+/*
+VerifySeal checks that the unicity seal "seal" is issued for the round of the block
+"block": the round number, epoch, timestamp and root hash of the seal are the same
+as in the round info and the round info describes the block (see VerifyBlock).
+When the round info of the parent round "parent" is not nil it's root hash must be
+the previous hash of the seal, ie the seals form a chain.
+Finally the signatures of the seal are verified against the trust base "tb".
+*/
+func (x *RootRoundInfo) VerifySeal(seal *UnicitySeal, block *RootBlockData, parent *RootRoundInfo, tb RootTrustBase, algo crypto.Hash) error {
+	if seal == nil {
+		return ErrUnicitySealIsNil
+	}
+	if seal.RootChainRoundNumber != x.RoundNumber {
+		return fmt.Errorf("seal is for round %d, expected %d", seal.RootChainRoundNumber, x.RoundNumber)
+	}
+	if seal.Epoch != x.Epoch {
+		return fmt.Errorf("seal is for epoch %d, expected %d", seal.Epoch, x.Epoch)
+	}
+	if seal.Timestamp != x.Timestamp {
+		return fmt.Errorf("seal timestamp %d, expected %d", seal.Timestamp, x.Timestamp)
+	}
+	if !bytes.Equal(seal.Hash, x.CurrentRootHash) {
+		return fmt.Errorf("seal root hash %X, expected %X", []byte(seal.Hash), []byte(x.CurrentRootHash))
+	}
+	if err := x.VerifyBlock(block, algo); err != nil {
+		return fmt.Errorf("round info doesn't match the block: %w", err)
+	}
+	if parent != nil {
+		if err := x.Verify(parent); err != nil {
+			return fmt.Errorf("round info doesn't extend the parent: %w", err)
+		}
+		if !bytes.Equal(seal.PreviousHash, parent.CurrentRootHash) {
+			return fmt.Errorf("seal previous hash %X, expected root hash of the parent round %X", []byte(seal.PreviousHash), []byte(parent.CurrentRootHash))
+		}
+	}
+	if err := seal.Verify(tb); err != nil {
+		return fmt.Errorf("verifying unicity seal: %w", err)
+	}
+	return nil
+}
+
+func (x *RootRoundInfo) GetVersion() ABVersion {
+	if x != nil && x.Version > 0 {
+		return x.Version
+	}
+	return 1
+}
+
+func (x *RootRoundInfo) MarshalCBOR() ([]byte, error) {
+	type alias RootRoundInfo
+	if x.Version == 0 {
+		x.Version = x.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(RootPartitionRoundInfoTag, (*alias)(x))
+}
+
+func (x *RootRoundInfo) UnmarshalCBOR(data []byte) error {
+	type alias RootRoundInfo
+	if err := cbor.UnmarshalTaggedValue(RootPartitionRoundInfoTag, data, (*alias)(x)); err != nil {
+		return fmt.Errorf("failed to unmarshal round info: %w", err)
+	}
+	return EnsureVersion(x, x.Version, 1)
+}
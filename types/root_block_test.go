@@ -0,0 +1,363 @@
+package types
+
+import (
+	"crypto"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+	testsig "github.com/alphabill-org/alphabill-go-base/testutils/sig"
+)
+
+func Test_RootBlockData(t *testing.T) {
+	signer, _ := testsig.CreateSignerAndVerifier(t)
+	pdr := &PartitionDescriptionRecord{
+		Version:     1,
+		NetworkID:   networkID,
+		PartitionID: partitionID,
+		TypeIDLen:   8,
+		UnitIDLen:   256,
+		T2Timeout:   2500 * time.Millisecond,
+	}
+	ir := &InputRecord{
+		Version:      1,
+		PreviousHash: []byte{0, 0, 1},
+		Hash:         []byte{0, 0, 2},
+		BlockHash:    []byte{0, 0, 3},
+		SummaryValue: []byte{0, 0, 4},
+		RoundNumber:  1,
+		Timestamp:    NewTimestamp(),
+	}
+	trHash := make([]byte, 32)
+	uc := createUnicityCertificate(t, "test", signer, ir, trHash, pdr)
+
+	newBlock := func() *RootBlockData {
+		return &RootBlockData{
+			Version:      1,
+			Author:       "test",
+			Round:        2,
+			Epoch:        0,
+			Timestamp:    NewTimestamp(),
+			ParentQCHash: []byte{1, 2, 3},
+			Payload: []*CertifiedInputRecord{{
+				Partition:     partitionID,
+				IR:            ir,
+				TRHash:        trHash,
+				ShardConfHash: doHash(t, pdr),
+			}},
+		}
+	}
+
+	t.Run("IsValid", func(t *testing.T) {
+		require.NoError(t, newBlock().IsValid())
+
+		var b *RootBlockData
+		require.EqualError(t, b.IsValid(), "root block is nil")
+
+		b = newBlock()
+		b.Version = 2
+		require.EqualError(t, b.IsValid(), "invalid version (type *types.RootBlockData)")
+
+		b = newBlock()
+		b.Author = ""
+		require.EqualError(t, b.IsValid(), "block author is missing")
+
+		b = newBlock()
+		b.Round = 0
+		require.ErrorIs(t, b.IsValid(), ErrInvalidRootRound)
+
+		b = newBlock()
+		b.Timestamp = GenesisTime - 1
+		require.ErrorIs(t, b.IsValid(), ErrInvalidTimestamp)
+
+		b = newBlock()
+		b.ParentQCHash = nil
+		require.EqualError(t, b.IsValid(), "parent QC hash is missing")
+
+		b = newBlock()
+		b.Payload = append(b.Payload, nil)
+		require.EqualError(t, b.IsValid(), "invalid input record at idx 1: certified input record is nil")
+
+		b = newBlock()
+		b.Payload = append(b.Payload, b.Payload[0])
+		require.EqualError(t, b.IsValid(), `duplicate input record for partition 01000001 shard ""`)
+
+		b = newBlock()
+		b.Payload[0] = &CertifiedInputRecord{Partition: partitionID, TRHash: trHash, ShardConfHash: trHash}
+		require.EqualError(t, b.IsValid(), "invalid input record at idx 0: invalid input record: input record is nil")
+
+		b.Payload[0] = &CertifiedInputRecord{Partition: partitionID, IR: ir, ShardConfHash: trHash}
+		require.EqualError(t, b.IsValid(), "invalid input record at idx 0: technical record hash is missing")
+
+		b.Payload[0] = &CertifiedInputRecord{Partition: partitionID, IR: ir, TRHash: trHash}
+		require.EqualError(t, b.IsValid(), "invalid input record at idx 0: shard configuration hash is missing")
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		b := newBlock()
+		h1, err := b.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, doHash(t, b), h1)
+
+		b.Round++
+		h2, err := b.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		require.NotEqual(t, h1, h2)
+	})
+
+	t.Run("VerifyCertificate", func(t *testing.T) {
+		b := newBlock()
+		require.Same(t, b.Payload[0], b.InputRecord(partitionID, ShardID{}))
+		require.Nil(t, b.InputRecord(partitionID+1, ShardID{}))
+		require.NoError(t, b.VerifyCertificate(uc))
+		require.ErrorIs(t, b.VerifyCertificate(nil), ErrUnicityCertificateIsNil)
+
+		b.Payload[0].Partition = partitionID + 1
+		require.EqualError(t, b.VerifyCertificate(uc), `block of round 2 has no input record for partition 01000001 shard ""`)
+		require.EqualError(t, b.Payload[0].VerifyCertificate(uc), "certificate is for partition 01000001, expected 01000002")
+
+		b = newBlock()
+		id0, _ := ShardID{}.Split()
+		b.Payload[0].Shard = id0
+		require.EqualError(t, b.Payload[0].VerifyCertificate(uc), `certificate is for shard "", expected "0"`)
+
+		b = newBlock()
+		ir2 := *ir
+		ir2.RoundNumber++
+		b.Payload[0].IR = &ir2
+		require.EqualError(t, b.VerifyCertificate(uc), "certificate input record differs from the input record of the block")
+
+		b = newBlock()
+		b.Payload[0].TRHash = []byte{1}
+		require.ErrorContains(t, b.VerifyCertificate(uc), "technical record hash mismatch: UC has 0000")
+
+		b = newBlock()
+		b.Payload[0].ShardConfHash = []byte{1}
+		require.ErrorContains(t, b.VerifyCertificate(uc), "shard configuration hash mismatch: UC has ")
+	})
+
+	t.Run("CBOR encoding", func(t *testing.T) {
+		b := newBlock()
+		data, err := cbor.Marshal(b)
+		require.NoError(t, err)
+		var b2 RootBlockData
+		require.NoError(t, cbor.Unmarshal(data, &b2))
+		require.NoError(t, b2.IsValid())
+		require.NoError(t, b2.VerifyCertificate(uc))
+		h1, err := b.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		h2, err := b2.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, h1, h2)
+
+		b2.Version = 2
+		data, err = cbor.Marshal(&b2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &b2), "invalid version (type *types.RootBlockData), expected 1, got 2")
+	})
+
+	t.Run("JSON encoding", func(t *testing.T) {
+		b := newBlock()
+		data, err := json.Marshal(b)
+		require.NoError(t, err)
+		var b2 RootBlockData
+		require.NoError(t, json.Unmarshal(data, &b2))
+		require.NoError(t, b2.VerifyCertificate(uc))
+		h1, err := b.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		h2, err := b2.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, h1, h2)
+	})
+}
+
+func Test_RootRoundInfo(t *testing.T) {
+	newRoundInfo := func(round uint64, rootHash []byte) *RootRoundInfo {
+		return &RootRoundInfo{
+			Version:           1,
+			RoundNumber:       round,
+			Epoch:             1,
+			Timestamp:         GenesisTime + round,
+			ParentRoundNumber: round - 1,
+			CurrentRootHash:   rootHash,
+			CurrentBlockHash:  []byte{byte(round), 0xb},
+		}
+	}
+	parent := newRoundInfo(5, []byte{5, 5, 5})
+
+	t.Run("IsValid", func(t *testing.T) {
+		require.NoError(t, newRoundInfo(6, []byte{6}).IsValid())
+
+		var ri *RootRoundInfo
+		require.EqualError(t, ri.IsValid(), "round info is nil")
+
+		ri = newRoundInfo(6, []byte{6})
+		ri.Version = 0
+		require.EqualError(t, ri.IsValid(), "invalid version (type *types.RootRoundInfo)")
+
+		ri = newRoundInfo(6, []byte{6})
+		ri.RoundNumber = 0
+		require.ErrorIs(t, ri.IsValid(), ErrInvalidRootRound)
+
+		ri = newRoundInfo(6, []byte{6})
+		ri.ParentRoundNumber = 6
+		require.EqualError(t, ri.IsValid(), "parent round 6 must be less than round 6")
+
+		ri = newRoundInfo(6, []byte{6})
+		ri.Timestamp = 1
+		require.ErrorIs(t, ri.IsValid(), ErrInvalidTimestamp)
+
+		ri = newRoundInfo(6, nil)
+		require.EqualError(t, ri.IsValid(), "current root hash is missing")
+
+		ri = newRoundInfo(6, []byte{6})
+		ri.CurrentBlockHash = nil
+		require.EqualError(t, ri.IsValid(), "current block hash is missing")
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		ri := newRoundInfo(6, []byte{6})
+		h, err := ri.Hash(crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, doHash(t, ri), h)
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		ri := newRoundInfo(6, []byte{6})
+		require.NoError(t, ri.Verify(parent))
+		require.EqualError(t, ri.Verify(nil), "parent round info is nil")
+
+		// rounds without block are allowed
+		ri = newRoundInfo(8, []byte{8})
+		ri.ParentRoundNumber = 5
+		require.NoError(t, ri.Verify(parent))
+
+		ri.ParentRoundNumber = 4
+		require.EqualError(t, ri.Verify(parent), "parent round number is 4, got round info of the round 5")
+
+		ri = newRoundInfo(6, []byte{6})
+		ri.Epoch = 2
+		require.NoError(t, ri.Verify(parent))
+		ri.Epoch = 3
+		require.EqualError(t, ri.Verify(parent), "epoch 3 doesn't follow the parent epoch 1")
+		ri.Epoch = 0
+		require.EqualError(t, ri.Verify(parent), "epoch 0 doesn't follow the parent epoch 1")
+
+		ri = newRoundInfo(6, []byte{6})
+		ri.Timestamp = parent.Timestamp - 1
+		require.EqualError(t, ri.Verify(parent), "timestamp 1681971088 is before the parent timestamp 1681971089")
+	})
+
+	newBlock := func(ri *RootRoundInfo) *RootBlockData {
+		return &RootBlockData{
+			Version:      1,
+			Author:       "test",
+			Round:        ri.RoundNumber,
+			Epoch:        ri.Epoch,
+			Timestamp:    ri.Timestamp,
+			ParentQCHash: []byte{1, 2, 3},
+		}
+	}
+
+	t.Run("VerifyBlock", func(t *testing.T) {
+		ri := newRoundInfo(6, []byte{6})
+		block := newBlock(ri)
+		ri.CurrentBlockHash = doHash(t, block)
+		require.NoError(t, ri.VerifyBlock(block, crypto.SHA256))
+		require.EqualError(t, ri.VerifyBlock(nil, crypto.SHA256), "root block is nil")
+
+		block.Round = 7
+		require.EqualError(t, ri.VerifyBlock(block, crypto.SHA256), "block is of round 7, expected 6")
+
+		block = newBlock(ri)
+		block.Epoch = 2
+		require.EqualError(t, ri.VerifyBlock(block, crypto.SHA256), "block is of epoch 2, expected 1")
+
+		block = newBlock(ri)
+		block.Timestamp++
+		require.EqualError(t, ri.VerifyBlock(block, crypto.SHA256), "block timestamp 1681971091, expected 1681971090")
+
+		// block of the same round but with different content
+		block = newBlock(ri)
+		block.Author = "other"
+		require.ErrorContains(t, ri.VerifyBlock(block, crypto.SHA256), "block hash ")
+	})
+
+	t.Run("VerifySeal", func(t *testing.T) {
+		signer, verifier := testsig.CreateSignerAndVerifier(t)
+		tb := NewTrustBase(t, verifier)
+		ri := newRoundInfo(6, []byte{6})
+		block := newBlock(ri)
+		ri.CurrentBlockHash = doHash(t, block)
+		newSeal := func() *UnicitySeal {
+			seal := &UnicitySeal{
+				Version:              1,
+				RootChainRoundNumber: ri.RoundNumber,
+				Epoch:                ri.Epoch,
+				Timestamp:            ri.Timestamp,
+				PreviousHash:         parent.CurrentRootHash,
+				Hash:                 ri.CurrentRootHash,
+			}
+			require.NoError(t, seal.Sign("test", signer))
+			return seal
+		}
+		require.NoError(t, ri.VerifySeal(newSeal(), block, parent, tb, crypto.SHA256))
+		require.NoError(t, ri.VerifySeal(newSeal(), block, nil, tb, crypto.SHA256))
+		require.ErrorIs(t, ri.VerifySeal(nil, block, parent, tb, crypto.SHA256), ErrUnicitySealIsNil)
+
+		seal := newSeal()
+		seal.RootChainRoundNumber = 7
+		require.EqualError(t, ri.VerifySeal(seal, block, parent, tb, crypto.SHA256), "seal is for round 7, expected 6")
+
+		seal = newSeal()
+		seal.Epoch = 2
+		require.EqualError(t, ri.VerifySeal(seal, block, parent, tb, crypto.SHA256), "seal is for epoch 2, expected 1")
+
+		seal = newSeal()
+		seal.Timestamp++
+		require.EqualError(t, ri.VerifySeal(seal, block, parent, tb, crypto.SHA256), "seal timestamp 1681971091, expected 1681971090")
+
+		seal = newSeal()
+		seal.Hash = []byte{7}
+		require.EqualError(t, ri.VerifySeal(seal, block, parent, tb, crypto.SHA256), "seal root hash 07, expected 06")
+
+		seal = newSeal()
+		seal.PreviousHash = []byte{4}
+		require.EqualError(t, ri.VerifySeal(seal, block, parent, tb, crypto.SHA256), "seal previous hash 04, expected root hash of the parent round 050505")
+
+		require.EqualError(t, ri.VerifySeal(newSeal(), block, newRoundInfo(4, []byte{4}), tb, crypto.SHA256), "round info doesn't extend the parent: parent round number is 5, got round info of the round 4")
+
+		// seal matches the round info but the round info is not of the block
+		require.EqualError(t, ri.VerifySeal(newSeal(), nil, parent, tb, crypto.SHA256), "round info doesn't match the block: root block is nil")
+		other := newBlock(ri)
+		other.ParentQCHash = []byte{4, 5, 6}
+		require.ErrorContains(t, ri.VerifySeal(newSeal(), other, parent, tb, crypto.SHA256), "round info doesn't match the block: block hash ")
+
+		// seal matches the round info but signatures do not verify
+		require.ErrorIs(t, ri.VerifySeal(newSeal(), block, parent, nil, crypto.SHA256), ErrRootValidatorInfoMissing)
+
+		seal = newSeal()
+		seal.Signatures = nil
+		require.ErrorIs(t, ri.VerifySeal(seal, block, parent, tb, crypto.SHA256), ErrUnicitySealSignatureIsNil)
+
+		_, verifier2 := testsig.CreateSignerAndVerifier(t)
+		require.ErrorContains(t, ri.VerifySeal(newSeal(), block, parent, NewTrustBase(t, verifier2), crypto.SHA256), "verifying unicity seal: verifying signatures: ")
+	})
+
+	t.Run("CBOR encoding", func(t *testing.T) {
+		ri := newRoundInfo(6, []byte{6})
+		data, err := cbor.Marshal(ri)
+		require.NoError(t, err)
+		var ri2 RootRoundInfo
+		require.NoError(t, cbor.Unmarshal(data, &ri2))
+		require.Equal(t, ri, &ri2)
+
+		ri2.Version = 2
+		data, err = cbor.Marshal(&ri2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &ri2), "invalid version (type *types.RootRoundInfo), expected 1, got 2")
+	})
+}
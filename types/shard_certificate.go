@@ -94,11 +94,7 @@ func CreateShardTree(scheme ShardingScheme, states []ShardTreeInput, algo crypto
 	tree := ShardTree{}
 	h := abhash.New(algo.New())
 	for _, v := range states {
-		h.Reset()
-		h.Write(v.IR)
-		h.Write(v.TRHash)
-		h.Write(v.ShardConfHash)
-		if tree[v.Shard.Key()], err = h.Sum(); err != nil {
+		if tree[v.Shard.Key()], err = v.hash(h); err != nil {
 			return nil, fmt.Errorf("calculating hash for shard %s: %w", v.Shard, err)
 		}
 	}
@@ -125,6 +121,61 @@ func CreateShardTree(scheme ShardingScheme, states []ShardTreeInput, algo crypto
 
 type ShardTree map[string][]byte // ShardID.Key -> hash(IR, TRh, ShardConfHash)
 
+func (in *ShardTreeInput) hash(h *abhash.Hash) ([]byte, error) {
+	h.Reset()
+	h.Write(in.IR)
+	h.Write(in.TRHash)
+	h.Write(in.ShardConfHash)
+	return h.Sum()
+}
+
+/*
+Update replaces the leaf of the shard "input.Shard" and recalculates the hashes on
+the path from the leaf to the root, the rest of the tree is not touched. So the tree
+created by CreateShardTree can be kept between rounds and updated only for the shards
+which have changed, the result is the same as creating the tree from scratch.
+
+The shard must be a leaf of the tree, ie the sharding scheme can't be changed using
+Update. When error is returned the tree is not modified.
+*/
+func (tree ShardTree) Update(input ShardTreeInput, algo crypto.Hash) error {
+	if _, ok := tree[input.Shard.Key()]; !ok {
+		return fmt.Errorf("shard %q is not in the tree", input.Shard)
+	}
+	if _, _, ok := tree.children(input.Shard); ok {
+		return fmt.Errorf("%q is not a leaf of the shard tree", input.Shard)
+	}
+
+	h := abhash.New(algo.New())
+	leafHash, err := input.hash(h)
+	if err != nil {
+		return fmt.Errorf("calculating hash for shard %s: %w", input.Shard, err)
+	}
+	// calculate the new hashes before modifying the tree so that it's not left
+	// in an inconsistent state in case of error
+	path := map[string][]byte{input.Shard.Key(): leafHash}
+	nodeHash := func(id ShardID) []byte {
+		if v, ok := path[id.Key()]; ok {
+			return v
+		}
+		return tree[id.Key()]
+	}
+	for id, ok := input.Shard.Parent(); ok; id, ok = id.Parent() {
+		id0, id1 := id.Split()
+		h.Reset()
+		h.Write(nodeHash(id0))
+		h.Write(nodeHash(id1))
+		if path[id.Key()], err = h.Sum(); err != nil {
+			return fmt.Errorf("calculating hash for shard tree node %q: %w", id, err)
+		}
+	}
+
+	for k, v := range path {
+		tree[k] = v
+	}
+	return nil
+}
+
 /*
 generate generates non-leaf nodes of the tree. This may be called only
 when all the "leaf nodes" have been created as otherwise it would cause
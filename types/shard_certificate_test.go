@@ -218,6 +218,54 @@ func Test_ShardTreeCertificate_Certificate(t *testing.T) {
 	})
 }
 
+func Test_ShardTree_Update(t *testing.T) {
+	id0, id1 := ShardID{}.Split()
+	id00, id01 := id0.Split()
+	scheme := buildShardingScheme([]ShardID{id00, id01, id1})
+	in := generateSTInput(scheme)
+	tree, err := CreateShardTree(scheme, in, crypto.SHA256)
+	require.NoError(t, err)
+
+	t.Run("invalid shard", func(t *testing.T) {
+		err := tree.Update(ShardTreeInput{Shard: id0, IR: &InputRecord{}}, crypto.SHA256)
+		require.EqualError(t, err, `"0" is not a leaf of the shard tree`)
+
+		err = tree.Update(ShardTreeInput{Shard: ShardID{bits: []byte{128}, length: 2}, IR: &InputRecord{}}, crypto.SHA256)
+		require.EqualError(t, err, `shard "10" is not in the tree`)
+	})
+
+	t.Run("same as full rebuild", func(t *testing.T) {
+		for i := range in {
+			in[i].IR = &InputRecord{Hash: test.RandomBytes(8)}
+			in[i].TRHash = test.RandomBytes(8)
+			require.NoError(t, tree.Update(in[i], crypto.SHA256))
+
+			expected, err := CreateShardTree(scheme, in, crypto.SHA256)
+			require.NoError(t, err)
+			require.Equal(t, expected, tree)
+			for id := range scheme.All() {
+				cert, err := tree.Certificate(id)
+				require.NoError(t, err)
+				certExp, err := expected.Certificate(id)
+				require.NoError(t, err)
+				require.Equal(t, certExp, cert)
+			}
+		}
+	})
+
+	t.Run("single shard", func(t *testing.T) {
+		in := []ShardTreeInput{{Shard: ShardID{}, IR: &InputRecord{}}}
+		tree, err := CreateShardTree(ShardingScheme{}, in, crypto.SHA256)
+		require.NoError(t, err)
+
+		in[0].TRHash = []byte{1, 2, 3}
+		require.NoError(t, tree.Update(in[0], crypto.SHA256))
+		expected, err := CreateShardTree(ShardingScheme{}, in, crypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, expected, tree)
+	})
+}
+
 // generate input records with enough randomness that we have different hashes
 func generateSTInput(scheme ShardingScheme) []ShardTreeInput {
 	out := []ShardTreeInput{}
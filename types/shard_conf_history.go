@@ -0,0 +1,164 @@
+package types
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+)
+
+/*
+ShardConfHistory is the sequence of the configurations (PDRs) of a shard ordered by
+epoch, every record is a valid successor of the previous one (see
+PartitionDescriptionRecord.Verify). The history doesn't have to begin from the
+epoch zero.
+
+Zero value is an empty history, use Add to append records.
+*/
+type ShardConfHistory struct {
+	_       struct{}                      `cbor:",toarray"`
+	Version ABVersion                     `json:"version"`
+	Records []*PartitionDescriptionRecord `json:"records"`
+}
+
+/*
+Add appends the shard configuration "pdr" to the history. The first record may be
+of any epoch, subsequent records must extend the last record of the history.
+*/
+func (h *ShardConfHistory) Add(pdr *PartitionDescriptionRecord) error {
+	if pdr == nil {
+		return ErrSystemDescriptionIsNil
+	}
+	if err := pdr.IsValid(); err != nil {
+		return fmt.Errorf("invalid shard configuration: %w", err)
+	}
+	if err := pdr.Verify(h.Latest()); err != nil {
+		return fmt.Errorf("shard configuration of epoch %d doesn't extend the history: %w", pdr.Epoch, err)
+	}
+	h.Records = append(h.Records, pdr)
+	return nil
+}
+
+/*
+Latest returns the last shard configuration in the history, nil when the history
+is empty.
+*/
+func (h *ShardConfHistory) Latest() *PartitionDescriptionRecord {
+	if len(h.Records) == 0 {
+		return nil
+	}
+	return h.Records[len(h.Records)-1]
+}
+
+/*
+ByEpoch returns the shard configuration of the "epoch".
+*/
+func (h *ShardConfHistory) ByEpoch(epoch uint64) (*PartitionDescriptionRecord, error) {
+	if len(h.Records) == 0 {
+		return nil, errors.New("shard configuration history is empty")
+	}
+	// epochs are consecutive so the index can be calculated
+	first := h.Records[0].Epoch
+	if epoch < first || epoch-first >= uint64(len(h.Records)) {
+		return nil, fmt.Errorf("epoch %d is not in the history (epochs %d..%d)", epoch, first, h.Latest().Epoch)
+	}
+	return h.Records[epoch-first], nil
+}
+
+/*
+AtRound returns the shard configuration which is active in the root round
+"rootRound", ie the configuration with the greatest EpochStart which is not
+greater than the round.
+*/
+func (h *ShardConfHistory) AtRound(rootRound uint64) (*PartitionDescriptionRecord, error) {
+	if len(h.Records) == 0 {
+		return nil, errors.New("shard configuration history is empty")
+	}
+	// index of the first record which starts after the round
+	idx := sort.Search(len(h.Records), func(i int) bool { return h.Records[i].EpochStart > rootRound })
+	if idx == 0 {
+		return nil, fmt.Errorf("root round %d is before the start of the history (round %d)", rootRound, h.Records[0].EpochStart)
+	}
+	return h.Records[idx-1], nil
+}
+
+/*
+ShardConfHash returns the hash of the shard configuration of the "epoch", ie the
+value of the UnicityCertificate.ShardConfHash of the rounds of the epoch.
+*/
+func (h *ShardConfHistory) ShardConfHash(epoch uint64, algo crypto.Hash) ([]byte, error) {
+	pdr, err := h.ByEpoch(epoch)
+	if err != nil {
+		return nil, err
+	}
+	return pdr.Hash(algo)
+}
+
+/*
+ShardConfHashAt returns the hash of the shard configuration active in the root
+round "rootRound" (ie UnicitySeal.RootChainRoundNumber of the UC).
+*/
+func (h *ShardConfHistory) ShardConfHashAt(rootRound uint64, algo crypto.Hash) ([]byte, error) {
+	pdr, err := h.AtRound(rootRound)
+	if err != nil {
+		return nil, err
+	}
+	return pdr.Hash(algo)
+}
+
+/*
+IsValid checks that all the records are valid and each record extends the previous
+one. Meant to be used for the history which is not built using Add (ie decoded
+from JSON, UnmarshalCBOR calls it).
+*/
+func (h *ShardConfHistory) IsValid() error {
+	if h == nil {
+		return errors.New("shard configuration history is nil")
+	}
+	if h.GetVersion() != 1 {
+		return ErrInvalidVersion(h)
+	}
+	var prev *PartitionDescriptionRecord
+	for i, pdr := range h.Records {
+		if pdr == nil {
+			return fmt.Errorf("shard configuration at idx %d is nil", i)
+		}
+		if err := pdr.IsValid(); err != nil {
+			return fmt.Errorf("invalid shard configuration at idx %d: %w", i, err)
+		}
+		if err := pdr.Verify(prev); err != nil {
+			return fmt.Errorf("shard configuration at idx %d doesn't extend the previous record: %w", i, err)
+		}
+		prev = pdr
+	}
+	return nil
+}
+
+func (h *ShardConfHistory) GetVersion() ABVersion {
+	if h != nil && h.Version > 0 {
+		return h.Version
+	}
+	return 1
+}
+
+func (h *ShardConfHistory) MarshalCBOR() ([]byte, error) {
+	type alias ShardConfHistory
+	if h.Version == 0 {
+		h.Version = h.GetVersion()
+	}
+	return cbor.MarshalTaggedValue(ShardConfHistoryTag, (*alias)(h))
+}
+
+func (h *ShardConfHistory) UnmarshalCBOR(data []byte) error {
+	type alias ShardConfHistory
+	if err := cbor.UnmarshalTaggedValue(ShardConfHistoryTag, data, (*alias)(h)); err != nil {
+		return fmt.Errorf("failed to unmarshal shard configuration history: %w", err)
+	}
+	if err := EnsureVersion(h, h.Version, 1); err != nil {
+		return err
+	}
+	// the history is used to verify the UCs, do not accept inconsistent records
+	return h.IsValid()
+}
@@ -0,0 +1,166 @@
+package types
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+)
+
+func Test_ShardConfHistory(t *testing.T) {
+	newPDR := func(epoch, epochStart uint64) *PartitionDescriptionRecord {
+		return &PartitionDescriptionRecord{
+			Version:         1,
+			NetworkID:       5,
+			PartitionID:     1,
+			PartitionTypeID: 1,
+			TypeIDLen:       8,
+			UnitIDLen:       256,
+			T2Timeout:       2500 * time.Millisecond,
+			Epoch:           epoch,
+			EpochStart:      epochStart,
+		}
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		h := ShardConfHistory{}
+		require.Nil(t, h.Latest())
+		pdr, err := h.ByEpoch(0)
+		require.EqualError(t, err, "shard configuration history is empty")
+		require.Nil(t, pdr)
+		pdr, err = h.AtRound(1)
+		require.EqualError(t, err, "shard configuration history is empty")
+		require.Nil(t, pdr)
+		require.NoError(t, h.IsValid())
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		h := ShardConfHistory{}
+		require.ErrorIs(t, h.Add(nil), ErrSystemDescriptionIsNil)
+
+		invalid := newPDR(2, 10)
+		invalid.T2Timeout = 0
+		require.EqualError(t, h.Add(invalid), "invalid shard configuration: t2 timeout value out of allowed range: 0s")
+
+		// history may start from any epoch
+		require.NoError(t, h.Add(newPDR(2, 10)))
+		require.EqualError(t, h.Add(newPDR(4, 20)), "shard configuration of epoch 4 doesn't extend the history: invalid epoch, provided 4 previous 2")
+		require.EqualError(t, h.Add(newPDR(3, 10)), "shard configuration of epoch 3 doesn't extend the history: invalid epoch start, provided 10 previous 10")
+
+		pdr := newPDR(3, 20)
+		pdr.PartitionID = 2
+		require.EqualError(t, h.Add(pdr), "shard configuration of epoch 3 doesn't extend the history: invalid partition id, provided 2 previous 1")
+
+		pdr = newPDR(3, 20)
+		require.NoError(t, h.Add(pdr))
+		require.Same(t, pdr, h.Latest())
+		require.Len(t, h.Records, 2)
+		require.NoError(t, h.IsValid())
+	})
+
+	h := ShardConfHistory{}
+	for i := range uint64(3) {
+		require.NoError(t, h.Add(newPDR(i+1, (i+1)*100)))
+	}
+
+	t.Run("ByEpoch", func(t *testing.T) {
+		for epoch := uint64(1); epoch <= 3; epoch++ {
+			pdr, err := h.ByEpoch(epoch)
+			require.NoError(t, err)
+			require.Equal(t, epoch, pdr.Epoch)
+
+			hash, err := h.ShardConfHash(epoch, crypto.SHA256)
+			require.NoError(t, err)
+			require.Equal(t, doHash(t, pdr), hash)
+		}
+
+		for _, epoch := range []uint64{0, 4} {
+			pdr, err := h.ByEpoch(epoch)
+			require.EqualError(t, err, fmt.Sprintf("epoch %d is not in the history (epochs 1..3)", epoch))
+			require.Nil(t, pdr)
+			hash, err := h.ShardConfHash(epoch, crypto.SHA256)
+			require.Error(t, err)
+			require.Nil(t, hash)
+		}
+	})
+
+	t.Run("AtRound", func(t *testing.T) {
+		var testCases = []struct {
+			round uint64
+			epoch uint64
+		}{
+			{100, 1}, {101, 1}, {199, 1}, {200, 2}, {299, 2}, {300, 3}, {1000, 3},
+		}
+		for _, tc := range testCases {
+			pdr, err := h.AtRound(tc.round)
+			require.NoError(t, err)
+			require.Equal(t, tc.epoch, pdr.Epoch, "round %d", tc.round)
+
+			hash, err := h.ShardConfHashAt(tc.round, crypto.SHA256)
+			require.NoError(t, err)
+			require.Equal(t, doHash(t, pdr), hash)
+		}
+
+		pdr, err := h.AtRound(99)
+		require.EqualError(t, err, "root round 99 is before the start of the history (round 100)")
+		require.Nil(t, pdr)
+		hash, err := h.ShardConfHashAt(99, crypto.SHA256)
+		require.Error(t, err)
+		require.Nil(t, hash)
+	})
+
+	t.Run("IsValid", func(t *testing.T) {
+		var nilHistory *ShardConfHistory
+		require.EqualError(t, nilHistory.IsValid(), "shard configuration history is nil")
+
+		h2 := ShardConfHistory{Version: 2}
+		require.EqualError(t, h2.IsValid(), "invalid version (type *types.ShardConfHistory)")
+
+		h2 = ShardConfHistory{Records: []*PartitionDescriptionRecord{newPDR(1, 100), nil}}
+		require.EqualError(t, h2.IsValid(), "shard configuration at idx 1 is nil")
+
+		h2 = ShardConfHistory{Records: []*PartitionDescriptionRecord{newPDR(1, 100), newPDR(3, 200)}}
+		require.EqualError(t, h2.IsValid(), "shard configuration at idx 1 doesn't extend the previous record: invalid epoch, provided 3 previous 1")
+	})
+
+	t.Run("CBOR encoding", func(t *testing.T) {
+		data, err := cbor.Marshal(&h)
+		require.NoError(t, err)
+		var h2 ShardConfHistory
+		require.NoError(t, cbor.Unmarshal(data, &h2))
+		require.NoError(t, h2.IsValid())
+		require.EqualValues(t, 1, h2.Version)
+		require.Equal(t, h.Records, h2.Records)
+
+		h2.Version = 2
+		data, err = cbor.Marshal(&h2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &h2), "invalid version (type *types.ShardConfHistory), expected 1, got 2")
+
+		// records which do not extend each other are rejected
+		h2 = ShardConfHistory{Records: []*PartitionDescriptionRecord{newPDR(1, 100), newPDR(3, 200)}}
+		data, err = cbor.Marshal(&h2)
+		require.NoError(t, err)
+		require.EqualError(t, cbor.Unmarshal(data, &ShardConfHistory{}), "shard configuration at idx 1 doesn't extend the previous record: invalid epoch, provided 3 previous 1")
+	})
+
+	t.Run("JSON encoding", func(t *testing.T) {
+		data, err := json.Marshal(&h)
+		require.NoError(t, err)
+		var h2 ShardConfHistory
+		require.NoError(t, json.Unmarshal(data, &h2))
+		require.NoError(t, h2.IsValid())
+		for epoch := uint64(1); epoch <= 3; epoch++ {
+			hash, err := h.ShardConfHash(epoch, crypto.SHA256)
+			require.NoError(t, err)
+			hash2, err := h2.ShardConfHash(epoch, crypto.SHA256)
+			require.NoError(t, err)
+			require.Equal(t, hash, hash2)
+		}
+	})
+}
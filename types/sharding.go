@@ -67,6 +67,26 @@ func (id ShardID) Split() (ShardID, ShardID) {
 		ShardID{bits: b1, length: bitCnt}
 }
 
+/*
+Parent returns the ID of the parent shard, ie the ID is shortened by one bit. The
+second return value is false when the ID is empty (root of the shard tree) and
+thus has no parent.
+*/
+func (id ShardID) Parent() (ShardID, bool) {
+	switch id.length {
+	case 0:
+		return ShardID{}, false
+	case 1:
+		return ShardID{}, true
+	}
+	bitCnt := id.length - 1
+	b := slices.Clone(id.bits[:(bitCnt+7)/8])
+	if r := bitCnt % 8; r != 0 {
+		b[len(b)-1] &= byte(0xFF << (8 - r))
+	}
+	return ShardID{bits: b, length: bitCnt}, true
+}
+
 func (id ShardID) Equal(v ShardID) bool {
 	return id.length == v.length && bytes.Equal(id.bits, v.bits)
 }
@@ -318,6 +338,14 @@ func (ss ShardingScheme) Shard(id UnitID) ShardID {
 	return node.id
 }
 
+/*
+Clone returns a deep copy of the scheme, ie splitting or merging the shards of the
+clone doesn't affect the original scheme and vice versa.
+*/
+func (ss ShardingScheme) Clone() ShardingScheme {
+	return buildShardingScheme(slices.Collect(ss.All()))
+}
+
 /*
 All returns all shard IDs in the scheme.
 */
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"slices"
+
+	abhash "github.com/alphabill-org/alphabill-go-base/hash"
+)
+
+/*
+ShardingSchemeChange describes the transition from one sharding scheme to another
+(ie between the shard configuration epochs) as a list of shard splits and merges.
+
+The merges are applied first, in the order they are listed, and the splits after
+that, also in the listed order. So to split a shard into more than two shards the
+parent must be listed before the children and to merge more than two shards the
+children must be merged before the parent. Diff returns the change in this order.
+*/
+type ShardingSchemeChange struct {
+	_      struct{}  `cbor:",toarray"`
+	Merges []ShardID `json:"merges"` // IDs of the shards to be created by merging their two child shards
+	Splits []ShardID `json:"splits"` // IDs of the shards to be split into two
+}
+
+// ShardOrigin tells from which shards of the old scheme the units of the shard
+// in the new scheme come from.
+type ShardOrigin struct {
+	Shard ShardID   // shard in the new scheme
+	From  []ShardID // shards in the old scheme, sorted using CompareShardIDs
+}
+
+/*
+IsEmpty returns true when the change doesn't alter the sharding scheme.
+*/
+func (c *ShardingSchemeChange) IsEmpty() bool {
+	return c == nil || (len(c.Merges) == 0 && len(c.Splits) == 0)
+}
+
+/*
+IsValid checks the change for the errors which do not depend on the scheme the
+change is applied to: every shard ID may be listed only once. Use Verify to check
+that the change can be applied to given scheme.
+*/
+func (c *ShardingSchemeChange) IsValid() error {
+	if c == nil {
+		return errors.New("sharding scheme change is nil")
+	}
+	seen := make(map[string]struct{}, len(c.Merges)+len(c.Splits))
+	for _, id := range slices.Concat(c.Merges, c.Splits) {
+		if _, ok := seen[id.Key()]; ok {
+			return fmt.Errorf("shard %q is listed more than once", id)
+		}
+		seen[id.Key()] = struct{}{}
+	}
+	return nil
+}
+
+/*
+Verify checks that the change is valid and contains only legal operations on the
+scheme "ss", ie only leafs are split and only nodes whose both children are leafs
+are merged.
+*/
+func (c *ShardingSchemeChange) Verify(ss ShardingScheme) error {
+	_, err := c.Apply(ss)
+	return err
+}
+
+/*
+Apply returns new sharding scheme which is the result of applying the change to
+the scheme "ss". The "ss" itself is not modified.
+*/
+func (c *ShardingSchemeChange) Apply(ss ShardingScheme) (ShardingScheme, error) {
+	if err := c.IsValid(); err != nil {
+		return ShardingScheme{}, err
+	}
+	if err := ss.IsValid(); err != nil {
+		return ShardingScheme{}, fmt.Errorf("invalid sharding scheme: %w", err)
+	}
+	scheme := buildShardingScheme(slices.Collect(ss.All()))
+	for _, id := range c.Merges {
+		if err := scheme.Merge(id); err != nil {
+			return ShardingScheme{}, fmt.Errorf("merging shard %q: %w", id, err)
+		}
+	}
+	for _, id := range c.Splits {
+		if _, _, err := scheme.Split(id); err != nil {
+			return ShardingScheme{}, fmt.Errorf("splitting shard %q: %w", id, err)
+		}
+	}
+	return scheme, nil
+}
+
+func (c *ShardingSchemeChange) Hash(hashAlgorithm crypto.Hash) ([]byte, error) {
+	hasher := abhash.New(hashAlgorithm.New())
+	hasher.Write(c)
+	return hasher.Sum()
+}
+
+/*
+Diff returns the change which transforms the scheme "ss" into the scheme "target".
+*/
+func (ss ShardingScheme) Diff(target ShardingScheme) (*ShardingSchemeChange, error) {
+	change, _, err := diffSchemes(ss, target)
+	return change, err
+}
+
+/*
+Origins returns for every shard in the scheme "target" the shards of the scheme "ss"
+the units of the target shard come from, ie when the target shard
+  - exists in the old scheme it's the shard itself;
+  - is created by splitting it's the shard which was split;
+  - is created by merging it's the shards which were merged.
+
+The result is sorted using CompareShardIDs.
+*/
+func (ss ShardingScheme) Origins(target ShardingScheme) ([]ShardOrigin, error) {
+	_, origins, err := diffSchemes(ss, target)
+	return origins, err
+}
+
+func diffSchemes(from, to ShardingScheme) (*ShardingSchemeChange, []ShardOrigin, error) {
+	if err := from.IsValid(); err != nil {
+		return nil, nil, fmt.Errorf("invalid old sharding scheme: %w", err)
+	}
+	if err := to.IsValid(); err != nil {
+		return nil, nil, fmt.Errorf("invalid new sharding scheme: %w", err)
+	}
+
+	change := &ShardingSchemeChange{}
+	var origins []ShardOrigin
+	var walk func(o, n *ShardingScheme)
+	walk = func(o, n *ShardingScheme) {
+		switch {
+		case o.isLeaf() && n.isLeaf():
+			origins = append(origins, ShardOrigin{Shard: n.id, From: []ShardID{o.id}})
+		case o.isLeaf():
+			// splits are listed parent first
+			n.walkInternal(true, func(id ShardID) { change.Splits = append(change.Splits, id) })
+			for id := range n.All() {
+				origins = append(origins, ShardOrigin{Shard: id, From: []ShardID{o.id}})
+			}
+		case n.isLeaf():
+			// merges are listed children first
+			o.walkInternal(false, func(id ShardID) { change.Merges = append(change.Merges, id) })
+			origins = append(origins, ShardOrigin{Shard: n.id, From: slices.SortedFunc(o.All(), CompareShardIDs)})
+		default:
+			walk(o.next0, n.next0)
+			walk(o.next1, n.next1)
+		}
+	}
+	walk(&from, &to)
+
+	slices.SortFunc(origins, func(a, b ShardOrigin) int { return CompareShardIDs(a.Shard, b.Shard) })
+	return change, origins, nil
+}
+
+func (ss *ShardingScheme) isLeaf() bool {
+	return ss.next0 == nil && ss.next1 == nil
+}
+
+/*
+walkInternal calls "f" for every non-leaf node of the (valid) scheme, parent before
+children when "preorder" is true, children before parent otherwise.
+*/
+func (ss *ShardingScheme) walkInternal(preorder bool, f func(ShardID)) {
+	if ss.isLeaf() {
+		return
+	}
+	if preorder {
+		f(ss.id)
+	}
+	ss.next0.walkInternal(preorder, f)
+	ss.next1.walkInternal(preorder, f)
+	if !preorder {
+		f(ss.id)
+	}
+}
@@ -0,0 +1,182 @@
+package types
+
+import (
+	"crypto"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alphabill-org/alphabill-go-base/cbor"
+)
+
+func Test_ShardingScheme_Diff(t *testing.T) {
+	emptyID := ShardID{}
+	id0, id1 := emptyID.Split()
+	id00, id01 := id0.Split()
+	id10, id11 := id1.Split()
+	id100, id101 := id10.Split()
+
+	newScheme := func(t *testing.T, leafs ...ShardID) ShardingScheme {
+		t.Helper()
+		ss, err := NewShardingScheme(leafs)
+		require.NoError(t, err)
+		return ss
+	}
+
+	t.Run("no change", func(t *testing.T) {
+		ss := newScheme(t, id0, id10, id11)
+		change, err := ss.Diff(newScheme(t, id0, id10, id11))
+		require.NoError(t, err)
+		require.True(t, change.IsEmpty())
+
+		origins, err := ss.Origins(ss)
+		require.NoError(t, err)
+		require.Equal(t, []ShardOrigin{
+			{Shard: id0, From: []ShardID{id0}},
+			{Shard: id10, From: []ShardID{id10}},
+			{Shard: id11, From: []ShardID{id11}},
+		}, origins)
+	})
+
+	t.Run("multi level split", func(t *testing.T) {
+		old := ShardingScheme{}
+		target := newScheme(t, id0, id100, id101, id11)
+		change, err := old.Diff(target)
+		require.NoError(t, err)
+		require.Empty(t, change.Merges)
+		require.Equal(t, []ShardID{emptyID, id1, id10}, change.Splits)
+
+		ss, err := change.Apply(old)
+		require.NoError(t, err)
+		require.Equal(t, slices.Collect(target.All()), slices.Collect(ss.All()))
+
+		origins, err := old.Origins(target)
+		require.NoError(t, err)
+		require.Equal(t, []ShardOrigin{
+			{Shard: id0, From: []ShardID{emptyID}},
+			{Shard: id100, From: []ShardID{emptyID}},
+			{Shard: id101, From: []ShardID{emptyID}},
+			{Shard: id11, From: []ShardID{emptyID}},
+		}, origins)
+	})
+
+	t.Run("multi level merge", func(t *testing.T) {
+		old := newScheme(t, id0, id100, id101, id11)
+		change, err := old.Diff(ShardingScheme{})
+		require.NoError(t, err)
+		require.Empty(t, change.Splits)
+		require.Equal(t, []ShardID{id10, id1, emptyID}, change.Merges)
+
+		ss, err := change.Apply(old)
+		require.NoError(t, err)
+		require.Equal(t, []ShardID{emptyID}, slices.Collect(ss.All()))
+
+		origins, err := old.Origins(ShardingScheme{})
+		require.NoError(t, err)
+		require.Equal(t, []ShardOrigin{{Shard: emptyID, From: []ShardID{id0, id100, id101, id11}}}, origins)
+	})
+
+	t.Run("split and merge", func(t *testing.T) {
+		old := newScheme(t, id0, id10, id11)
+		target := newScheme(t, id00, id01, id1)
+		change, err := old.Diff(target)
+		require.NoError(t, err)
+		require.Equal(t, &ShardingSchemeChange{Merges: []ShardID{id1}, Splits: []ShardID{id0}}, change)
+		require.NoError(t, change.Verify(old))
+
+		origins, err := old.Origins(target)
+		require.NoError(t, err)
+		require.Equal(t, []ShardOrigin{
+			{Shard: id00, From: []ShardID{id0}},
+			{Shard: id01, From: []ShardID{id0}},
+			{Shard: id1, From: []ShardID{id10, id11}},
+		}, origins)
+
+		// scheme must be unchanged by Apply
+		ss, err := change.Apply(old)
+		require.NoError(t, err)
+		require.Equal(t, slices.Collect(target.All()), slices.Collect(ss.All()))
+		require.Equal(t, []ShardID{id0, id10, id11}, slices.Collect(old.All()))
+	})
+
+	t.Run("random", func(t *testing.T) {
+		randomScheme := func() ShardingScheme {
+			var ss ShardingScheme
+			for range rand.IntN(20) {
+				ids := slices.Collect(ss.All())
+				_, _, err := ss.Split(ids[rand.IntN(len(ids))])
+				require.NoError(t, err)
+			}
+			return ss
+		}
+		for range 50 {
+			old, target := randomScheme(), randomScheme()
+			change, err := old.Diff(target)
+			require.NoError(t, err)
+			ss, err := change.Apply(old)
+			require.NoError(t, err)
+			require.ElementsMatch(t, slices.Collect(target.All()), slices.Collect(ss.All()))
+
+			origins, err := old.Origins(target)
+			require.NoError(t, err)
+			require.Len(t, origins, len(slices.Collect(target.All())))
+		}
+	})
+
+	t.Run("invalid scheme", func(t *testing.T) {
+		invalid := buildShardingScheme([]ShardID{id0})
+		_, err := invalid.Diff(ShardingScheme{})
+		require.EqualError(t, err, `invalid old sharding scheme: shard ID "0" has no sibling`)
+		_, err = ShardingScheme{}.Origins(invalid)
+		require.EqualError(t, err, `invalid new sharding scheme: shard ID "0" has no sibling`)
+	})
+}
+
+func Test_ShardingSchemeChange_Verify(t *testing.T) {
+	emptyID := ShardID{}
+	id0, id1 := emptyID.Split()
+	id00, _ := id0.Split()
+	ss, err := NewShardingScheme([]ShardID{id0, id1})
+	require.NoError(t, err)
+
+	var testCases = []struct {
+		name   string
+		change *ShardingSchemeChange
+		errMsg string
+	}{
+		{"nil", nil, "sharding scheme change is nil"},
+		{"duplicate split", &ShardingSchemeChange{Splits: []ShardID{id0, id0}}, `shard "0" is listed more than once`},
+		{"split and merge", &ShardingSchemeChange{Merges: []ShardID{emptyID}, Splits: []ShardID{emptyID}}, `shard "" is listed more than once`},
+		{"split not leaf", &ShardingSchemeChange{Splits: []ShardID{emptyID}}, `splitting shard "": shard ID  is not a leaf`},
+		{"split unknown", &ShardingSchemeChange{Splits: []ShardID{id00}}, `splitting shard "00": shard ID 00 is not in the scheme`},
+		{"merge leaf", &ShardingSchemeChange{Merges: []ShardID{id0}}, `merging shard "0": node 0 is a leaf`},
+		{"children before parent", &ShardingSchemeChange{Splits: []ShardID{id00, id0}}, `splitting shard "00": shard ID 00 is not in the scheme`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.EqualError(t, tc.change.Verify(ss), tc.errMsg)
+		})
+	}
+
+	require.NoError(t, (&ShardingSchemeChange{Splits: []ShardID{id0, id00}}).Verify(ss))
+	require.NoError(t, (&ShardingSchemeChange{Merges: []ShardID{emptyID}}).Verify(ss))
+}
+
+func Test_ShardingSchemeChange_encoding(t *testing.T) {
+	id0, id1 := ShardID{}.Split()
+	change := &ShardingSchemeChange{Merges: []ShardID{id1}, Splits: []ShardID{id0}}
+
+	buf, err := cbor.Marshal(change)
+	require.NoError(t, err)
+	var decoded ShardingSchemeChange
+	require.NoError(t, cbor.Unmarshal(buf, &decoded))
+	require.Equal(t, change, &decoded)
+
+	h1, err := change.Hash(crypto.SHA256)
+	require.NoError(t, err)
+	h2, err := (&ShardingSchemeChange{Merges: []ShardID{id0}, Splits: []ShardID{id1}}).Hash(crypto.SHA256)
+	require.NoError(t, err)
+	require.NotEqual(t, h1, h2)
+}
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+ShardLabel returns additional text to be displayed next to the shard (ie unit count
+or validator set of the shard) when the sharding scheme is rendered. Empty string
+means that there is nothing to add.
+*/
+type ShardLabel func(ShardID) string
+
+/*
+PrettyPrint returns human readable (ASCII tree) representation of the sharding
+scheme. Optional "label" is called for every leaf of the scheme (ie for the shards)
+and it's return value is appended to the shard ID.
+*/
+func (ss ShardingScheme) PrettyPrint(label ShardLabel) string {
+	var sb strings.Builder
+	sb.WriteString(shardName(ss.id))
+	writeLabel(&sb, ss.isLeaf(), ss.id, label)
+	sb.WriteByte('\n')
+	ss.output(&sb, "", label)
+	return sb.String()
+}
+
+func (ss *ShardingScheme) output(sb *strings.Builder, prefix string, label ShardLabel) {
+	if ss.isLeaf() {
+		return
+	}
+	for i, n := range []*ShardingScheme{ss.next0, ss.next1} {
+		branch, indent := "├── ", "│   "
+		if i == 1 {
+			branch, indent = "└── ", "    "
+		}
+		sb.WriteString(prefix + branch + shardName(n.id))
+		writeLabel(sb, n.isLeaf(), n.id, label)
+		sb.WriteByte('\n')
+		n.output(sb, prefix+indent, label)
+	}
+}
+
+/*
+DOT returns Graphviz DOT representation of the sharding scheme. Optional "label"
+is called for every leaf of the scheme (ie for the shards) and it's return value
+is added to the label of the node.
+*/
+func (ss ShardingScheme) DOT(label ShardLabel) string {
+	var sb strings.Builder
+	sb.WriteString("digraph ShardingScheme {\n\tnode [shape=box];\n")
+	var walk func(n *ShardingScheme)
+	walk = func(n *ShardingScheme) {
+		text := shardName(n.id)
+		if n.isLeaf() && label != nil {
+			if s := label(n.id); s != "" {
+				text += "\n" + s
+			}
+		}
+		style := ""
+		if !n.isLeaf() {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&sb, "\t%s [label=\"%s\"%s];\n", dotNodeID(n.id), dotEscape(text), style)
+		if n.isLeaf() {
+			return
+		}
+		for _, c := range []*ShardingScheme{n.next0, n.next1} {
+			walk(c)
+			fmt.Fprintf(&sb, "\t%s -> %s;\n", dotNodeID(n.id), dotNodeID(c.id))
+		}
+	}
+	walk(&ss)
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+/*
+PrettyPrint returns human readable (ASCII tree) representation of the shard tree,
+every node is printed with it's hash.
+*/
+func (tree ShardTree) PrettyPrint() string {
+	if len(tree) == 0 {
+		return "tree is empty"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %X\n", shardName(ShardID{}), tree.RootHash())
+	tree.output(&sb, ShardID{}, "")
+	return sb.String()
+}
+
+func (tree ShardTree) output(sb *strings.Builder, id ShardID, prefix string) {
+	id0, id1, ok := tree.children(id)
+	if !ok {
+		return
+	}
+	for i, c := range []ShardID{id0, id1} {
+		branch, indent := "├── ", "│   "
+		if i == 1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintf(sb, "%s%s%s %X\n", prefix, branch, shardName(c), tree[c.Key()])
+		tree.output(sb, c, prefix+indent)
+	}
+}
+
+/*
+DOT returns Graphviz DOT representation of the shard tree, every node is labeled
+with the shard ID and hash.
+*/
+func (tree ShardTree) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph ShardTree {\n\tnode [shape=box];\n")
+	if len(tree) != 0 {
+		var walk func(id ShardID)
+		walk = func(id ShardID) {
+			fmt.Fprintf(&sb, "\t%s [label=\"%s\\n%X\"];\n", dotNodeID(id), dotEscape(shardName(id)), tree[id.Key()])
+			id0, id1, ok := tree.children(id)
+			if !ok {
+				return
+			}
+			for _, c := range []ShardID{id0, id1} {
+				walk(c)
+				fmt.Fprintf(&sb, "\t%s -> %s;\n", dotNodeID(id), dotNodeID(c))
+			}
+		}
+		walk(ShardID{})
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+/*
+children returns the IDs of the child nodes of the node "id", the last return value
+is false when the node is a leaf (ie a shard).
+*/
+func (tree ShardTree) children(id ShardID) (ShardID, ShardID, bool) {
+	id0, id1 := id.Split()
+	_, ok0 := tree[id0.Key()]
+	_, ok1 := tree[id1.Key()]
+	return id0, id1, ok0 && ok1
+}
+
+func writeLabel(sb *strings.Builder, isLeaf bool, id ShardID, label ShardLabel) {
+	if !isLeaf || label == nil {
+		return
+	}
+	if s := label(id); s != "" {
+		sb.WriteString(" " + s)
+	}
+}
+
+// shardName returns the shard ID as bit string, empty ID is shown as "ε".
+func shardName(id ShardID) string {
+	if id.Length() == 0 {
+		return "ε"
+	}
+	return id.String()
+}
+
+// dotNodeID returns DOT node identifier for the shard (bit strings are not valid
+// identifiers as they might start with a digit).
+func dotNodeID(id ShardID) string {
+	return "s" + id.String()
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ShardingScheme_PrettyPrint(t *testing.T) {
+	t.Run("single shard", func(t *testing.T) {
+		ss := ShardingScheme{}
+		require.Equal(t, "ε\n", ss.PrettyPrint(nil))
+		require.Equal(t, "ε units: 10\n", ss.PrettyPrint(func(ShardID) string { return "units: 10" }))
+	})
+
+	t.Run("multiple shards", func(t *testing.T) {
+		ss := ShardingScheme{}
+		id0, _, err := ss.Split(ShardID{})
+		require.NoError(t, err)
+		_, id01, err := ss.Split(id0)
+		require.NoError(t, err)
+
+		require.Equal(t, "ε\n├── 0\n│   ├── 00\n│   └── 01\n└── 1\n", ss.PrettyPrint(nil))
+
+		// label is shown for shards only, empty label is not shown
+		label := func(id ShardID) string {
+			if id.Equal(id01) {
+				return ""
+			}
+			return fmt.Sprintf("[%d]", id.Length())
+		}
+		require.Equal(t, "ε\n├── 0\n│   ├── 00 [2]\n│   └── 01\n└── 1 [1]\n", ss.PrettyPrint(label))
+	})
+}
+
+func Test_ShardingScheme_DOT(t *testing.T) {
+	t.Run("single shard", func(t *testing.T) {
+		ss := ShardingScheme{}
+		require.Equal(t, "digraph ShardingScheme {\n\tnode [shape=box];\n\ts [label=\"ε\"];\n}\n", ss.DOT(nil))
+		require.Equal(t, "digraph ShardingScheme {\n\tnode [shape=box];\n\ts [label=\"ε\\nunits: \\\"10\\\"\"];\n}\n",
+			ss.DOT(func(ShardID) string { return `units: "10"` }))
+	})
+
+	t.Run("multiple shards", func(t *testing.T) {
+		ss := ShardingScheme{}
+		_, _, err := ss.Split(ShardID{})
+		require.NoError(t, err)
+
+		expected := "digraph ShardingScheme {\n\tnode [shape=box];\n" +
+			"\ts [label=\"ε\", style=dashed];\n" +
+			"\ts0 [label=\"0\\nunits: 1\"];\n" +
+			"\ts -> s0;\n" +
+			"\ts1 [label=\"1\\nunits: 1\"];\n" +
+			"\ts -> s1;\n" +
+			"}\n"
+		require.Equal(t, expected, ss.DOT(func(ShardID) string { return "units: 1" }))
+	})
+}
+
+func Test_ShardTree_PrettyPrint(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		require.Equal(t, "tree is empty", ShardTree{}.PrettyPrint())
+	})
+
+	t.Run("single shard", func(t *testing.T) {
+		tree := ShardTree{ShardID{}.Key(): {1, 2}}
+		require.Equal(t, "ε 0102\n", tree.PrettyPrint())
+	})
+
+	t.Run("multiple shards", func(t *testing.T) {
+		id0, id1 := ShardID{}.Split()
+		id00, id01 := id0.Split()
+		tree := ShardTree{
+			ShardID{}.Key(): {0xFF},
+			id0.Key():       {0},
+			id1.Key():       {1},
+			id00.Key():      {0, 0},
+			id01.Key():      {0, 1},
+		}
+		require.Equal(t, "ε FF\n├── 0 00\n│   ├── 00 0000\n│   └── 01 0001\n└── 1 01\n", tree.PrettyPrint())
+	})
+}
+
+func Test_ShardTree_DOT(t *testing.T) {
+	require.Equal(t, "digraph ShardTree {\n\tnode [shape=box];\n}\n", ShardTree{}.DOT())
+
+	id0, id1 := ShardID{}.Split()
+	tree := ShardTree{
+		ShardID{}.Key(): {0xFF},
+		id0.Key():       {0},
+		id1.Key():       {1},
+	}
+	expected := "digraph ShardTree {\n\tnode [shape=box];\n" +
+		"\ts [label=\"ε\\nFF\"];\n" +
+		"\ts0 [label=\"0\\n00\"];\n" +
+		"\ts -> s0;\n" +
+		"\ts1 [label=\"1\\n01\"];\n" +
+		"\ts -> s1;\n" +
+		"}\n"
+	require.Equal(t, expected, tree.DOT())
+}
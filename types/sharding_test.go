@@ -72,6 +72,28 @@ func Test_ShardID_Split(t *testing.T) {
 	})
 }
 
+func Test_ShardID_Parent(t *testing.T) {
+	_, ok := ShardID{}.Parent()
+	require.False(t, ok)
+
+	// split repeatedly and check that the parent of both halves is the original ID
+	id := ShardID{}
+	for i := range 20 {
+		i0, i1 := id.Split()
+		p0, ok := i0.Parent()
+		require.True(t, ok)
+		require.Equal(t, id, p0, "split %d", i)
+		p1, ok := i1.Parent()
+		require.True(t, ok)
+		require.Equal(t, id, p1, "split %d", i)
+		if i%3 == 0 {
+			id = i0
+		} else {
+			id = i1
+		}
+	}
+}
+
 func Test_ShardID_Comparator(t *testing.T) {
 	testCases := []struct {
 		ShardID ShardID
@@ -383,6 +405,22 @@ func Test_ShardingScheme_Split(t *testing.T) {
 	})
 }
 
+func Test_ShardingScheme_Clone(t *testing.T) {
+	var sc ShardingScheme
+	require.Equal(t, sc, sc.Clone())
+
+	id0, _, err := sc.Split(ShardID{})
+	require.NoError(t, err)
+	_, _, err = sc.Split(id0)
+	require.NoError(t, err)
+
+	clone := sc.Clone()
+	require.Equal(t, sc, clone)
+	require.NoError(t, clone.Merge(id0))
+	require.Len(t, slices.Collect(sc.All()), 3)
+	require.Len(t, slices.Collect(clone.All()), 2)
+}
+
 func Test_ShardingScheme_Merge(t *testing.T) {
 	emptyID := ShardID{}
 	id0, id1 := emptyID.Split()
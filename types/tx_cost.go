@@ -0,0 +1,97 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alphabill-org/alphabill-go-base/util"
+)
+
+var ErrTxCostFunctionIsNil = errors.New("transaction cost function is nil")
+
+/*
+TxCostFunction is the declarative cost model of the transactions of a partition.
+
+The cost of a transaction is calculated in gas units as
+  - the base cost of the transaction type (BaseCost when the type has no entry in TxTypeCost);
+  - plus PerByteCost for every byte of the CBOR encoded transaction order;
+  - plus PredicateCost for every predicate evaluation.
+
+The fee (in the smallest fee credit units) is the gas divided by GasUnitsPerFeeUnit
+rounded up.
+*/
+type TxCostFunction struct {
+	_                  struct{}          `cbor:",toarray"`
+	GasUnitsPerFeeUnit uint64            `json:"gasUnitsPerFeeUnit"`
+	BaseCost           uint64            `json:"baseCost"`             // gas units charged for the tx types not in TxTypeCost
+	TxTypeCost         map[uint16]uint64 `json:"txTypeCost,omitempty"` // base cost in gas units per tx type
+	PerByteCost        uint64            `json:"perByteCost"`          // gas units per byte of the tx order
+	PredicateCost      uint64            `json:"predicateCost"`        // gas units per predicate evaluation
+}
+
+func (cf *TxCostFunction) IsValid() error {
+	if cf == nil {
+		return ErrTxCostFunctionIsNil
+	}
+	if cf.GasUnitsPerFeeUnit == 0 {
+		return errors.New("gas units per fee unit must be greater than zero")
+	}
+	return nil
+}
+
+/*
+Gas returns the cost of the transaction "txo" in gas units, assuming the execution
+of the transaction evaluates "predicateRuns" predicates.
+
+The size of the transaction order is part of the cost so the order should have
+it's auth and fee proofs assigned (placeholder proofs of the expected size are OK)
+for the estimate to be accurate.
+*/
+func (cf *TxCostFunction) Gas(txo *TransactionOrder, predicateRuns uint64) (uint64, error) {
+	if err := cf.IsValid(); err != nil {
+		return 0, err
+	}
+	if txo == nil {
+		return 0, ErrTransactionOrderIsNil
+	}
+	buf, err := txo.MarshalCBOR()
+	if err != nil {
+		return 0, fmt.Errorf("encoding transaction order: %w", err)
+	}
+
+	base, ok := cf.TxTypeCost[txo.Type]
+	if !ok {
+		base = cf.BaseCost
+	}
+	sizeCost, ok1 := util.SafeMul(uint64(len(buf)), cf.PerByteCost)
+	predicateCost, ok2 := util.SafeMul(predicateRuns, cf.PredicateCost)
+	gas, ok3 := util.AddUint64(base, sizeCost, predicateCost)
+	if !(ok1 && ok2 && ok3) {
+		return 0, errors.New("transaction cost overflows uint64")
+	}
+	return gas, nil
+}
+
+// FeeOf converts "gas" units to fee, the result is rounded up.
+func (cf *TxCostFunction) FeeOf(gas uint64) uint64 {
+	fee := gas / cf.GasUnitsPerFeeUnit
+	if gas%cf.GasUnitsPerFeeUnit != 0 {
+		fee++
+	}
+	return fee
+}
+
+/*
+EstimateFee returns the estimated fee of the transaction "txo", see [TxCostFunction.Gas]
+for the meaning of the "predicateRuns". The result can be used as the
+ClientMetadata.MaxTransactionFee of the order - as assigning the max fee changes
+the size of the order it is advisable to assign some non-zero placeholder value
+before the estimation.
+*/
+func (cf *TxCostFunction) EstimateFee(txo *TransactionOrder, predicateRuns uint64) (uint64, error) {
+	gas, err := cf.Gas(txo, predicateRuns)
+	if err != nil {
+		return 0, err
+	}
+	return cf.FeeOf(gas), nil
+}
@@ -0,0 +1,82 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCostFunction(t *testing.T) {
+	txo := &TransactionOrder{
+		Version: 1,
+		Payload: Payload{
+			NetworkID:      5,
+			PartitionID:    1,
+			UnitID:         []byte{1, 2, 3},
+			Type:           2,
+			ClientMetadata: &ClientMetadata{Timeout: 10, MaxTransactionFee: 1},
+		},
+		AuthProof: []byte{0x44, 1, 2, 3, 4},
+	}
+	buf, err := txo.MarshalCBOR()
+	require.NoError(t, err)
+	size := uint64(len(buf))
+
+	t.Run("invalid input", func(t *testing.T) {
+		var cf *TxCostFunction
+		_, err := cf.EstimateFee(txo, 1)
+		require.ErrorIs(t, err, ErrTxCostFunctionIsNil)
+
+		cf = &TxCostFunction{}
+		_, err = cf.EstimateFee(txo, 1)
+		require.EqualError(t, err, "gas units per fee unit must be greater than zero")
+
+		cf.GasUnitsPerFeeUnit = 1
+		_, err = cf.EstimateFee(nil, 1)
+		require.ErrorIs(t, err, ErrTransactionOrderIsNil)
+	})
+
+	t.Run("gas", func(t *testing.T) {
+		cf := &TxCostFunction{GasUnitsPerFeeUnit: 10, BaseCost: 100, PerByteCost: 2, PredicateCost: 50}
+		gas, err := cf.Gas(txo, 3)
+		require.NoError(t, err)
+		require.Equal(t, 100+2*size+150, gas)
+
+		// tx type specific cost overrides the base cost
+		cf.TxTypeCost = map[uint16]uint64{2: 1000, 3: 1}
+		gas, err = cf.Gas(txo, 3)
+		require.NoError(t, err)
+		require.Equal(t, 1000+2*size+150, gas)
+
+		cf.PredicateCost = math.MaxUint64
+		_, err = cf.Gas(txo, 2)
+		require.EqualError(t, err, "transaction cost overflows uint64")
+	})
+
+	t.Run("fee", func(t *testing.T) {
+		cf := &TxCostFunction{GasUnitsPerFeeUnit: 10}
+		require.EqualValues(t, 0, cf.FeeOf(0))
+		require.EqualValues(t, 1, cf.FeeOf(1))
+		require.EqualValues(t, 1, cf.FeeOf(10))
+		require.EqualValues(t, 2, cf.FeeOf(11))
+
+		cf.BaseCost = 95
+		cf.PerByteCost = 1
+		fee, err := cf.EstimateFee(txo, 0)
+		require.NoError(t, err)
+		require.Equal(t, (95+size+9)/10, fee)
+	})
+
+	t.Run("PDR", func(t *testing.T) {
+		pdr := &PartitionDescriptionRecord{Version: 2, PartitionID: 1}
+		_, err := pdr.EstimateTxFee(txo, 1)
+		require.EqualError(t, err, "transaction cost function is nil for partition 00000001")
+		require.ErrorIs(t, err, ErrTxCostFunctionIsNil)
+
+		pdr.TxCostFunction = &TxCostFunction{GasUnitsPerFeeUnit: 1, BaseCost: 7}
+		fee, err := pdr.EstimateTxFee(txo, 1)
+		require.NoError(t, err)
+		require.EqualValues(t, 7, fee)
+	})
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"crypto"
 	"errors"
 	"fmt"
@@ -85,6 +86,45 @@ type (
 	StateUnlockProofKind byte
 )
 
+/*
+NewTransactionOrder creates transaction order of type "txType" for the unit "unitID"
+with attributes "attr". Other fields of the payload are copied from the "txTemplate"
+(client metadata is cloned so it is not shared between the transaction orders
+created from the same template).
+*/
+func NewTransactionOrder(txTemplate Payload, unitID UnitID, txType uint16, attr any) (*TransactionOrder, error) {
+	txo := &TransactionOrder{Version: 1, Payload: txTemplate}
+	if cm := txTemplate.ClientMetadata; cm != nil {
+		txo.ClientMetadata = &ClientMetadata{
+			Timeout:           cm.Timeout,
+			MaxTransactionFee: cm.MaxTransactionFee,
+			FeeCreditRecordID: bytes.Clone(cm.FeeCreditRecordID),
+			ReferenceNumber:   bytes.Clone(cm.ReferenceNumber),
+		}
+	}
+	txo.UnitID = unitID
+	txo.Type = txType
+	if err := txo.SetAttributes(attr); err != nil {
+		return nil, err
+	}
+	return txo, nil
+}
+
+/*
+NewTransactionOrderCanonical decodes transaction order from "data" and verifies that
+"data" is the deterministic encoding of the order. Signatures are calculated over
+the re-encoded order so it should be used to decode orders received from untrusted
+sources, UnmarshalCBOR accepts any decodable encoding. The check includes the fields
+kept in encoded form (Attributes, AuthProof, FeeProof, StateUnlock).
+*/
+func NewTransactionOrderCanonical(data []byte) (*TransactionOrder, error) {
+	txo := &TransactionOrder{}
+	if err := cbor.UnmarshalCanonical(data, txo); err != nil {
+		return nil, err
+	}
+	return txo, nil
+}
+
 func (t *TransactionOrder) StateLockProofSigBytes() ([]byte, error) {
 	if t == nil {
 		return nil, ErrTransactionOrderIsNil
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/alphabill-org/alphabill-go-base/cbor"
+	testsig "github.com/alphabill-org/alphabill-go-base/testutils/sig"
 )
 
 var (
@@ -155,6 +156,32 @@ func Test_Payload_SetAttributes(t *testing.T) {
 	require.Equal(t, expectedAttributes, actualAttributes, "expected to get back the same attributes")
 }
 
+func Test_NewTransactionOrder(t *testing.T) {
+	tmpl := createTransactionOrder(t).Payload
+	attr := &testAttributes{NewOwnerPredicate: []byte{1}, TargetValue: 5, Counter: 6}
+	txo, err := NewTransactionOrder(tmpl, UnitID{1, 2, 3}, 22, attr)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, txo.Version)
+	require.Equal(t, UnitID{1, 2, 3}, txo.UnitID)
+	require.EqualValues(t, 22, txo.Type)
+	require.Equal(t, tmpl.PartitionID, txo.PartitionID)
+	require.Equal(t, tmpl.ClientMetadata, txo.ClientMetadata)
+	require.NotSame(t, tmpl.ClientMetadata, txo.ClientMetadata)
+	attr2 := &testAttributes{}
+	require.NoError(t, txo.UnmarshalAttributes(attr2))
+	require.Equal(t, attr, attr2)
+
+	// template without client metadata
+	tmpl.ClientMetadata = nil
+	txo, err = NewTransactionOrder(tmpl, UnitID{1}, 22, attr)
+	require.NoError(t, err)
+	require.Nil(t, txo.ClientMetadata)
+
+	txo, err = NewTransactionOrder(tmpl, UnitID{1}, 22, make(chan int))
+	require.Error(t, err)
+	require.Nil(t, txo)
+}
+
 func TestStateLock_IsValid(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		s := StateLock{
@@ -258,6 +285,84 @@ func Test_UnmarshalCBOR(t *testing.T) {
 	})
 }
 
+func Test_NewTransactionOrderCanonical(t *testing.T) {
+	signer, verifier := testsig.CreateSignerAndVerifier(t)
+	txo := createTransactionOrder(t)
+	sigBytes, err := txo.AuthProofSigBytes()
+	require.NoError(t, err)
+	sig, err := signer.SignBytes(sigBytes)
+	require.NoError(t, err)
+	require.NoError(t, txo.SetAuthProof(&testAuthProof{Signature: sig}))
+
+	data, err := cbor.Marshal(txo)
+	require.NoError(t, err)
+	// tag (3 bytes) + array header (1 byte) + Version; encode version 1 using
+	// non-minimal length (0x18 0x01 instead of 0x01)
+	require.EqualValues(t, 1, data[4])
+	nonCanonical := append(append(append([]byte{}, data[:4]...), 0x18, 0x01), data[5:]...)
+
+	t.Run("canonical input", func(t *testing.T) {
+		txo2, err := NewTransactionOrderCanonical(data)
+		require.NoError(t, err)
+		require.Equal(t, txo, txo2)
+	})
+
+	t.Run("plain decoding accepts non-canonical input", func(t *testing.T) {
+		// the signature still verifies as it is calculated over the re-encoded order
+		txo2 := &TransactionOrder{}
+		require.NoError(t, txo2.UnmarshalCBOR(nonCanonical))
+		require.Equal(t, txo, txo2)
+		sigBytes2, err := txo2.AuthProofSigBytes()
+		require.NoError(t, err)
+		require.NoError(t, verifier.VerifyBytes(sig, sigBytes2))
+	})
+
+	t.Run("non-canonical input is rejected", func(t *testing.T) {
+		txo2, err := NewTransactionOrderCanonical(nonCanonical)
+		require.ErrorIs(t, err, cbor.ErrNonCanonical)
+		require.EqualError(t, err, `non-canonical CBOR encoding of *types.TransactionOrder`)
+		require.Nil(t, txo2)
+	})
+
+	t.Run("non-canonical order in transaction record", func(t *testing.T) {
+		txr := createTransactionRecord(t, txo, 1)
+		txr.TransactionOrder = nonCanonical
+		txrBytes, err := cbor.Marshal(txr)
+		require.NoError(t, err)
+		txr2, err := NewTransactionRecordCanonical(txrBytes)
+		require.ErrorIs(t, err, cbor.ErrNonCanonical)
+		require.ErrorContains(t, err, `non-canonical CBOR encoding of *types.TransactionRecord`)
+		require.Nil(t, txr2)
+	})
+
+	t.Run("non-canonical auth proof", func(t *testing.T) {
+		// AuthProof is kept in encoded form so re-encoding the order copies it
+		// verbatim; encode the length of the signature using non-minimal length
+		require.Less(t, len(sig), 256)
+		authProof := append([]byte{0x81, 0x59, 0x00, byte(len(sig))}, sig...)
+		txo2 := *txo
+		txo2.AuthProof = authProof
+		data, err := cbor.Marshal(&txo2)
+		require.NoError(t, err)
+
+		// the proof decodes to the same signature
+		txo3 := &TransactionOrder{}
+		require.NoError(t, txo3.UnmarshalCBOR(data))
+		proof := &testAuthProof{}
+		require.NoError(t, txo3.UnmarshalAuthProof(proof))
+		require.Equal(t, sig, proof.Signature)
+
+		txo3, err = NewTransactionOrderCanonical(data)
+		require.ErrorIs(t, err, cbor.ErrNonCanonical)
+		require.Nil(t, txo3)
+	})
+}
+
+type testAuthProof struct {
+	_         struct{} `cbor:",toarray"`
+	Signature []byte
+}
+
 func TestAddStateUnlockCommitProof(t *testing.T) {
 	tx := createTransactionOrder(t)
 	tx.AddStateUnlockCommitProof([]byte{255})
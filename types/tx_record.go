@@ -101,6 +101,25 @@ func (t *TransactionRecord) GetTransactionOrderV1() (*TransactionOrder, error) {
 	return txoV1, nil
 }
 
+/*
+NewTransactionRecordCanonical decodes transaction record from "data" and verifies
+that both the record and the transaction order it contains are in deterministic
+encoding, see cbor.UnmarshalCanonical.
+*/
+func NewTransactionRecordCanonical(data []byte) (*TransactionRecord, error) {
+	txr := &TransactionRecord{}
+	if err := cbor.UnmarshalCanonical(data, txr); err != nil {
+		return nil, err
+	}
+	if txr.TransactionOrder == nil {
+		return nil, ErrTransactionOrderIsNil
+	}
+	if _, err := NewTransactionOrderCanonical(txr.TransactionOrder); err != nil {
+		return nil, fmt.Errorf("transaction order: %w", err)
+	}
+	return txr, nil
+}
+
 func (t *TransactionRecord) TargetUnits() []UnitID {
 	if t == nil {
 		return nil
@@ -53,6 +53,25 @@ func TestTransactionRecordFunctions(t *testing.T) {
 		require.NoError(t, txr2.IsValid())
 	})
 
+	t.Run("Test Unmarshal non-canonical", func(t *testing.T) {
+		txrBytes, err := txr.MarshalCBOR()
+		require.NoError(t, err)
+		// tag (3 bytes) + array header (1 byte) + Version encoded using non-minimal length
+		require.EqualValues(t, 1, txrBytes[4])
+		nonCanonical := append(append(append([]byte{}, txrBytes[:4]...), 0x18, 0x01), txrBytes[5:]...)
+
+		txr2 := &TransactionRecord{}
+		require.NoError(t, txr2.UnmarshalCBOR(nonCanonical))
+		require.Equal(t, txr, txr2)
+
+		txr2, err = NewTransactionRecordCanonical(txrBytes)
+		require.NoError(t, err)
+		require.Equal(t, txr, txr2)
+		txr2, err = NewTransactionRecordCanonical(nonCanonical)
+		require.ErrorIs(t, err, cbor.ErrNonCanonical)
+		require.Nil(t, txr2)
+	})
+
 	t.Run("Test Unmarshal invalid version", func(t *testing.T) {
 		txr.Version = 2
 		txrBytes, err := txr.MarshalCBOR()
@@ -124,6 +124,13 @@ func (x *UnicityCertificate) GetFeeSum() uint64 {
 	return 0
 }
 
+/*
+GetETHash returns the executed transactions hash of the certified input record.
+
+The hash is calculated by the partition node, this module does not implement
+the calculation nor inclusion proofs for the executed transactions so the value
+can only be compared with the hash obtained from the node.
+*/
 func (x *UnicityCertificate) GetETHash() []byte {
 	if x != nil && x.InputRecord != nil {
 		return x.InputRecord.ETHash
@@ -266,3 +273,16 @@ func (x *UnicityCertificate) UnmarshalCBOR(data []byte) error {
 	}
 	return EnsureVersion(x, x.Version, 1)
 }
+
+/*
+NewUnicityCertificateCanonical decodes unicity certificate from "data" and verifies
+that "data" (including the nested input record and unicity seal) is the deterministic
+encoding of the certificate, see cbor.UnmarshalCanonical.
+*/
+func NewUnicityCertificateCanonical(data []byte) (*UnicityCertificate, error) {
+	uc := &UnicityCertificate{}
+	if err := cbor.UnmarshalCanonical(data, uc); err != nil {
+		return nil, err
+	}
+	return uc, nil
+}
@@ -880,3 +880,24 @@ func Test_UnicityCertificate_Cbor(t *testing.T) {
 		require.ErrorContains(t, uc2.UnmarshalCBOR(ucData), "invalid version (type *types.UnicityCertificate), expected 1, got 2")
 	})
 }
+
+func Test_NewUnicityCertificateCanonical(t *testing.T) {
+	// zero value UC, see Test_UnicityCertificate_Cbor
+	ucData, err := hex.Decode([]byte("0xD903EF8701D903F08A010000F6F6F600F600F64101F6824180F6D903F6830100F6D903E9880100000000F6F6F6"))
+	require.NoError(t, err)
+
+	uc, err := NewUnicityCertificateCanonical(ucData)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, uc.UnicitySeal.Version)
+
+	// encode the version of the unicity seal using non-minimal length
+	seal := []byte{0xD9, 0x03, 0xE9, 0x88, 0x01}
+	idx := bytes.LastIndex(ucData, seal)
+	require.Positive(t, idx)
+	nonCanonical := append(append(bytes.Clone(ucData[:idx+len(seal)-1]), 0x18, 0x01), ucData[idx+len(seal):]...)
+	require.NoError(t, cbor.Unmarshal(nonCanonical, &UnicityCertificate{}))
+
+	uc, err = NewUnicityCertificateCanonical(nonCanonical)
+	require.ErrorIs(t, err, cbor.ErrNonCanonical)
+	require.Nil(t, uc)
+}
@@ -193,3 +193,15 @@ func (x *UnicitySeal) UnmarshalCBOR(b []byte) (err error) {
 	}
 	return nil
 }
+
+/*
+NewUnicitySealCanonical decodes unicity seal from "data" and verifies that "data"
+is the deterministic encoding of the seal, see cbor.UnmarshalCanonical.
+*/
+func NewUnicitySealCanonical(data []byte) (*UnicitySeal, error) {
+	seal := &UnicitySeal{}
+	if err := cbor.UnmarshalCanonical(data, seal); err != nil {
+		return nil, err
+	}
+	return seal, nil
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"crypto"
 	"testing"
 
@@ -387,3 +388,23 @@ func TestUnicitySeal_UnmarshalCBOR(t *testing.T) {
 		require.EqualError(t, err, `invalid signature type: string`)
 	})
 }
+
+func Test_NewUnicitySealCanonical(t *testing.T) {
+	seal := &UnicitySeal{Version: 1, RootChainRoundNumber: 1, Timestamp: 2, Hash: []byte{3}}
+	data, err := cbor.Marshal(seal)
+	require.NoError(t, err)
+
+	res, err := NewUnicitySealCanonical(data)
+	require.NoError(t, err)
+	require.Equal(t, seal, res)
+
+	// tag (3 bytes) + array header (1 byte) + Version + NetworkID + RootChainRoundNumber;
+	// encode the round number using non-minimal length
+	require.EqualValues(t, 1, data[6])
+	nonCanonical := append(append(bytes.Clone(data[:6]), 0x18, 0x01), data[7:]...)
+	require.NoError(t, cbor.Unmarshal(nonCanonical, &UnicitySeal{}))
+
+	res, err = NewUnicitySealCanonical(nonCanonical)
+	require.ErrorIs(t, err, cbor.ErrNonCanonical)
+	require.Nil(t, res)
+}
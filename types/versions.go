@@ -36,6 +36,8 @@ const (
 	TransactionOrderTag
 	RootPartitionBlockDataTag
 	RootPartitionRoundInfoTag
+	ShardConfHistoryTag
+	CompactBlockTag
 )
 
 func ErrInvalidVersion(s Versioned) error {
@@ -47,3 +47,11 @@ func SafeSub(a, b uint64) (uint64, bool) {
 	diff, borrow := bits.Sub64(a, b, 0)
 	return diff, borrow == 0
 }
+
+/*
+SafeMul returns a*b and boolean indicating is the result ok (ie no overflow).
+*/
+func SafeMul(a, b uint64) (uint64, bool) {
+	hi, lo := bits.Mul64(a, b)
+	return lo, hi == 0
+}
@@ -152,3 +152,49 @@ func TestSafeSub(t *testing.T) {
 		}
 	})
 }
+
+func TestSafeMul(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		cases := []struct {
+			a      uint64
+			b      uint64
+			result uint64
+		}{
+			{0, 0, 0},
+			{0, math.MaxUint64, 0},
+			{1, math.MaxUint64, math.MaxUint64},
+			{2, 3, 6},
+			{math.MaxUint32, math.MaxUint32, math.MaxUint32 * math.MaxUint32},
+		}
+
+		for _, tt := range cases {
+			result, ok := SafeMul(tt.a, tt.b)
+			if !ok {
+				t.Errorf("unexpected overflow for %x * %x", tt.a, tt.b)
+				continue
+			}
+			if result != tt.result {
+				t.Errorf("expected %x * %x = %x, got %x", tt.a, tt.b, tt.result, result)
+			}
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		cases := []struct {
+			a uint64
+			b uint64
+		}{
+			{2, math.MaxUint64},
+			{math.MaxUint32 + 1, math.MaxUint32 + 1},
+			{math.MaxUint64, math.MaxUint64},
+		}
+
+		for _, tt := range cases {
+			if result, ok := SafeMul(tt.a, tt.b); ok {
+				t.Errorf("expected overflow for %x * %x got %x", tt.a, tt.b, result)
+			}
+		}
+	})
+}